@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/db/postgresql"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/response"
+	"github.com/arefev/gophermart/internal/service"
+	"github.com/arefev/gophermart/internal/trm"
+	"go.uber.org/zap"
+)
+
+var ErrAdminUsage = errors.New(`usage: gophermart admin COMMAND ARGS... [flags]
+commands:
+  user LOGIN                  show user
+  orders LOGIN                list user orders
+  withdrawals LOGIN           list user withdrawals
+  balance LOGIN               show user balance
+  adjust LOGIN AMOUNT REASON  credit (AMOUNT > 0) or debit (AMOUNT < 0) points
+  requeue NUMBER REASON       send order to accrual check again
+  invalidate NUMBER REASON    mark order invalid
+  block LOGIN REASON          block user
+  unblock LOGIN REASON        unblock user`)
+
+type support interface {
+	FindUser(ctx context.Context, login string) (*model.User, error)
+	Balance(ctx context.Context, login string) (*model.Balance, error)
+	Orders(ctx context.Context, login string) ([]model.Order, error)
+	Withdrawals(ctx context.Context, login string) ([]model.Withdrawal, error)
+	AdjustBalance(ctx context.Context, login string, amount float64, reason string) (*model.Balance, error)
+	RequeueOrder(ctx context.Context, number, reason string) error
+	InvalidateOrder(ctx context.Context, number, reason string) error
+	SetBlocked(ctx context.Context, login string, blocked bool, reason string) error
+}
+
+type adminCommand struct {
+	run  func(ctx context.Context, s support, args []string) (any, error)
+	args int
+}
+
+var adminCommands = map[string]adminCommand{
+	"user": {args: 1, run: func(ctx context.Context, s support, args []string) (any, error) {
+		u, err := s.FindUser(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return response.NewUser(u), nil
+	}},
+	"orders": {args: 1, run: func(ctx context.Context, s support, args []string) (any, error) {
+		list, err := s.Orders(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return response.NewOrders(list), nil
+	}},
+	"withdrawals": {args: 1, run: func(ctx context.Context, s support, args []string) (any, error) {
+		list, err := s.Withdrawals(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return response.NewWithdrawals(list), nil
+	}},
+	"balance": {args: 1, run: func(ctx context.Context, s support, args []string) (any, error) {
+		return s.Balance(ctx, args[0])
+	}},
+	"adjust": {args: 3, run: func(ctx context.Context, s support, args []string) (any, error) {
+		amount, err := strconv.ParseFloat(args[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse amount fail: %w", err)
+		}
+
+		return s.AdjustBalance(ctx, args[0], amount, args[2])
+	}},
+	"requeue": {args: 2, run: func(ctx context.Context, s support, args []string) (any, error) {
+		return nil, s.RequeueOrder(ctx, args[0], args[1])
+	}},
+	"invalidate": {args: 2, run: func(ctx context.Context, s support, args []string) (any, error) {
+		return nil, s.InvalidateOrder(ctx, args[0], args[1])
+	}},
+	"block": {args: 2, run: func(ctx context.Context, s support, args []string) (any, error) {
+		return nil, s.SetBlocked(ctx, args[0], true, args[1])
+	}},
+	"unblock": {args: 2, run: func(ctx context.Context, s support, args []string) (any, error) {
+		return nil, s.SetBlocked(ctx, args[0], false, args[1])
+	}},
+}
+
+// runAdmin подкоманда gophermart admin для службы поддержки. Аргументы команды
+// идут перед флагами, флаги те же, что у сервера, нужна только БД.
+func runAdmin(args []string) error {
+	if len(args) == 0 {
+		return ErrAdminUsage
+	}
+
+	cmd, ok := adminCommands[args[0]]
+	if !ok || len(args) < cmd.args+1 {
+		return ErrAdminUsage
+	}
+
+	cmdArgs, flags := args[1:cmd.args+1], args[cmd.args+1:]
+
+	conf, err := config.NewConfig(flags)
+	if err != nil {
+		return fmt.Errorf("admin: init config fail: %w", err)
+	}
+
+	zLog, err := logger.Build(conf.LogLevel)
+	if err != nil {
+		return fmt.Errorf("admin: init logger fail: %w", err)
+	}
+
+	db, err := postgresql.NewDB(zLog).Connect(conf.DatabaseDSN)
+	if err != nil {
+		return fmt.Errorf("admin: db connect fail: %w", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			zLog.Error("db close failed", zap.Error(err))
+		}
+	}()
+
+	// Команды работают только со схемой, которую знает бинарник
+	if _, err := migrationsEnsure(conf.DatabaseDSN, false); err != nil {
+		return fmt.Errorf("admin: check schema fail: %w", err)
+	}
+
+	tr := trm.NewTr(db.Connection())
+	app := application.App{
+		Rep:       newRepository(tr, zLog),
+		TrManager: trm.NewTrm(tr, zLog),
+		Log:       zLog,
+		Conf:      &conf,
+	}
+
+	result, err := cmd.run(context.Background(), service.NewSupportService(&app, adminActor()), cmdArgs)
+	if err != nil {
+		return fmt.Errorf("admin %s: %w", args[0], err)
+	}
+
+	if result == nil {
+		fmt.Fprintln(os.Stdout, "ok")
+		return nil
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		return fmt.Errorf("admin: encode result fail: %w", err)
+	}
+
+	return nil
+}
+
+// adminActor автор действий в журнале аудита: пользователь ОС, запустивший команду.
+func adminActor() string {
+	u, err := user.Current()
+	if err != nil {
+		return "cli"
+	}
+
+	return "cli:" + u.Username
+}
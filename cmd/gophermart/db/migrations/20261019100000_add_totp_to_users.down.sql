@@ -0,0 +1,6 @@
+BEGIN;
+ALTER TABLE public.users
+    DROP COLUMN IF EXISTS "totp_secret",
+    DROP COLUMN IF EXISTS "totp_enabled",
+    DROP COLUMN IF EXISTS "totp_last_step";
+COMMIT;
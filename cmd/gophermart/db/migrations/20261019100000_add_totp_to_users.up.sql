@@ -0,0 +1,6 @@
+BEGIN;
+ALTER TABLE public.users
+    ADD COLUMN IF NOT EXISTS "totp_secret" varchar NULL,
+    ADD COLUMN IF NOT EXISTS "totp_enabled" boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS "totp_last_step" bigint NOT NULL DEFAULT 0;
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS public.users_recovery_codes (
+    id bigint GENERATED ALWAYS AS IDENTITY NOT NULL,
+    "user_id" bigint NOT NULL,
+    "code_hash" varchar(64) NOT NULL,
+    "used_at" timestamp NULL,
+    "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT users_recovery_codes_pk PRIMARY KEY (id),
+    CONSTRAINT users_recovery_codes_unique UNIQUE (user_id, code_hash),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id)
+);
+COMMIT;
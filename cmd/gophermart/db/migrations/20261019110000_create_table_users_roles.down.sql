@@ -0,0 +1,3 @@
+BEGIN;
+DROP TABLE IF EXISTS public.users_roles;
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS public.users_roles (
+    id bigint GENERATED ALWAYS AS IDENTITY NOT NULL,
+    "user_id" bigint NOT NULL,
+    "role" varchar(50) NOT NULL,
+    "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT users_roles_pk PRIMARY KEY (id),
+    CONSTRAINT users_roles_unique UNIQUE (user_id, role),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id)
+);
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS public.api_keys (
+    id bigint GENERATED ALWAYS AS IDENTITY NOT NULL,
+    "name" varchar(255) NOT NULL,
+    "prefix" varchar(16) NOT NULL,
+    "key_hash" varchar(64) NOT NULL,
+    "scopes" varchar NOT NULL DEFAULT '',
+    "last_used_at" timestamp NULL,
+    "revoked_at" timestamp NULL,
+    "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT api_keys_pk PRIMARY KEY (id),
+    CONSTRAINT api_keys_prefix_unique UNIQUE (prefix)
+);
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS public.users_identities (
+    id bigint GENERATED ALWAYS AS IDENTITY NOT NULL,
+    "user_id" bigint NOT NULL,
+    "issuer" varchar NOT NULL,
+    "subject" varchar NOT NULL,
+    "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT users_identities_pk PRIMARY KEY (id),
+    CONSTRAINT users_identities_unique UNIQUE (issuer, subject),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id)
+);
+COMMIT;
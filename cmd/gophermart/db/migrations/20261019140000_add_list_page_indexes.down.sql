@@ -0,0 +1,4 @@
+BEGIN;
+DROP INDEX IF EXISTS public.orders_user_uploaded_idx;
+DROP INDEX IF EXISTS public.withdrawals_user_processed_idx;
+COMMIT;
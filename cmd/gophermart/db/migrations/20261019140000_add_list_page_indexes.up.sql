@@ -0,0 +1,4 @@
+BEGIN;
+CREATE INDEX IF NOT EXISTS orders_user_uploaded_idx ON public.orders (user_id, uploaded_at, id);
+CREATE INDEX IF NOT EXISTS withdrawals_user_processed_idx ON public.withdrawals (user_id, processed_at, id);
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+DROP TRIGGER IF EXISTS orders_status_history_trigger ON public.orders;
+DROP FUNCTION IF EXISTS public.orders_status_history_write();
+DROP TABLE IF EXISTS public.orders_status_history;
+ALTER TABLE public.orders DROP COLUMN IF EXISTS "checked_at";
+COMMIT;
@@ -0,0 +1,30 @@
+BEGIN;
+ALTER TABLE public.orders ADD COLUMN IF NOT EXISTS "checked_at" timestamp NULL;
+
+CREATE TABLE IF NOT EXISTS public.orders_status_history (
+    id bigint GENERATED ALWAYS AS IDENTITY NOT NULL,
+    "order_id" bigint NOT NULL,
+    "status" smallint NOT NULL,
+    "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT orders_status_history_pk PRIMARY KEY (id),
+    CONSTRAINT fk_order FOREIGN KEY(order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS orders_status_history_order_idx ON public.orders_status_history (order_id, id);
+
+INSERT INTO public.orders_status_history(order_id, status, created_at)
+SELECT id, status, uploaded_at FROM public.orders;
+
+CREATE OR REPLACE FUNCTION public.orders_status_history_write() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.status IS DISTINCT FROM OLD.status THEN
+        INSERT INTO public.orders_status_history(order_id, status) VALUES (NEW.id, NEW.status);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER orders_status_history_trigger
+AFTER INSERT OR UPDATE OF status ON public.orders
+FOR EACH ROW EXECUTE FUNCTION public.orders_status_history_write();
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+DROP TRIGGER IF EXISTS users_events_notify_trigger ON public.users_events;
+DROP FUNCTION IF EXISTS public.users_events_notify();
+DROP TABLE IF EXISTS public.users_events;
+COMMIT;
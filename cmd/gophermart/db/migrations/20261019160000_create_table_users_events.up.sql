@@ -0,0 +1,30 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS public.users_events (
+    id bigint GENERATED ALWAYS AS IDENTITY NOT NULL,
+    "user_id" bigint NOT NULL,
+    "type" varchar(50) NOT NULL,
+    "payload" jsonb NOT NULL,
+    "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT users_events_pk PRIMARY KEY (id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS users_events_user_idx ON public.users_events (user_id, id);
+
+CREATE OR REPLACE FUNCTION public.users_events_notify() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('users_events', json_build_object(
+        'id', NEW.id,
+        'user_id', NEW.user_id,
+        'type', NEW.type,
+        'payload', NEW.payload,
+        'created_at', NEW.created_at
+    )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_events_notify_trigger
+AFTER INSERT ON public.users_events
+FOR EACH ROW EXECUTE FUNCTION public.users_events_notify();
+COMMIT;
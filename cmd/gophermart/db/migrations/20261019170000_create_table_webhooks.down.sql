@@ -0,0 +1,4 @@
+BEGIN;
+DROP TABLE IF EXISTS public.webhooks_deliveries;
+DROP TABLE IF EXISTS public.webhooks;
+COMMIT;
@@ -0,0 +1,31 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS public.webhooks (
+    id bigint GENERATED ALWAYS AS IDENTITY NOT NULL,
+    "url" varchar NOT NULL,
+    "secret" varchar NOT NULL,
+    "events" varchar NOT NULL DEFAULT '',
+    "active" boolean NOT NULL DEFAULT true,
+    "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT webhooks_pk PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS public.webhooks_deliveries (
+    id bigint GENERATED ALWAYS AS IDENTITY NOT NULL,
+    "webhook_id" bigint NOT NULL,
+    "event_type" varchar(50) NOT NULL,
+    "payload" jsonb NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "attempts" integer NOT NULL DEFAULT 0,
+    "last_status_code" integer NOT NULL DEFAULT 0,
+    "last_error" varchar NULL,
+    "next_attempt_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "delivered_at" timestamp NULL,
+    "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT webhooks_deliveries_pk PRIMARY KEY (id),
+    CONSTRAINT fk_webhook FOREIGN KEY(webhook_id) REFERENCES webhooks(id)
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_deliveries_pending_idx ON public.webhooks_deliveries (next_attempt_at) 
+WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhooks_deliveries_webhook_idx ON public.webhooks_deliveries (webhook_id, id);
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS public.outbox (
+    id bigint GENERATED ALWAYS AS IDENTITY NOT NULL,
+    "event_type" varchar(50) NOT NULL,
+    "payload" jsonb NOT NULL,
+    "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "published_at" timestamp NULL,
+    CONSTRAINT outbox_pk PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS outbox_unpublished_idx ON public.outbox (id) WHERE published_at IS NULL;
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+DROP TABLE IF EXISTS public.audit_log;
+ALTER TABLE public.users DROP COLUMN IF EXISTS "blocked";
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+ALTER TABLE public.users ADD COLUMN IF NOT EXISTS "blocked" boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS public.audit_log (
+    id bigint GENERATED ALWAYS AS IDENTITY NOT NULL,
+    "actor" varchar(255) NOT NULL,
+    "action" varchar(50) NOT NULL,
+    "user_id" bigint NULL,
+    "order_number" varchar(255) NULL,
+    "reason" text NOT NULL DEFAULT '',
+    "details" jsonb NOT NULL DEFAULT '{}',
+    "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT audit_log_pk PRIMARY KEY (id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_user_idx ON public.audit_log (user_id, id);
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+ALTER TABLE public.users
+    DROP COLUMN IF EXISTS "totp_locked_at",
+    DROP COLUMN IF EXISTS "totp_failures";
+COMMIT;
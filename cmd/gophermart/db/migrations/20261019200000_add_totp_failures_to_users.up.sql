@@ -0,0 +1,5 @@
+BEGIN;
+ALTER TABLE public.users
+    ADD COLUMN IF NOT EXISTS "totp_failures" integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "totp_locked_at" timestamp NULL;
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+ALTER TABLE public.webhooks
+    DROP CONSTRAINT IF EXISTS fk_user,
+    DROP COLUMN IF EXISTS "user_id";
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+ALTER TABLE public.webhooks
+    ADD COLUMN IF NOT EXISTS "user_id" integer NULL,
+    ADD CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id);
+COMMIT;
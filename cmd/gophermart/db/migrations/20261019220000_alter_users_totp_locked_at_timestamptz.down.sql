@@ -0,0 +1,4 @@
+BEGIN;
+ALTER TABLE public.users
+    ALTER COLUMN "totp_locked_at" TYPE timestamp USING "totp_locked_at"::timestamp;
+COMMIT;
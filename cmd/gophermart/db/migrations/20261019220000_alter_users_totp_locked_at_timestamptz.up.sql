@@ -0,0 +1,6 @@
+BEGIN;
+-- Время блокировки сравнивается с iat токена в UTC, поэтому хранится с часовым поясом.
+-- Старые значения записаны CURRENT_TIMESTAMP в часовом поясе сессии
+ALTER TABLE public.users
+    ALTER COLUMN "totp_locked_at" TYPE timestamptz USING "totp_locked_at"::timestamptz;
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+ALTER TABLE public.users
+    DROP COLUMN IF EXISTS "totp_locked_until";
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+ALTER TABLE public.users
+    ADD COLUMN IF NOT EXISTS "totp_locked_until" timestamptz NULL;
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+DROP INDEX IF EXISTS public.outbox_published_idx;
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+CREATE INDEX IF NOT EXISTS outbox_published_idx ON public.outbox (published_at) WHERE published_at IS NOT NULL;
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+DROP INDEX IF EXISTS public.users_events_created_idx;
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+CREATE INDEX IF NOT EXISTS users_events_created_idx ON public.users_events (created_at);
+COMMIT;
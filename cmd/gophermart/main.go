@@ -9,25 +9,47 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/arefev/gophermart/internal/application"
 	"github.com/arefev/gophermart/internal/config"
 	"github.com/arefev/gophermart/internal/db/postgresql"
+	"github.com/arefev/gophermart/internal/events"
+	"github.com/arefev/gophermart/internal/health"
 	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/metrics"
+	"github.com/arefev/gophermart/internal/outbox"
 	"github.com/arefev/gophermart/internal/repository"
 	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/rpc"
+	"github.com/arefev/gophermart/internal/service/password"
+	"github.com/arefev/gophermart/internal/tlsconf"
+	"github.com/arefev/gophermart/internal/tracing"
 	"github.com/arefev/gophermart/internal/trm"
+	"github.com/arefev/gophermart/internal/webhook"
 	"github.com/arefev/gophermart/internal/worker"
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		if err := runAdmin(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
 	if err := run(); err != nil {
 		log.Fatal(err)
 	}
@@ -42,92 +64,322 @@ func run() error {
 		return fmt.Errorf("run: init config fail: %w", err)
 	}
 
-	zLog, err := logger.Build(conf.LogLevel)
+	if conf.PrintConfig {
+		if err := conf.Print(os.Stdout); err != nil {
+			return fmt.Errorf("run: %w", err)
+		}
+
+		return conf.Validate()
+	}
+
+	if err := conf.Validate(); err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+
+	logLevel, err := zap.ParseAtomicLevel(conf.LogLevel)
+	if err != nil {
+		return fmt.Errorf("run: parse log level fail: %w", err)
+	}
+
+	zLog, err := logger.BuildWithLevel(logLevel)
 	if err != nil {
 		return fmt.Errorf("run: init logger fail: %w", err)
 	}
 
-	db, err := postgresql.NewDB(zLog).Connect(conf.DatabaseDSN)
+	shutdownTracing, err := tracing.Setup(mainCtx, conf.TraceExporter, conf.TraceEndpoint)
 	if err != nil {
-		return fmt.Errorf("run: db trm connect fail: %w", err)
+		return fmt.Errorf("run: init tracing fail: %w", err)
 	}
 
-	err = migrationsUp(conf.DatabaseDSN)
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			zLog.Error("tracing shutdown failed", zap.Error(err))
+		}
+	}()
+
+	db, err := postgresql.NewDB(zLog).Connect(conf.DatabaseDSN)
 	if err != nil {
-		return fmt.Errorf("run: migration up fail: %w", err)
+		return fmt.Errorf("run: db trm connect fail: %w", err)
 	}
 
 	defer func() {
 		if err := db.Close(); err != nil {
-			zLog.Error("db close failed: %w", zap.Error(err))
+			zLog.Error("db close failed", zap.Error(err))
 		}
 	}()
 
+	version, err := migrationsEnsure(conf.DatabaseDSN, conf.AutoMigrate)
+	if err != nil {
+		return fmt.Errorf("run: check schema fail: %w", err)
+	}
+
+	if err := metrics.RegisterDB(db.Connection().DB); err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+
 	tr := trm.NewTr(db.Connection())
 	app := application.App{
-		Rep: application.Repository{
-			User:    repository.NewUser(tr, zLog),
-			Order:   repository.NewOrder(tr, zLog),
-			Balance: repository.NewBalance(tr, zLog),
-		},
+		Rep:       newRepository(tr, zLog),
 		TrManager: trm.NewTrm(tr, zLog),
 		Log:       zLog,
 		Conf:      &conf,
+		Events:    events.NewHub(),
+		Health:    health.New(),
+	}
+
+	if conf.PwdBreached != "" {
+		list, err := password.NewBreachedList(conf.PwdBreached)
+		if err != nil {
+			return fmt.Errorf("run: %w", err)
+		}
+
+		app.Breached = list
+	}
+
+	app.Health.Register("database", health.Ping(db.Connection()))
+	app.Health.Register("migrations", health.Migrations(db.Connection(), version))
+	app.Health.Register("worker", health.Fresh(
+		app.Health.Worker(),
+		time.Duration(conf.HealthWorkerMaxAge)*time.Second,
+	))
+	if conf.HealthAccrual {
+		app.Health.Register("accrual", health.Reachable(conf.AccrualAddress))
+	}
+
+	publisher, err := outbox.NewPublisher(conf.OutboxPublisher, conf.OutboxFile)
+	if err != nil {
+		return fmt.Errorf("run: init outbox publisher fail: %w", err)
+	}
+
+	var certs *tlsconf.Reloader
+	if conf.TLSCert != "" {
+		certs, err = tlsconf.NewReloader(conf.TLSCert, conf.TLSKey, zLog)
+		if err != nil {
+			return fmt.Errorf("run: init tls certificate fail: %w", err)
+		}
+	}
+
+	publicTLS, adminTLS, err := serverTLS(&conf, certs)
+	if err != nil {
+		return fmt.Errorf("run: %w", err)
 	}
 
 	g, gCtx := errgroup.WithContext(mainCtx)
 
+	if certs != nil {
+		g.Go(func() error {
+			return certs.Watch(gCtx, seconds(conf.TLSReloadInterval))
+		})
+	}
+
+	g.Go(func() error {
+		return outbox.NewRelay(&app, publisher).Run(gCtx)
+	})
+
+	g.Go(func() error {
+		return events.Listen(gCtx, conf.DatabaseDSN, app.Events, zLog)
+	})
+
+	g.Go(func() error {
+		return webhook.NewDispatcher(&app).Run(gCtx)
+	})
+
 	zLog.Info("Worker starting...")
+	wk := worker.NewWorker(&app, worker.NewRequest(conf.AccrualAddress))
+	app.Accrual = wk
+	// Результат воркера сохраняется отдельно: errgroup вернет только первую ошибку,
+	// и брошенное по таймауту задание потерялось бы за отменой контекста
+	var workerErr error
+	workerStopped := make(chan struct{})
 	g.Go(func() error {
-		wRequest := worker.NewRequest(conf.AccrualAddress)
-		return worker.NewWorker(&app, wRequest).Run(gCtx)
+		defer close(workerStopped)
+		workerErr = wk.Run(gCtx)
+		return workerErr
+	})
+
+	g.Go(func() error {
+		watchReload(gCtx, zLog, logLevel, wk)
+		return nil
 	})
 
 	zLog.Info(
 		"Server starting...",
 		zap.String("address", conf.Address),
 		zap.String("log level", conf.LogLevel),
+		zap.Bool("tls", publicTLS != nil),
 	)
 
-	server := http.Server{
-		Addr:    conf.Address,
-		Handler: router.New(&app),
-		BaseContext: func(_ net.Listener) context.Context {
-			return gCtx
-		},
+	// Запросы не отменяются сигналом остановки: Shutdown дает им завершиться,
+	// и только по истечении таймаута контекст запросов отменяется принудительно
+	serveCtx, cancelServe := context.WithCancel(context.Background())
+	defer cancelServe()
+
+	server := newServer(&conf, conf.Address, router.New(&app), publicTLS)
+	server.BaseContext = func(_ net.Listener) context.Context {
+		return serveCtx
+	}
+	// Потоки SSE не завершаются сами, Shutdown ждал бы их до таймаута.
+	// Клиенты переподключатся к другому экземпляру с Last-Event-ID
+	server.RegisterOnShutdown(app.Events.Close)
+
+	g.Go(func() error {
+		return serve(server)
+	})
+
+	// Служебный listener останавливается после основного, чтобы /readyz
+	// успел отдать shutting_down, пока завершаются текущие запросы
+	serverStopped := make(chan struct{})
+
+	if conf.AdminAddress != "" {
+		zLog.Info(
+			"Admin listener starting...",
+			zap.String("address", conf.AdminAddress),
+			zap.Bool("mtls", adminTLS != nil),
+		)
+		adminServer := newServer(&conf, conf.AdminAddress, router.NewAdminListener(&app), adminTLS)
+
+		g.Go(func() error {
+			return serve(adminServer)
+		})
+		g.Go(func() error {
+			<-serverStopped
+			return adminServer.Shutdown(context.Background())
+		})
 	}
 
-	g.Go(server.ListenAndServe)
+	if conf.GRPCAddress != "" {
+		zLog.Info(
+			"gRPC server starting...",
+			zap.String("address", conf.GRPCAddress),
+			zap.Bool("tls", publicTLS != nil),
+		)
+		g.Go(func() error {
+			return rpc.Serve(gCtx, &app, conf.GRPCAddress, publicTLS)
+		})
+	}
 
+	// Порядок остановки: readiness перестает быть успешной, затем HTTP сервер и воркер
+	// завершают начатое параллельно в пределах одного ShutdownTimeout. БД закрывается после g.Wait.
 	g.Go(func() error {
-		<-mainCtx.Done()
-		zLog.Info("Server stopped")
-		return server.Shutdown(gCtx)
+		<-gCtx.Done()
+		app.Health.Shutdown()
+		defer close(serverStopped)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(conf.ShutdownTimeout)*time.Second)
+		defer cancel()
+		defer cancelServe()
+
+		err := server.Shutdown(ctx)
+		if err == nil {
+			zLog.Info("Server stopped")
+		}
+
+		zLog.Info("Waiting for worker jobs...")
+		select {
+		case <-workerStopped:
+		case <-ctx.Done():
+		}
+
+		if err != nil {
+			return fmt.Errorf("server shutdown fail: %w", err)
+		}
+
+		return nil
 	})
 
-	if err := g.Wait(); err != nil {
+	err = g.Wait()
+	if errors.Is(workerErr, worker.ErrDrainTimeout) {
+		return fmt.Errorf("exit reason: %w", workerErr)
+	}
+
+	if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, http.ErrServerClosed) {
 		return fmt.Errorf("exit reason: %w", err)
 	}
 
 	return nil
 }
 
-func migrationsUp(dsn string) error {
-	ex, err := os.Executable()
+func newRepository(tr repository.TxGetter, zLog *zap.Logger) application.Repository {
+	return application.Repository{
+		User:         repository.NewUser(tr, zLog),
+		Order:        repository.NewOrder(tr, zLog),
+		Balance:      repository.NewBalance(tr, zLog),
+		RecoveryCode: repository.NewRecoveryCode(tr, zLog),
+		Role:         repository.NewRole(tr, zLog),
+		APIKey:       repository.NewAPIKey(tr, zLog),
+		Identity:     repository.NewIdentity(tr, zLog),
+		Event:        repository.NewEvent(tr, zLog),
+		Webhook:      repository.NewWebhook(tr, zLog),
+		Outbox:       repository.NewOutbox(tr, zLog),
+		Audit:        repository.NewAudit(tr, zLog),
+	}
+}
+
+type reloader interface {
+	Reload(ctx context.Context, s worker.Settings) error
+}
+
+// watchReload по SIGHUP перечитывает конфигурацию и применяет настройки, которые
+// безопасно менять на ходу: уровень лога, интервал опроса и лимит воркера.
+// Остальные настройки требуют перезапуска.
+func watchReload(ctx context.Context, log *zap.Logger, level zap.AtomicLevel, w reloader) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			if err := reload(ctx, level, w); err != nil {
+				log.Error("config reload failed", zap.Error(err))
+				continue
+			}
+
+			log.Info("Config reloaded", zap.String("log level", level.String()))
+		}
+	}
+}
+
+func reload(ctx context.Context, level zap.AtomicLevel, w reloader) error {
+	conf, err := config.NewConfig(os.Args[1:])
 	if err != nil {
-		return fmt.Errorf("get executable path fail: %w", err)
+		return fmt.Errorf("reload: %w", err)
 	}
 
-	filePath := filepath.Dir(ex)
-	m, err := migrate.New("file://"+filePath+"/db/migrations", dsn)
+	if err := conf.Validate(); err != nil {
+		return fmt.Errorf("reload: %w", err)
+	}
+
+	lvl, err := zap.ParseAtomicLevel(conf.LogLevel)
 	if err != nil {
-		return fmt.Errorf("migrations instance fail: %w", err)
+		return fmt.Errorf("reload: parse log level fail: %w", err)
 	}
 
-	err = m.Up()
-	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return fmt.Errorf("migrations up fail: %w", err)
+	if err := w.Reload(ctx, worker.Settings{PollInterval: conf.PollInterval, RateLimit: conf.RateLimit}); err != nil {
+		return fmt.Errorf("reload: %w", err)
 	}
 
+	level.SetLevel(lvl.Level())
 	return nil
 }
+
+// migrationsEnsure при autoMigrate применяет миграции, затем проверяет, что схема
+// совпадает с известной бинарнику. Возвращает версию схемы для проверки готовности.
+func migrationsEnsure(dsn string, autoMigrate bool) (uint, error) {
+	mg, err := newMigrator(dsn)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		_ = mg.Close()
+	}()
+
+	if err := mg.Ensure(autoMigrate); err != nil {
+		return 0, fmt.Errorf("migrations fail: %w", err)
+	}
+
+	return mg.Latest(), nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/migration"
+)
+
+const migrationsDir = "db/migrations"
+
+//go:embed db/migrations/*.sql
+var migrationsFS embed.FS
+
+var ErrMigrateUsage = errors.New("usage: gophermart migrate up | down [N] | status | force VERSION [flags]")
+
+func newMigrator(dsn string) (*migration.Migrator, error) {
+	return migration.New(migrationsFS, migrationsDir, dsn)
+}
+
+// runMigrate подкоманда gophermart migrate. Флаги те же, что у сервера, нужна только БД.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return ErrMigrateUsage
+	}
+
+	action, args := args[0], args[1:]
+
+	// down и force принимают число перед флагами
+	n, rest, ok := number(args)
+	if action == "down" || action == "force" {
+		args = rest
+	}
+
+	if action == "down" && !ok {
+		n = 1
+	}
+
+	if action == "down" && n <= 0 {
+		return fmt.Errorf("migrate down: %w, got %d", migration.ErrStepsInvalid, n)
+	}
+
+	if action == "force" && !ok {
+		return ErrMigrateUsage
+	}
+
+	conf, err := config.NewConfig(args)
+	if err != nil {
+		return fmt.Errorf("migrate: init config fail: %w", err)
+	}
+
+	mg, err := newMigrator(conf.DatabaseDSN)
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+
+	defer func() {
+		_ = mg.Close()
+	}()
+
+	switch action {
+	case "up":
+		err = mg.Up()
+	case "down":
+		err = mg.Down(n)
+	case "force":
+		err = mg.Force(n)
+	case "status":
+	default:
+		return ErrMigrateUsage
+	}
+
+	if err != nil {
+		return fmt.Errorf("migrate %s: %w", action, err)
+	}
+
+	status, err := mg.Status()
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "version: %d\nlatest: %d\ndirty: %t\n", status.Version, status.Latest, status.Dirty)
+	return nil
+}
+
+func number(args []string) (int, []string, bool) {
+	if len(args) == 0 {
+		return 0, args, false
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, args, false
+	}
+
+	return n, args[1:], true
+}
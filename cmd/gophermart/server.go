@@ -0,0 +1,76 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/tlsconf"
+)
+
+// newServer HTTP сервер с таймаутами из конфигурации. WriteTimeout по умолчанию
+// выключен: он обрывал бы долгие SSE подписки.
+func newServer(conf *config.Config, addr string, h http.Handler, tlsConf *tls.Config) *http.Server {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           h,
+		TLSConfig:         tlsConf,
+		ReadHeaderTimeout: seconds(conf.ReadHeaderTimeout),
+		ReadTimeout:       seconds(conf.ReadTimeout),
+		WriteTimeout:      seconds(conf.WriteTimeout),
+		IdleTimeout:       seconds(conf.IdleTimeout),
+	}
+
+	// Пустая карта протоколов отключает HTTP/2, который иначе включается для TLS автоматически
+	if !conf.HTTP2 {
+		srv.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	}
+
+	return srv
+}
+
+// serve запускает сервер по TLS, если задан TLSConfig, иначе по HTTP.
+// Сертификат берется из TLSConfig.GetCertificate, поэтому пути файлов не передаются.
+func serve(srv *http.Server) error {
+	if srv.TLSConfig != nil {
+		return srv.ListenAndServeTLS("", "")
+	}
+
+	return srv.ListenAndServe()
+}
+
+// serverTLS конфигурации TLS основного и служебного серверов. Без сертификата
+// оба работают по HTTP, служебный переходит на mTLS только при заданном CA клиентов.
+func serverTLS(conf *config.Config, certs *tlsconf.Reloader) (*tls.Config, *tls.Config, error) {
+	if certs == nil {
+		return nil, nil, nil
+	}
+
+	opts := tlsconf.Options{
+		MinVersion: conf.TLSMinVersion,
+		Ciphers:    conf.TLSCiphers,
+	}
+
+	public, err := tlsconf.New(certs, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("server tls config fail: %w", err)
+	}
+
+	if conf.AdminClientCA == "" {
+		return public, nil, nil
+	}
+
+	opts.ClientCA = conf.AdminClientCA
+	admin, err := tlsconf.New(certs, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("admin tls config fail: %w", err)
+	}
+
+	return public, admin, nil
+}
+
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
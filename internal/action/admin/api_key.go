@@ -0,0 +1,95 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/response"
+	"github.com/arefev/gophermart/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type APIKeyCreateRequest struct {
+	Name   string   `json:"name" validate:"required,lte=255"`
+	Scopes []string `json:"scopes" validate:"required,min=1"`
+}
+
+type apiKeyCreateAction struct {
+	app *application.App
+}
+
+func NewAPIKeyCreateAction(app *application.App) *apiKeyCreateAction {
+	return &apiKeyCreateAction{
+		app: app,
+	}
+}
+
+func (a *apiKeyCreateAction) Handle(r *http.Request) (*response.APIKey, error) {
+	rKey := APIKeyCreateRequest{}
+	d := json.NewDecoder(r.Body)
+
+	if err := d.Decode(&rKey); err != nil {
+		return nil, fmt.Errorf("api key create from request %w: %w", service.ErrAPIKeyJSONDecodeFail, err)
+	}
+
+	v := service.NewValidator()
+	if err := v.Struct(rKey); err != nil {
+		return nil, fmt.Errorf("api key create from request %w: %w", service.ErrAPIKeyValidateFail, err)
+	}
+
+	plain, key, err := service.NewAPIKeyService(a.app).Create(r.Context(), rKey.Name, rKey.Scopes)
+	if err != nil {
+		return nil, fmt.Errorf("api key create from request fail: %w", err)
+	}
+
+	res := response.NewAPIKey(key)
+	res.Key = plain
+
+	return &res, nil
+}
+
+type apiKeyListAction struct {
+	app *application.App
+}
+
+func NewAPIKeyListAction(app *application.App) *apiKeyListAction {
+	return &apiKeyListAction{
+		app: app,
+	}
+}
+
+func (a *apiKeyListAction) Handle(r *http.Request) ([]model.APIKey, error) {
+	list, err := service.NewAPIKeyService(a.app).List(r.Context())
+	if err != nil {
+		return []model.APIKey{}, fmt.Errorf("api key list from request fail: %w", err)
+	}
+
+	return list, nil
+}
+
+type apiKeyRevokeAction struct {
+	app *application.App
+}
+
+func NewAPIKeyRevokeAction(app *application.App) *apiKeyRevokeAction {
+	return &apiKeyRevokeAction{
+		app: app,
+	}
+}
+
+func (a *apiKeyRevokeAction) Handle(r *http.Request) error {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return fmt.Errorf("api key revoke from request %w: %w", service.ErrAPIKeyNotFound, err)
+	}
+
+	if err := service.NewAPIKeyService(a.app).Revoke(r.Context(), id); err != nil {
+		return fmt.Errorf("api key revoke from request fail: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type RoleRequest struct {
+	Role string `json:"role" validate:"required,lte=50"`
+}
+
+type grantRoleAction struct {
+	app *application.App
+}
+
+func NewGrantRoleAction(app *application.App) *grantRoleAction {
+	return &grantRoleAction{
+		app: app,
+	}
+}
+
+func (g *grantRoleAction) Handle(r *http.Request) error {
+	rRole := RoleRequest{}
+	d := json.NewDecoder(r.Body)
+
+	if err := d.Decode(&rRole); err != nil {
+		return fmt.Errorf("grant role from request %w: %w", service.ErrRoleJSONDecodeFail, err)
+	}
+
+	v := service.NewValidator()
+	if err := v.Struct(rRole); err != nil {
+		return fmt.Errorf("grant role from request %w: %w", service.ErrRoleValidateFail, err)
+	}
+
+	login := chi.URLParam(r, "login")
+	if err := service.NewRoleService(g.app).Grant(r.Context(), login, rRole.Role); err != nil {
+		return fmt.Errorf("grant role from request fail: %w", err)
+	}
+
+	return nil
+}
+
+type revokeRoleAction struct {
+	app *application.App
+}
+
+func NewRevokeRoleAction(app *application.App) *revokeRoleAction {
+	return &revokeRoleAction{
+		app: app,
+	}
+}
+
+func (rv *revokeRoleAction) Handle(r *http.Request) error {
+	login := chi.URLParam(r, "login")
+	role := chi.URLParam(r, "role")
+
+	if err := service.NewRoleService(rv.app).Revoke(r.Context(), login, role); err != nil {
+		return fmt.Errorf("revoke role from request fail: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,268 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type BalanceAdjustRequest struct {
+	Reason string  `json:"reason" validate:"required,lte=500"`
+	Amount float64 `json:"amount" validate:"required"`
+}
+
+type OrderStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=NEW PROCESSING INVALID"`
+	Reason string `json:"reason" validate:"required,lte=500"`
+}
+
+type OrderRecheckRequest struct {
+	Reason string `json:"reason" validate:"required,lte=500"`
+}
+
+type userSearchAction struct {
+	app *application.App
+}
+
+func NewUserSearchAction(app *application.App) *userSearchAction {
+	return &userSearchAction{
+		app: app,
+	}
+}
+
+func (a *userSearchAction) Handle(r *http.Request) ([]model.User, error) {
+	actor, err := supportActor(a.app, r)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := service.NewSupportService(a.app, actor).SearchUsers(r.Context(), r.URL.Query().Get("q"))
+	if err != nil {
+		return nil, fmt.Errorf("user search from request fail: %w", err)
+	}
+
+	return list, nil
+}
+
+type userFindAction struct {
+	app *application.App
+}
+
+func NewUserFindAction(app *application.App) *userFindAction {
+	return &userFindAction{
+		app: app,
+	}
+}
+
+func (a *userFindAction) Handle(r *http.Request) (*model.User, error) {
+	actor, err := supportActor(a.app, r)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := service.NewSupportService(a.app, actor).FindUser(r.Context(), chi.URLParam(r, "login"))
+	if err != nil {
+		return nil, fmt.Errorf("user find from request fail: %w", err)
+	}
+
+	return user, nil
+}
+
+type userBalanceAction struct {
+	app *application.App
+}
+
+func NewUserBalanceAction(app *application.App) *userBalanceAction {
+	return &userBalanceAction{
+		app: app,
+	}
+}
+
+func (a *userBalanceAction) Handle(r *http.Request) (*model.Balance, error) {
+	actor, err := supportActor(a.app, r)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := service.NewSupportService(a.app, actor).Balance(r.Context(), chi.URLParam(r, "login"))
+	if err != nil {
+		return nil, fmt.Errorf("user balance from request fail: %w", err)
+	}
+
+	return balance, nil
+}
+
+type userOrdersAction struct {
+	app *application.App
+}
+
+func NewUserOrdersAction(app *application.App) *userOrdersAction {
+	return &userOrdersAction{
+		app: app,
+	}
+}
+
+func (a *userOrdersAction) Handle(r *http.Request) ([]model.Order, error) {
+	actor, err := supportActor(a.app, r)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := service.NewSupportService(a.app, actor).Orders(r.Context(), chi.URLParam(r, "login"))
+	if err != nil {
+		return nil, fmt.Errorf("user orders from request fail: %w", err)
+	}
+
+	return list, nil
+}
+
+type userWithdrawalsAction struct {
+	app *application.App
+}
+
+func NewUserWithdrawalsAction(app *application.App) *userWithdrawalsAction {
+	return &userWithdrawalsAction{
+		app: app,
+	}
+}
+
+func (a *userWithdrawalsAction) Handle(r *http.Request) ([]model.Withdrawal, error) {
+	actor, err := supportActor(a.app, r)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := service.NewSupportService(a.app, actor).Withdrawals(r.Context(), chi.URLParam(r, "login"))
+	if err != nil {
+		return nil, fmt.Errorf("user withdrawals from request fail: %w", err)
+	}
+
+	return list, nil
+}
+
+type balanceAdjustAction struct {
+	app *application.App
+}
+
+func NewBalanceAdjustAction(app *application.App) *balanceAdjustAction {
+	return &balanceAdjustAction{
+		app: app,
+	}
+}
+
+func (a *balanceAdjustAction) Handle(r *http.Request) (*model.Balance, error) {
+	rAdjust := BalanceAdjustRequest{}
+	d := json.NewDecoder(r.Body)
+
+	if err := d.Decode(&rAdjust); err != nil {
+		return nil, fmt.Errorf("balance adjust from request %w: %w", service.ErrSupportJSONDecodeFail, err)
+	}
+
+	v := service.NewValidator()
+	if err := v.Struct(rAdjust); err != nil {
+		return nil, fmt.Errorf("balance adjust from request %w: %w", service.ErrSupportValidateFail, err)
+	}
+
+	actor, err := supportActor(a.app, r)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := service.NewSupportService(a.app, actor).
+		AdjustBalance(r.Context(), chi.URLParam(r, "login"), rAdjust.Amount, rAdjust.Reason)
+	if err != nil {
+		return nil, fmt.Errorf("balance adjust from request fail: %w", err)
+	}
+
+	return balance, nil
+}
+
+type orderStatusAction struct {
+	app *application.App
+}
+
+func NewOrderStatusAction(app *application.App) *orderStatusAction {
+	return &orderStatusAction{
+		app: app,
+	}
+}
+
+func (a *orderStatusAction) Handle(r *http.Request) error {
+	rStatus := OrderStatusRequest{}
+	d := json.NewDecoder(r.Body)
+
+	if err := d.Decode(&rStatus); err != nil {
+		return fmt.Errorf("order status from request %w: %w", service.ErrSupportJSONDecodeFail, err)
+	}
+
+	v := service.NewValidator()
+	if err := v.Struct(rStatus); err != nil {
+		return fmt.Errorf("order status from request %w: %w", service.ErrSupportValidateFail, err)
+	}
+
+	actor, err := supportActor(a.app, r)
+	if err != nil {
+		return err
+	}
+
+	status := model.OrderStatusFromString(rStatus.Status)
+	err = service.NewSupportService(a.app, actor).
+		OverrideOrderStatus(r.Context(), chi.URLParam(r, "number"), status, rStatus.Reason)
+	if err != nil {
+		return fmt.Errorf("order status from request fail: %w", err)
+	}
+
+	return nil
+}
+
+type orderRecheckAction struct {
+	app *application.App
+}
+
+func NewOrderRecheckAction(app *application.App) *orderRecheckAction {
+	return &orderRecheckAction{
+		app: app,
+	}
+}
+
+func (a *orderRecheckAction) Handle(r *http.Request) (*model.Order, error) {
+	rRecheck := OrderRecheckRequest{}
+	d := json.NewDecoder(r.Body)
+
+	if err := d.Decode(&rRecheck); err != nil {
+		return nil, fmt.Errorf("order recheck from request %w: %w", service.ErrSupportJSONDecodeFail, err)
+	}
+
+	v := service.NewValidator()
+	if err := v.Struct(rRecheck); err != nil {
+		return nil, fmt.Errorf("order recheck from request %w: %w", service.ErrSupportValidateFail, err)
+	}
+
+	actor, err := supportActor(a.app, r)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := service.NewSupportService(a.app, actor).
+		Recheck(r.Context(), chi.URLParam(r, "number"), rRecheck.Reason)
+	if err != nil {
+		return nil, fmt.Errorf("order recheck from request fail: %w", err)
+	}
+
+	return order, nil
+}
+
+// supportActor автор действия в журнале аудита: авторизованный сотрудник.
+func supportActor(app *application.App, r *http.Request) (string, error) {
+	user, err := service.NewUserService(app).Authorized(r.Context())
+	if err != nil {
+		return "", service.ErrUserNotAuthorized
+	}
+
+	return "user:" + user.Login, nil
+}
@@ -0,0 +1,120 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/response"
+	"github.com/arefev/gophermart/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type WebhookCreateRequest struct {
+	URL    string   `json:"url" validate:"required,url,lte=2048"`
+	Login  string   `json:"login" validate:"lte=20"`
+	Events []string `json:"events"`
+}
+
+type webhookCreateAction struct {
+	app *application.App
+}
+
+func NewWebhookCreateAction(app *application.App) *webhookCreateAction {
+	return &webhookCreateAction{
+		app: app,
+	}
+}
+
+func (a *webhookCreateAction) Handle(r *http.Request) (*response.Webhook, error) {
+	rHook := WebhookCreateRequest{}
+	d := json.NewDecoder(r.Body)
+
+	if err := d.Decode(&rHook); err != nil {
+		return nil, fmt.Errorf("webhook create from request %w: %w", service.ErrWebhookJSONDecodeFail, err)
+	}
+
+	v := service.NewValidator()
+	if err := v.Struct(rHook); err != nil {
+		return nil, fmt.Errorf("webhook create from request %w: %w", service.ErrWebhookValidateFail, err)
+	}
+
+	hook, err := service.NewWebhookService(a.app).Create(r.Context(), rHook.Login, rHook.URL, rHook.Events)
+	if err != nil {
+		return nil, fmt.Errorf("webhook create from request fail: %w", err)
+	}
+
+	res := response.NewWebhook(hook)
+	res.Secret = hook.Secret
+
+	return &res, nil
+}
+
+type webhookListAction struct {
+	app *application.App
+}
+
+func NewWebhookListAction(app *application.App) *webhookListAction {
+	return &webhookListAction{
+		app: app,
+	}
+}
+
+func (a *webhookListAction) Handle(r *http.Request) ([]model.Webhook, error) {
+	list, err := service.NewWebhookService(a.app).List(r.Context())
+	if err != nil {
+		return []model.Webhook{}, fmt.Errorf("webhook list from request fail: %w", err)
+	}
+
+	return list, nil
+}
+
+type webhookDeleteAction struct {
+	app *application.App
+}
+
+func NewWebhookDeleteAction(app *application.App) *webhookDeleteAction {
+	return &webhookDeleteAction{
+		app: app,
+	}
+}
+
+func (a *webhookDeleteAction) Handle(r *http.Request) error {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return fmt.Errorf("webhook delete from request %w: %w", service.ErrWebhookNotFound, err)
+	}
+
+	if err := service.NewWebhookService(a.app).Deactivate(r.Context(), id); err != nil {
+		return fmt.Errorf("webhook delete from request fail: %w", err)
+	}
+
+	return nil
+}
+
+type webhookDeliveriesAction struct {
+	app *application.App
+}
+
+func NewWebhookDeliveriesAction(app *application.App) *webhookDeliveriesAction {
+	return &webhookDeliveriesAction{
+		app: app,
+	}
+}
+
+func (a *webhookDeliveriesAction) Handle(r *http.Request) ([]model.WebhookDelivery, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return nil, fmt.Errorf("webhook deliveries from request %w: %w", service.ErrWebhookNotFound, err)
+	}
+
+	list, err := service.NewWebhookService(a.app).Deliveries(r.Context(), id)
+	if err != nil {
+		return nil, fmt.Errorf("webhook deliveries from request fail: %w", err)
+	}
+
+	return list, nil
+}
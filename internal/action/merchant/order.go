@@ -0,0 +1,97 @@
+package merchant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/action/order"
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/service"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrOrderJSONDecodeFail = errors.New("json decode fail")
+	ErrOrderValidateFail   = errors.New("validate fail")
+	ErrOrderUserNotFound   = errors.New("user not found")
+)
+
+type OrderCreateRequest struct {
+	Login  string `json:"login" validate:"required,lte=20"`
+	Number string `json:"number" validate:"required"`
+}
+
+type orderCreateAction struct {
+	app *application.App
+}
+
+func NewOrderCreateAction(app *application.App) *orderCreateAction {
+	return &orderCreateAction{
+		app: app,
+	}
+}
+
+func (o *orderCreateAction) Handle(r *http.Request) error {
+	key, err := service.NewAPIKeyService(o.app).Authorized(r.Context())
+	if err != nil {
+		return fmt.Errorf("merchant order create from request: %w", err)
+	}
+
+	rOrder := OrderCreateRequest{}
+	d := json.NewDecoder(r.Body)
+
+	if err := d.Decode(&rOrder); err != nil {
+		return fmt.Errorf("merchant order create from request %w: %w", ErrOrderJSONDecodeFail, err)
+	}
+
+	v := service.NewValidator()
+	if err := v.Struct(rOrder); err != nil {
+		return fmt.Errorf("merchant order create from request %w: %w", ErrOrderValidateFail, err)
+	}
+
+	user, err := o.findUser(r.Context(), rOrder.Login)
+	if err != nil {
+		return fmt.Errorf("merchant order create from request find user fail: %w", err)
+	}
+
+	o.app.Logger(r.Context()).Debug(
+		"merchant order create",
+		zap.String("api key", key.Prefix),
+		zap.String("login", user.Login),
+		zap.String("number", rOrder.Number),
+	)
+
+	if err := order.NewCreateAction(o.app).Create(r.Context(), user, rOrder.Number); err != nil {
+		return fmt.Errorf("merchant order create from request fail: %w", err)
+	}
+
+	return nil
+}
+
+func (o *orderCreateAction) findUser(ctx context.Context, login string) (*model.User, error) {
+	var user *model.User
+	err := o.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		var ok bool
+		user, ok = o.app.Rep.User.FindByLogin(ctx, login)
+		if !ok {
+			return ErrOrderUserNotFound
+		}
+
+		// Заблокированный пользователь не получает новые заказы и через ключ магазина
+		if user.Blocked {
+			return service.ErrUserBlocked
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("find user transaction fail: %w", err)
+	}
+
+	return user, nil
+}
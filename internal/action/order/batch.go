@@ -0,0 +1,231 @@
+package order
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/metrics"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/outbox"
+	"github.com/arefev/gophermart/internal/response"
+	"github.com/arefev/gophermart/internal/service"
+	"github.com/arefev/gophermart/internal/service/alg"
+	"github.com/go-playground/validator/v10"
+)
+
+const BatchDefaultMaxSize = 100
+
+// batchEntryMaxBytes запас на один номер в теле запроса вместе с кавычками и разделителями.
+// Номер длиннее 50 символов невалиден, но попадает в ответ как invalid.
+const batchEntryMaxBytes = 256
+
+const (
+	BatchResultAccepted        = "accepted"
+	BatchResultUploadedByUser  = "already_uploaded"
+	BatchResultUploadedByOther = "uploaded_by_other_user"
+	BatchResultInvalid         = "invalid"
+)
+
+var (
+	ErrOrderBatchDecodeFail = errors.New("batch decode fail")
+	ErrOrderBatchEmpty      = errors.New("batch is empty")
+	ErrOrderBatchTooLarge   = errors.New("batch is too large")
+)
+
+type batchAction struct {
+	app *application.App
+}
+
+func NewBatchAction(app *application.App) *batchAction {
+	return &batchAction{
+		app: app,
+	}
+}
+
+// Handle загружает пачку номеров заказов в одной транзакции и возвращает результат по каждому номеру.
+// Тело запроса: JSON массив строк или text/csv, номер в первой колонке.
+func (b *batchAction) Handle(r *http.Request) (*response.OrderBatch, error) {
+	user, err := service.NewUserService(b.app).Authorized(r.Context())
+	if err != nil {
+		return nil, service.ErrUserNotAuthorized
+	}
+
+	limit := b.maxSize()
+	numbers, err := b.decode(r, limit)
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return nil, fmt.Errorf("%w: body exceeds %d bytes", ErrOrderBatchTooLarge, maxBytesErr.Limit)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("order batch from request %w: %w", ErrOrderBatchDecodeFail, err)
+	}
+
+	if len(numbers) == 0 {
+		return nil, ErrOrderBatchEmpty
+	}
+
+	if len(numbers) > limit {
+		return nil, fmt.Errorf("%w: more than %d numbers", ErrOrderBatchTooLarge, limit)
+	}
+
+	res, err := b.upload(r.Context(), user, numbers)
+	if err != nil {
+		return nil, fmt.Errorf("order batch from request fail: %w", err)
+	}
+
+	return res, nil
+}
+
+func (b *batchAction) upload(ctx context.Context, user *model.User, numbers []string) (*response.OrderBatch, error) {
+	create := NewCreateAction(b.app)
+	res := response.OrderBatch{Results: make([]response.OrderBatchItem, len(numbers))}
+
+	err := b.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		for i, number := range numbers {
+			item := &res.Results[i]
+			item.Number = number
+
+			rOrder, err := create.validate(number)
+			if err != nil {
+				item.Result = BatchResultInvalid
+				item.Reason = invalidReason(err)
+				continue
+			}
+
+			item.Number = rOrder.Number
+			if order, ok := b.app.Rep.Order.FindByNumber(ctx, rOrder.Number); ok {
+				item.Result = BatchResultUploadedByOther
+				if order.UserID == user.ID {
+					item.Result = BatchResultUploadedByUser
+				}
+				continue
+			}
+
+			if err := b.app.Rep.Order.Create(ctx, user.ID, model.OrderStatusNew, rOrder.Number); err != nil {
+				return fmt.Errorf("create order %s fail: %w", rOrder.Number, err)
+			}
+
+			uploaded := outbox.OrderUploaded{Number: rOrder.Number, UserID: user.ID}
+			if err := outbox.Emit(ctx, b.app, model.OutboxOrderUploaded, uploaded); err != nil {
+				return err
+			}
+
+			item.Result = BatchResultAccepted
+			res.Accepted++
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("order batch transaction fail: %w", err)
+	}
+
+	metrics.OrdersUploaded.Add(float64(res.Accepted))
+	return &res, nil
+}
+
+// decode читает не больше limit+1 номеров: этого достаточно, чтобы отклонить слишком
+// большую пачку, не разбирая ее целиком. Размер тела ограничен по тому же лимиту.
+func (b *batchAction) decode(r *http.Request, limit int) ([]string, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("parse content type fail: %w", err)
+	}
+
+	body := http.MaxBytesReader(nil, r.Body, int64(limit+1)*batchEntryMaxBytes)
+	switch mediaType {
+	case "text/csv":
+		return decodeCSV(body, limit)
+	case "application/json":
+		return decodeJSON(body, limit)
+	default:
+		return nil, fmt.Errorf("unsupported content type %s", mediaType)
+	}
+}
+
+func decodeCSV(body io.Reader, limit int) ([]string, error) {
+	var numbers []string
+	reader := csv.NewReader(body)
+	for i := 0; len(numbers) <= limit; i++ {
+		rec, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("read csv fail: %w", err)
+		}
+
+		number := strings.TrimSpace(rec[0])
+		if number == "" || (i == 0 && strings.EqualFold(number, "number")) {
+			continue
+		}
+
+		numbers = append(numbers, number)
+	}
+
+	return numbers, nil
+}
+
+func decodeJSON(body io.Reader, limit int) ([]string, error) {
+	d := json.NewDecoder(body)
+	t, err := d.Token()
+	if err != nil {
+		return nil, fmt.Errorf("decode json fail: %w", err)
+	}
+
+	if t != json.Delim('[') {
+		return nil, errors.New("decode json fail: body must be an array")
+	}
+
+	var numbers []string
+	for d.More() {
+		if len(numbers) > limit {
+			return numbers, nil
+		}
+
+		var number string
+		if err := d.Decode(&number); err != nil {
+			return nil, fmt.Errorf("decode json fail: %w", err)
+		}
+
+		numbers = append(numbers, number)
+	}
+
+	if _, err := d.Token(); err != nil {
+		return nil, fmt.Errorf("decode json fail: %w", err)
+	}
+
+	return numbers, nil
+}
+
+func (b *batchAction) maxSize() int {
+	if b.app.Conf.OrderBatchMax > 0 {
+		return b.app.Conf.OrderBatchMax
+	}
+
+	return BatchDefaultMaxSize
+}
+
+func invalidReason(err error) string {
+	var vErr validator.ValidationErrors
+	switch {
+	case errors.Is(err, alg.ErrLuhnInvalid):
+		return "luhn"
+	case errors.As(err, &vErr) && len(vErr) > 0:
+		return vErr[0].Tag()
+	default:
+		return "invalid"
+	}
+}
@@ -9,10 +9,11 @@ import (
 	"strings"
 
 	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/metrics"
 	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/outbox"
 	"github.com/arefev/gophermart/internal/service"
 	"github.com/arefev/gophermart/internal/service/alg"
-	"github.com/go-playground/validator/v10"
 )
 
 var (
@@ -36,10 +37,9 @@ func NewCreateAction(app *application.App) *createAction {
 }
 
 func (c *createAction) Handle(r *http.Request) error {
-	const errMsg = "order create from request:"
-	rOrder, err := c.validate(r)
+	number, err := io.ReadAll(r.Body)
 	if err != nil {
-		return fmt.Errorf("%w %w", ErrOrderCreateValidateFail, err)
+		return fmt.Errorf("%w read body fail: %w", ErrOrderCreateValidateFail, err)
 	}
 
 	user, err := service.NewUserService(c.app).Authorized(r.Context())
@@ -47,7 +47,19 @@ func (c *createAction) Handle(r *http.Request) error {
 		return service.ErrUserNotAuthorized
 	}
 
-	err = c.app.TrManager.Do(r.Context(), func(ctx context.Context) error {
+	return c.Create(r.Context(), user, string(number))
+}
+
+// Create загружает номер заказа пользователя. Используется как для запросов
+// самого пользователя, так и для загрузки заказов от его имени по ключу API.
+func (c *createAction) Create(ctx context.Context, user *model.User, number string) error {
+	const errMsg = "order create from request:"
+	rOrder, err := c.validate(number)
+	if err != nil {
+		return fmt.Errorf("%w %w", ErrOrderCreateValidateFail, err)
+	}
+
+	err = c.app.TrManager.Do(ctx, func(ctx context.Context) error {
 		order, ok := c.app.Rep.Order.FindByNumber(ctx, rOrder.Number)
 		if ok {
 			if order.UserID == user.ID {
@@ -61,31 +73,28 @@ func (c *createAction) Handle(r *http.Request) error {
 			return fmt.Errorf("%s create fail: %w", errMsg, err)
 		}
 
-		return nil
+		event := outbox.OrderUploaded{Number: rOrder.Number, UserID: user.ID}
+		return outbox.Emit(ctx, c.app, model.OutboxOrderUploaded, event)
 	})
 
 	if err != nil {
 		return fmt.Errorf("%s transaction fail: %w", errMsg, err)
 	}
 
+	metrics.OrdersUploaded.Inc()
 	return nil
 }
 
-func (c *createAction) validate(r *http.Request) (*OrderCreateRequest, error) {
-	number, err := io.ReadAll(r.Body)
-	if err != nil {
-		return nil, fmt.Errorf("read body fail: %w", err)
-	}
-
+func (c *createAction) validate(number string) (*OrderCreateRequest, error) {
 	rOrder := OrderCreateRequest{
-		Number: strings.Trim(string(number), " "),
+		Number: strings.Trim(number, " "),
 	}
 
 	if err := alg.CheckLuhn(rOrder.Number); err != nil {
 		return nil, fmt.Errorf("luhn check fail: %w", err)
 	}
 
-	v := validator.New()
+	v := service.NewValidator()
 	if err := v.Struct(rOrder); err != nil {
 		return nil, fmt.Errorf("validation fail: %w", err)
 	}
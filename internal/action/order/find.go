@@ -0,0 +1,56 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/response"
+	"github.com/arefev/gophermart/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+var ErrOrderNotFound = errors.New("order not found")
+
+type findAction struct {
+	app *application.App
+}
+
+func NewFindAction(app *application.App) *findAction {
+	return &findAction{
+		app: app,
+	}
+}
+
+// Handle возвращает заказ пользователя с историей статусов.
+// Чужой заказ не отличается от несуществующего.
+func (f *findAction) Handle(r *http.Request) (*response.OrderDetail, error) {
+	user, err := service.NewUserService(f.app).Authorized(r.Context())
+	if err != nil {
+		return nil, service.ErrUserNotAuthorized
+	}
+
+	number := chi.URLParam(r, "number")
+
+	var order *model.Order
+	var history []model.OrderStatusChange
+	err = f.app.TrManager.Do(r.Context(), func(ctx context.Context) error {
+		var ok bool
+		order, ok = f.app.Rep.Order.FindByNumber(ctx, number)
+		if !ok || order.UserID != user.ID {
+			return ErrOrderNotFound
+		}
+
+		history = f.app.Rep.Order.StatusHistory(ctx, order.ID)
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("order find transaction fail: %w", err)
+	}
+
+	return response.NewOrderDetail(order, history), nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/arefev/gophermart/internal/application"
 	"github.com/arefev/gophermart/internal/model"
@@ -22,22 +23,49 @@ func NewListAction(app *application.App) *listAction {
 	}
 }
 
-func (l *listAction) Handle(r *http.Request) ([]model.Order, error) {
+// Handle возвращает заказы пользователя и курсор следующей страницы,
+// если в запросе переданы параметры пагинации.
+func (l *listAction) Handle(r *http.Request) ([]model.Order, string, error) {
 	user, err := service.NewUserService(l.app).Authorized(r.Context())
 	if err != nil {
-		return []model.Order{}, service.ErrUserNotAuthorized
+		return []model.Order{}, "", service.ErrUserNotAuthorized
+	}
+
+	return l.List(r.Context(), user, r.URL.Query())
+}
+
+// List возвращает заказы пользователя по параметрам списка, используется HTTP и gRPC API.
+func (l *listAction) List(ctx context.Context, user *model.User, q url.Values) ([]model.Order, string, error) {
+	filter, err := service.ParseListFilter(q, true)
+	if err != nil {
+		return []model.Order{}, "", fmt.Errorf("order list parse query fail: %w", err)
 	}
 
 	var orders []model.Order
-	err = l.app.TrManager.Do(r.Context(), func(ctx context.Context) error {
-		orders = l.app.Rep.Order.GetByUserID(ctx, user.ID)
+	err = l.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		if filter == nil {
+			orders = l.app.Rep.Order.GetByUserID(ctx, user.ID)
+			return nil
+		}
+
+		page := *filter
+		page.Limit++
+		orders = l.app.Rep.Order.PageByUserID(ctx, user.ID, page)
 
 		return nil
 	})
 
 	if err != nil {
-		return []model.Order{}, fmt.Errorf("order list transaction fail: %w", err)
+		return []model.Order{}, "", fmt.Errorf("order list transaction fail: %w", err)
+	}
+
+	if filter == nil {
+		return orders, "", nil
 	}
 
-	return orders, nil
+	orders, next := service.NextPage(orders, filter.Limit, func(o *model.Order) model.Cursor {
+		return model.Cursor{At: o.UploadedAt, ID: o.ID}
+	})
+
+	return orders, next, nil
 }
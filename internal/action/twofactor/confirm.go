@@ -0,0 +1,60 @@
+package twofactor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/response"
+	"github.com/arefev/gophermart/internal/service"
+)
+
+type CodeRequest struct {
+	Code string `json:"code" validate:"required,lte=20"`
+}
+
+type confirmAction struct {
+	app *application.App
+}
+
+func NewConfirmAction(app *application.App) *confirmAction {
+	return &confirmAction{
+		app: app,
+	}
+}
+
+func (c *confirmAction) Handle(r *http.Request) (*response.RecoveryCodes, error) {
+	user, err := service.NewUserService(c.app).Authorized(r.Context())
+	if err != nil {
+		return nil, service.ErrUserNotAuthorized
+	}
+
+	rCode, err := decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("two factor confirm from request: %w", err)
+	}
+
+	codes, err := service.NewTwoFactorService(c.app).Confirm(r.Context(), user, rCode.Code)
+	if err != nil {
+		return nil, fmt.Errorf("two factor confirm from request fail: %w", err)
+	}
+
+	return &response.RecoveryCodes{Codes: codes}, nil
+}
+
+func decode(r *http.Request) (*CodeRequest, error) {
+	rCode := CodeRequest{}
+	d := json.NewDecoder(r.Body)
+
+	if err := d.Decode(&rCode); err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrTwoFactorJSONDecodeFail, err)
+	}
+
+	v := service.NewValidator()
+	if err := v.Struct(rCode); err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrTwoFactorValidateFail, err)
+	}
+
+	return &rCode, nil
+}
@@ -0,0 +1,34 @@
+package twofactor
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/response"
+	"github.com/arefev/gophermart/internal/service"
+)
+
+type enrollAction struct {
+	app *application.App
+}
+
+func NewEnrollAction(app *application.App) *enrollAction {
+	return &enrollAction{
+		app: app,
+	}
+}
+
+func (e *enrollAction) Handle(r *http.Request) (*response.TwoFactorEnroll, error) {
+	user, err := service.NewUserService(e.app).Authorized(r.Context())
+	if err != nil {
+		return nil, service.ErrUserNotAuthorized
+	}
+
+	secret, uri, err := service.NewTwoFactorService(e.app).Enroll(r.Context(), user)
+	if err != nil {
+		return nil, fmt.Errorf("two factor enroll from request fail: %w", err)
+	}
+
+	return &response.TwoFactorEnroll{Secret: secret, URI: uri}, nil
+}
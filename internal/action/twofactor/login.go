@@ -0,0 +1,54 @@
+package twofactor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/service"
+	"github.com/arefev/gophermart/internal/service/jwt"
+)
+
+type loginAction struct {
+	app *application.App
+}
+
+func NewLoginAction(app *application.App) *loginAction {
+	return &loginAction{
+		app: app,
+	}
+}
+
+func (l *loginAction) Handle(r *http.Request) (*jwt.Token, error) {
+	if _, err := service.NewUserService(l.app).Authorized(r.Context()); err != nil {
+		return nil, service.ErrUserNotAuthorized
+	}
+
+	rCode := CodeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&rCode); err != nil {
+		return nil, fmt.Errorf("two factor login from request %w: %w", service.ErrTwoFactorJSONDecodeFail, err)
+	}
+
+	return l.Login(r.Context(), &rCode)
+}
+
+// Login проверяет код второго шага и выдает токен, используется HTTP и gRPC API.
+func (l *loginAction) Login(ctx context.Context, rCode *CodeRequest) (*jwt.Token, error) {
+	user, err := service.NewUserService(l.app).Authorized(ctx)
+	if err != nil {
+		return nil, service.ErrUserNotAuthorized
+	}
+
+	if err := service.NewValidator().Struct(rCode); err != nil {
+		return nil, fmt.Errorf("two factor login from request %w: %w", service.ErrTwoFactorValidateFail, err)
+	}
+
+	token, err := service.NewTwoFactorService(l.app).Login(ctx, user, rCode.Code)
+	if err != nil {
+		return nil, fmt.Errorf("two factor login from request fail: %w", err)
+	}
+
+	return token, nil
+}
@@ -1,6 +1,7 @@
 package user
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -34,13 +35,18 @@ func (a *authAction) Handle(r *http.Request) (*jwt.Token, error) {
 		return nil, fmt.Errorf("auth from request %w: %w", service.ErrAuthJSONDecodeFail, err)
 	}
 
+	return a.Login(r.Context(), &rUser)
+}
+
+// Login проверяет логин и пароль и выдает токен, используется HTTP и gRPC API.
+func (a *authAction) Login(ctx context.Context, rUser *UserAuthRequest) (*jwt.Token, error) {
 	v := validator.New()
 	if err := v.Struct(rUser); err != nil {
 		return nil, fmt.Errorf("auth from request %w: %w", service.ErrAuthValidateFail, err)
 	}
 
 	s := service.NewUserService(a.app)
-	token, err := s.Authorize(r.Context(), rUser.Login, rUser.Password)
+	token, err := s.Authorize(ctx, rUser.Login, rUser.Password)
 	if err != nil {
 		return nil, fmt.Errorf("auth from request fail: %w", err)
 	}
@@ -0,0 +1,62 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/service"
+	"github.com/arefev/gophermart/internal/service/jwt"
+)
+
+const OIDCStateCookie = "oidc_state"
+
+type oidcLoginAction struct {
+	app *application.App
+}
+
+func NewOIDCLoginAction(app *application.App) *oidcLoginAction {
+	return &oidcLoginAction{
+		app: app,
+	}
+}
+
+// Handle возвращает адрес провайдера для редиректа и значение cookie с состоянием входа.
+func (a *oidcLoginAction) Handle(r *http.Request) (string, string, error) {
+	url, state, err := service.NewOIDCService(a.app).Begin(r.Context())
+	if err != nil {
+		return "", "", fmt.Errorf("oidc login from request fail: %w", err)
+	}
+
+	return url, state, nil
+}
+
+type oidcCallbackAction struct {
+	app *application.App
+}
+
+func NewOIDCCallbackAction(app *application.App) *oidcCallbackAction {
+	return &oidcCallbackAction{
+		app: app,
+	}
+}
+
+func (a *oidcCallbackAction) Handle(r *http.Request) (*jwt.Token, error) {
+	q := r.URL.Query()
+	if e := q.Get("error"); e != "" {
+		return nil, fmt.Errorf("oidc callback from request %w: %s", service.ErrOIDCDenied, e)
+	}
+
+	cookie, err := r.Cookie(OIDCStateCookie)
+	if err != nil {
+		return nil, fmt.Errorf("oidc callback from request %w: %w", service.ErrOIDCStateMismatch, err)
+	}
+
+	s := service.NewOIDCService(a.app)
+	token, err := s.Complete(r.Context(), cookie.Value, q.Get("state"), q.Get("code"))
+	if err != nil {
+		return nil, fmt.Errorf("oidc callback from request fail: %w", err)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,60 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/service"
+	"github.com/arefev/gophermart/internal/service/password"
+)
+
+type PasswordChangeRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,nefield=CurrentPassword"`
+}
+
+type passwordAction struct {
+	app *application.App
+}
+
+func NewPasswordAction(app *application.App) *passwordAction {
+	return &passwordAction{
+		app: app,
+	}
+}
+
+func (p *passwordAction) Handle(r *http.Request) error {
+	user, err := service.NewUserService(p.app).Authorized(r.Context())
+	if err != nil {
+		return service.ErrUserNotAuthorized
+	}
+
+	rPwd := PasswordChangeRequest{}
+	d := json.NewDecoder(r.Body)
+
+	if err := d.Decode(&rPwd); err != nil {
+		return fmt.Errorf("password change from request %w: %w", service.ErrPasswordJSONDecodeFail, err)
+	}
+
+	v := service.NewValidator()
+	if err := v.Struct(rPwd); err != nil {
+		return fmt.Errorf("password change from request %w: %w", service.ErrPasswordValidateFail, err)
+	}
+
+	s := service.NewUserService(p.app)
+	if err := s.ValidatePassword("new_password", user.Login, rPwd.NewPassword); err != nil {
+		if password.IsPolicyError(err) {
+			return fmt.Errorf("password change from request %w: %w", service.ErrPasswordValidateFail, err)
+		}
+
+		return fmt.Errorf("password change from request validate password fail: %w", err)
+	}
+
+	if err := s.ChangePassword(r.Context(), user, rPwd.CurrentPassword, rPwd.NewPassword); err != nil {
+		return fmt.Errorf("password change from request fail: %w", err)
+	}
+
+	return nil
+}
@@ -1,18 +1,19 @@
 package user
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 
 	"github.com/arefev/gophermart/internal/application"
 	"github.com/arefev/gophermart/internal/service"
-	"github.com/go-playground/validator/v10"
+	"github.com/arefev/gophermart/internal/service/password"
 )
 
 type UserCreateRequest struct {
 	Login    string `json:"login" validate:"required,gte=1,lte=20,alphanum"`
-	Password string `json:"password" validate:"required,lte=40"`
+	Password string `json:"password" validate:"required"`
 }
 
 type registerAction struct {
@@ -33,15 +34,32 @@ func (r *registerAction) Handle(req *http.Request) (*UserCreateRequest, error) {
 		return nil, fmt.Errorf("register from request %w: %w", service.ErrRegisterJSONDecodeFail, err)
 	}
 
-	v := validator.New()
+	if err := r.Register(req.Context(), &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Register проверяет и сохраняет нового пользователя, используется HTTP и gRPC API.
+func (r *registerAction) Register(ctx context.Context, user *UserCreateRequest) error {
+	v := service.NewValidator()
 	if err := v.Struct(user); err != nil {
-		return nil, fmt.Errorf("register from request %w: %w", service.ErrRegisterValidateFail, err)
+		return fmt.Errorf("register from request %w: %w", service.ErrRegisterValidateFail, err)
 	}
 
 	s := service.NewUserService(r.app)
-	if err := s.Create(req.Context(), user.Login, user.Password); err != nil {
-		return nil, fmt.Errorf("register from request save fail: %w", err)
+	if err := s.ValidatePassword("password", user.Login, user.Password); err != nil {
+		if password.IsPolicyError(err) {
+			return fmt.Errorf("register from request %w: %w", service.ErrRegisterValidateFail, err)
+		}
+
+		return fmt.Errorf("register from request validate password fail: %w", err)
 	}
 
-	return &user, nil
+	if err := s.Create(ctx, user.Login, user.Password); err != nil {
+		return fmt.Errorf("register from request save fail: %w", err)
+	}
+
+	return nil
 }
@@ -8,10 +8,14 @@ import (
 	"net/http"
 
 	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/events"
+	"github.com/arefev/gophermart/internal/metrics"
 	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/outbox"
 	"github.com/arefev/gophermart/internal/service"
 	"github.com/arefev/gophermart/internal/service/alg"
 	"github.com/arefev/gophermart/internal/trm"
+	"github.com/arefev/gophermart/internal/webhook"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -37,9 +41,11 @@ func NewCreateAction(app *application.App) *createAction {
 }
 
 func (c *createAction) Handle(r *http.Request) error {
-	wr, err := c.validate(r)
-	if err != nil {
-		return fmt.Errorf("validate withdrawal from request fail: %w", err)
+	wr := CreateRequest{}
+	d := json.NewDecoder(r.Body)
+
+	if err := d.Decode(&wr); err != nil {
+		return fmt.Errorf("validate withdrawal from request fail: decode json body fail: %w", err)
 	}
 
 	user, err := service.NewUserService(c.app).Authorized(r.Context())
@@ -47,7 +53,16 @@ func (c *createAction) Handle(r *http.Request) error {
 		return service.ErrUserNotAuthorized
 	}
 
-	if err := c.withdrawal(r.Context(), user, wr); err != nil {
+	return c.Withdraw(r.Context(), user, &wr)
+}
+
+// Withdraw проверяет запрос и списывает баллы пользователя, используется HTTP и gRPC API.
+func (c *createAction) Withdraw(ctx context.Context, user *model.User, wr *CreateRequest) error {
+	if err := c.validate(wr); err != nil {
+		return fmt.Errorf("validate withdrawal from request fail: %w", err)
+	}
+
+	if err := c.withdrawal(ctx, user, wr); err != nil {
 		return fmt.Errorf("withdrawal from request fail: %w", err)
 	}
 
@@ -75,32 +90,45 @@ func (c *createAction) withdrawal(ctx context.Context, user *model.User, wr *Cre
 			return fmt.Errorf("create withdrawal fail: %w", err)
 		}
 
-		return nil
+		balancePayload, err := events.NewBalancePayload(current, withdrawn)
+		if err != nil {
+			return err
+		}
+
+		if err := c.app.Rep.Event.Create(ctx, user.ID, model.EventBalance, balancePayload); err != nil {
+			return fmt.Errorf("create balance event fail: %w", err)
+		}
+
+		payload, err := webhook.NewPointsPayload(model.WebhookEventPointsWithdrawn, user.ID, wr.Order, wr.Sum)
+		if err != nil {
+			return err
+		}
+
+		if err := c.app.Rep.Webhook.Enqueue(ctx, user.ID, model.WebhookEventPointsWithdrawn, payload); err != nil {
+			return fmt.Errorf("enqueue webhook fail: %w", err)
+		}
+
+		event := outbox.PointsWithdrawn{Order: wr.Order, Sum: wr.Sum, UserID: user.ID}
+		return outbox.Emit(ctx, c.app, model.OutboxPointsWithdrawn, event)
 	})
 
 	if err != nil {
 		return fmt.Errorf("withdrawal %w: %w", trm.ErrTransactionFail, err)
 	}
 
+	metrics.PointsWithdrawn.Add(wr.Sum)
 	return nil
 }
 
-func (c *createAction) validate(r *http.Request) (*CreateRequest, error) {
-	rOrder := CreateRequest{}
-	d := json.NewDecoder(r.Body)
-
-	if err := d.Decode(&rOrder); err != nil {
-		return nil, fmt.Errorf("decode json body fail: %w", err)
-	}
-
-	if err := alg.CheckLuhn(rOrder.Order); err != nil {
-		return nil, fmt.Errorf("luhn check fail: %w", err)
+func (c *createAction) validate(wr *CreateRequest) error {
+	if err := alg.CheckLuhn(wr.Order); err != nil {
+		return fmt.Errorf("luhn check fail: %w", err)
 	}
 
 	v := validator.New()
-	if err := v.Struct(rOrder); err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrValidationWithdrawal, err)
+	if err := v.Struct(wr); err != nil {
+		return fmt.Errorf("%w: %w", ErrValidationWithdrawal, err)
 	}
 
-	return &rOrder, nil
+	return nil
 }
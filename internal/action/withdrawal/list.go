@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/arefev/gophermart/internal/application"
 	"github.com/arefev/gophermart/internal/model"
@@ -22,22 +23,49 @@ func NewListAction(app *application.App) *listAction {
 	}
 }
 
-func (l *listAction) Handle(r *http.Request) ([]model.Withdrawal, error) {
+// Handle возвращает списания пользователя и курсор следующей страницы,
+// если в запросе переданы параметры пагинации.
+func (l *listAction) Handle(r *http.Request) ([]model.Withdrawal, string, error) {
 	user, err := service.NewUserService(l.app).Authorized(r.Context())
 	if err != nil {
-		return []model.Withdrawal{}, service.ErrUserNotAuthorized
+		return []model.Withdrawal{}, "", service.ErrUserNotAuthorized
+	}
+
+	return l.List(r.Context(), user, r.URL.Query())
+}
+
+// List возвращает списания пользователя по параметрам списка, используется HTTP и gRPC API.
+func (l *listAction) List(ctx context.Context, user *model.User, q url.Values) ([]model.Withdrawal, string, error) {
+	filter, err := service.ParseListFilter(q, false)
+	if err != nil {
+		return []model.Withdrawal{}, "", fmt.Errorf("withdrawal list parse query fail: %w", err)
 	}
 
 	var list []model.Withdrawal
-	err = l.app.TrManager.Do(r.Context(), func(ctx context.Context) error {
-		list = l.app.Rep.Order.GetWithdrawalsByUserID(ctx, user.ID)
+	err = l.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		if filter == nil {
+			list = l.app.Rep.Order.GetWithdrawalsByUserID(ctx, user.ID)
+			return nil
+		}
+
+		page := *filter
+		page.Limit++
+		list = l.app.Rep.Order.PageWithdrawalsByUserID(ctx, user.ID, page)
 
 		return nil
 	})
 
 	if err != nil {
-		return []model.Withdrawal{}, fmt.Errorf("withdrawal list transaction fail: %w", err)
+		return []model.Withdrawal{}, "", fmt.Errorf("withdrawal list transaction fail: %w", err)
+	}
+
+	if filter == nil {
+		return list, "", nil
 	}
 
-	return list, nil
+	list, next := service.NextPage(list, filter.Limit, func(w *model.Withdrawal) model.Cursor {
+		return model.Cursor{At: w.ProcessedAt, ID: w.ID}
+	})
+
+	return list, next, nil
 }
@@ -2,8 +2,12 @@ package application
 
 import (
 	"context"
+	"time"
 
 	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/events"
+	"github.com/arefev/gophermart/internal/health"
+	"github.com/arefev/gophermart/internal/logger"
 	"github.com/arefev/gophermart/internal/model"
 	"github.com/arefev/gophermart/internal/trm"
 	"go.uber.org/zap"
@@ -13,21 +17,97 @@ type UserRepo interface {
 	Exists(ctx context.Context, login string) bool
 	FindByLogin(ctx context.Context, login string) (*model.User, bool)
 	Create(ctx context.Context, login, password string) error
+	UpdatePassword(ctx context.Context, id int, password string) error
+	SetTOTPSecret(ctx context.Context, id int, secret string) error
+	EnableTOTP(ctx context.Context, id int, step int64) error
+	UseTOTPStep(ctx context.Context, id int, step int64) (bool, error)
+	TOTPFailed(ctx context.Context, id int, maxFailures int, lockout time.Duration) (bool, error)
+	ResetTOTPFailures(ctx context.Context, id int) error
+	SetBlocked(ctx context.Context, id int, blocked bool) error
+	Search(ctx context.Context, pattern string, limit int) []model.User
+}
+
+type RoleRepo interface {
+	Grant(ctx context.Context, userID int, role string) error
+	Revoke(ctx context.Context, userID int, role string) error
+}
+
+type APIKeyRepo interface {
+	Create(ctx context.Context, name, prefix, hash string, scopes []string) (int, error)
+	FindByPrefix(ctx context.Context, prefix string) (*model.APIKey, bool)
+	List(ctx context.Context) []model.APIKey
+	Revoke(ctx context.Context, id int) (bool, error)
+	TouchLastUsed(ctx context.Context, id int) error
+}
+
+type IdentityRepo interface {
+	FindLogin(ctx context.Context, issuer, subject string) (string, bool)
+	Link(ctx context.Context, userID int, issuer, subject string) error
+}
+
+type EventRepo interface {
+	Create(ctx context.Context, userID int, eventType string, payload string) error
+	After(ctx context.Context, userID int, id int64, limit int) []model.Event
+	Purge(ctx context.Context, retention time.Duration) error
+}
+
+type WebhookRepo interface {
+	Create(ctx context.Context, userID int, url, secret string, events []string) (int, error)
+	List(ctx context.Context) []model.Webhook
+	Deactivate(ctx context.Context, id int) (bool, error)
+	CancelPending(ctx context.Context, id int) error
+	Enqueue(ctx context.Context, userID int, eventType, payload string) error
+	Claim(ctx context.Context, limit int, lease time.Duration) []model.WebhookDelivery
+	Delivered(ctx context.Context, id int64, statusCode int) error
+	Failed(ctx context.Context, id int64, statusCode int, reason string, retryIn time.Duration, dead bool) error
+	Deliveries(ctx context.Context, webhookID int, limit int) []model.WebhookDelivery
+}
+
+type OutboxRepo interface {
+	Add(ctx context.Context, eventType, payload string) error
+	Pending(ctx context.Context, limit int) []model.OutboxMessage
+	MarkPublished(ctx context.Context, ids []int64) error
+	Purge(ctx context.Context, retention time.Duration) error
+}
+
+type RecoveryCodeRepo interface {
+	Replace(ctx context.Context, userID int, hashes []string) error
+	Use(ctx context.Context, userID int, hash string) (bool, error)
 }
 
 type OrderRepo interface {
 	FindByNumber(ctx context.Context, number string) (*model.Order, bool)
 	Create(ctx context.Context, userID int, status model.OrderStatus, number string) error
 	GetByUserID(ctx context.Context, userID int) []model.Order
+	PageByUserID(ctx context.Context, userID int, filter model.ListFilter) []model.Order
 	WithStatusNew(ctx context.Context) []model.Order
-	AccrualByID(ctx context.Context, sum float64, status model.OrderStatus, id int) error
+	AccrualByID(ctx context.Context, sum float64, status model.OrderStatus, id int) (bool, error)
+	CheckedByID(ctx context.Context, status model.OrderStatus, id int) (bool, error)
+	StatusByID(ctx context.Context, status model.OrderStatus, id int) error
+	StatusHistory(ctx context.Context, orderID int) []model.OrderStatusChange
 	CreateWithdrawal(ctx context.Context, userID int, number string, sum float64) error
 	GetWithdrawalsByUserID(ctx context.Context, userID int) []model.Withdrawal
+	PageWithdrawalsByUserID(ctx context.Context, userID int, filter model.ListFilter) []model.Withdrawal
+}
+
+type AuditRepo interface {
+	Create(ctx context.Context, record model.AuditRecord) error
 }
 
 type BalanceRepo interface {
 	FindByUserID(ctx context.Context, userID int) (*model.Balance, bool)
 	UpdateByID(ctx context.Context, id int, current, withdrawn float64) error
+	AddCurrent(ctx context.Context, userID int, amount float64) (*model.Balance, bool, error)
+}
+
+// AccrualChecker внеочередная проверка заказа в системе начислений.
+type AccrualChecker interface {
+	Recheck(ctx context.Context, order *model.Order) error
+}
+
+// BreachedList локальный список утекших паролей, загружается при старте.
+type BreachedList interface {
+	Contains(pwd string) (bool, error)
 }
 
 type TrManager interface {
@@ -39,10 +119,27 @@ type App struct {
 	TrManager TrManager
 	Log       *zap.Logger
 	Conf      *config.Config
+	Events    *events.Hub
+	Health    *health.Health
+	Accrual   AccrualChecker
+	Breached  BreachedList
+}
+
+// Logger логер запроса с request_id и user_id, если он есть в контексте, иначе общий.
+func (app *App) Logger(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, app.Log)
 }
 
 type Repository struct {
-	User    UserRepo
-	Order   OrderRepo
-	Balance BalanceRepo
+	User         UserRepo
+	Order        OrderRepo
+	Balance      BalanceRepo
+	RecoveryCode RecoveryCodeRepo
+	Role         RoleRepo
+	APIKey       APIKeyRepo
+	Identity     IdentityRepo
+	Event        EventRepo
+	Webhook      WebhookRepo
+	Outbox       OutboxRepo
+	Audit        AuditRepo
 }
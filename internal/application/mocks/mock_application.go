@@ -7,6 +7,7 @@ package mock_application
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	model "github.com/arefev/gophermart/internal/model"
 	trm "github.com/arefev/gophermart/internal/trm"
@@ -50,6 +51,20 @@ func (mr *MockUserRepoMockRecorder) Create(ctx, login, password interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserRepo)(nil).Create), ctx, login, password)
 }
 
+// EnableTOTP mocks base method.
+func (m *MockUserRepo) EnableTOTP(ctx context.Context, id int, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", ctx, id, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP.
+func (mr *MockUserRepoMockRecorder) EnableTOTP(ctx, id, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockUserRepo)(nil).EnableTOTP), ctx, id, step)
+}
+
 // Exists mocks base method.
 func (m *MockUserRepo) Exists(ctx context.Context, login string) bool {
 	m.ctrl.T.Helper()
@@ -79,6 +94,652 @@ func (mr *MockUserRepoMockRecorder) FindByLogin(ctx, login interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByLogin", reflect.TypeOf((*MockUserRepo)(nil).FindByLogin), ctx, login)
 }
 
+// ResetTOTPFailures mocks base method.
+func (m *MockUserRepo) ResetTOTPFailures(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetTOTPFailures", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetTOTPFailures indicates an expected call of ResetTOTPFailures.
+func (mr *MockUserRepoMockRecorder) ResetTOTPFailures(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetTOTPFailures", reflect.TypeOf((*MockUserRepo)(nil).ResetTOTPFailures), ctx, id)
+}
+
+// Search mocks base method.
+func (m *MockUserRepo) Search(ctx context.Context, pattern string, limit int) []model.User {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, pattern, limit)
+	ret0, _ := ret[0].([]model.User)
+	return ret0
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockUserRepoMockRecorder) Search(ctx, pattern, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockUserRepo)(nil).Search), ctx, pattern, limit)
+}
+
+// SetBlocked mocks base method.
+func (m *MockUserRepo) SetBlocked(ctx context.Context, id int, blocked bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBlocked", ctx, id, blocked)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBlocked indicates an expected call of SetBlocked.
+func (mr *MockUserRepoMockRecorder) SetBlocked(ctx, id, blocked interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBlocked", reflect.TypeOf((*MockUserRepo)(nil).SetBlocked), ctx, id, blocked)
+}
+
+// SetTOTPSecret mocks base method.
+func (m *MockUserRepo) SetTOTPSecret(ctx context.Context, id int, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTPSecret", ctx, id, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTOTPSecret indicates an expected call of SetTOTPSecret.
+func (mr *MockUserRepoMockRecorder) SetTOTPSecret(ctx, id, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTPSecret", reflect.TypeOf((*MockUserRepo)(nil).SetTOTPSecret), ctx, id, secret)
+}
+
+// TOTPFailed mocks base method.
+func (m *MockUserRepo) TOTPFailed(ctx context.Context, id, maxFailures int, lockout time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TOTPFailed", ctx, id, maxFailures, lockout)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TOTPFailed indicates an expected call of TOTPFailed.
+func (mr *MockUserRepoMockRecorder) TOTPFailed(ctx, id, maxFailures, lockout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TOTPFailed", reflect.TypeOf((*MockUserRepo)(nil).TOTPFailed), ctx, id, maxFailures, lockout)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockUserRepo) UpdatePassword(ctx context.Context, id int, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, id, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockUserRepoMockRecorder) UpdatePassword(ctx, id, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserRepo)(nil).UpdatePassword), ctx, id, password)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockUserRepo) UseTOTPStep(ctx context.Context, id int, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, id, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockUserRepoMockRecorder) UseTOTPStep(ctx, id, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockUserRepo)(nil).UseTOTPStep), ctx, id, step)
+}
+
+// MockRoleRepo is a mock of RoleRepo interface.
+type MockRoleRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoleRepoMockRecorder
+}
+
+// MockRoleRepoMockRecorder is the mock recorder for MockRoleRepo.
+type MockRoleRepoMockRecorder struct {
+	mock *MockRoleRepo
+}
+
+// NewMockRoleRepo creates a new mock instance.
+func NewMockRoleRepo(ctrl *gomock.Controller) *MockRoleRepo {
+	mock := &MockRoleRepo{ctrl: ctrl}
+	mock.recorder = &MockRoleRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRoleRepo) EXPECT() *MockRoleRepoMockRecorder {
+	return m.recorder
+}
+
+// Grant mocks base method.
+func (m *MockRoleRepo) Grant(ctx context.Context, userID int, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Grant", ctx, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Grant indicates an expected call of Grant.
+func (mr *MockRoleRepoMockRecorder) Grant(ctx, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Grant", reflect.TypeOf((*MockRoleRepo)(nil).Grant), ctx, userID, role)
+}
+
+// Revoke mocks base method.
+func (m *MockRoleRepo) Revoke(ctx context.Context, userID int, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockRoleRepoMockRecorder) Revoke(ctx, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockRoleRepo)(nil).Revoke), ctx, userID, role)
+}
+
+// MockAPIKeyRepo is a mock of APIKeyRepo interface.
+type MockAPIKeyRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyRepoMockRecorder
+}
+
+// MockAPIKeyRepoMockRecorder is the mock recorder for MockAPIKeyRepo.
+type MockAPIKeyRepoMockRecorder struct {
+	mock *MockAPIKeyRepo
+}
+
+// NewMockAPIKeyRepo creates a new mock instance.
+func NewMockAPIKeyRepo(ctrl *gomock.Controller) *MockAPIKeyRepo {
+	mock := &MockAPIKeyRepo{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyRepo) EXPECT() *MockAPIKeyRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAPIKeyRepo) Create(ctx context.Context, name, prefix, hash string, scopes []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, name, prefix, hash, scopes)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAPIKeyRepoMockRecorder) Create(ctx, name, prefix, hash, scopes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAPIKeyRepo)(nil).Create), ctx, name, prefix, hash, scopes)
+}
+
+// FindByPrefix mocks base method.
+func (m *MockAPIKeyRepo) FindByPrefix(ctx context.Context, prefix string) (*model.APIKey, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByPrefix", ctx, prefix)
+	ret0, _ := ret[0].(*model.APIKey)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// FindByPrefix indicates an expected call of FindByPrefix.
+func (mr *MockAPIKeyRepoMockRecorder) FindByPrefix(ctx, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPrefix", reflect.TypeOf((*MockAPIKeyRepo)(nil).FindByPrefix), ctx, prefix)
+}
+
+// List mocks base method.
+func (m *MockAPIKeyRepo) List(ctx context.Context) []model.APIKey {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]model.APIKey)
+	return ret0
+}
+
+// List indicates an expected call of List.
+func (mr *MockAPIKeyRepoMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAPIKeyRepo)(nil).List), ctx)
+}
+
+// Revoke mocks base method.
+func (m *MockAPIKeyRepo) Revoke(ctx context.Context, id int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockAPIKeyRepoMockRecorder) Revoke(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAPIKeyRepo)(nil).Revoke), ctx, id)
+}
+
+// TouchLastUsed mocks base method.
+func (m *MockAPIKeyRepo) TouchLastUsed(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchLastUsed", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchLastUsed indicates an expected call of TouchLastUsed.
+func (mr *MockAPIKeyRepoMockRecorder) TouchLastUsed(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchLastUsed", reflect.TypeOf((*MockAPIKeyRepo)(nil).TouchLastUsed), ctx, id)
+}
+
+// MockIdentityRepo is a mock of IdentityRepo interface.
+type MockIdentityRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdentityRepoMockRecorder
+}
+
+// MockIdentityRepoMockRecorder is the mock recorder for MockIdentityRepo.
+type MockIdentityRepoMockRecorder struct {
+	mock *MockIdentityRepo
+}
+
+// NewMockIdentityRepo creates a new mock instance.
+func NewMockIdentityRepo(ctrl *gomock.Controller) *MockIdentityRepo {
+	mock := &MockIdentityRepo{ctrl: ctrl}
+	mock.recorder = &MockIdentityRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdentityRepo) EXPECT() *MockIdentityRepoMockRecorder {
+	return m.recorder
+}
+
+// FindLogin mocks base method.
+func (m *MockIdentityRepo) FindLogin(ctx context.Context, issuer, subject string) (string, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLogin", ctx, issuer, subject)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// FindLogin indicates an expected call of FindLogin.
+func (mr *MockIdentityRepoMockRecorder) FindLogin(ctx, issuer, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLogin", reflect.TypeOf((*MockIdentityRepo)(nil).FindLogin), ctx, issuer, subject)
+}
+
+// Link mocks base method.
+func (m *MockIdentityRepo) Link(ctx context.Context, userID int, issuer, subject string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Link", ctx, userID, issuer, subject)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Link indicates an expected call of Link.
+func (mr *MockIdentityRepoMockRecorder) Link(ctx, userID, issuer, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Link", reflect.TypeOf((*MockIdentityRepo)(nil).Link), ctx, userID, issuer, subject)
+}
+
+// MockEventRepo is a mock of EventRepo interface.
+type MockEventRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventRepoMockRecorder
+}
+
+// MockEventRepoMockRecorder is the mock recorder for MockEventRepo.
+type MockEventRepoMockRecorder struct {
+	mock *MockEventRepo
+}
+
+// NewMockEventRepo creates a new mock instance.
+func NewMockEventRepo(ctrl *gomock.Controller) *MockEventRepo {
+	mock := &MockEventRepo{ctrl: ctrl}
+	mock.recorder = &MockEventRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventRepo) EXPECT() *MockEventRepoMockRecorder {
+	return m.recorder
+}
+
+// After mocks base method.
+func (m *MockEventRepo) After(ctx context.Context, userID int, id int64, limit int) []model.Event {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "After", ctx, userID, id, limit)
+	ret0, _ := ret[0].([]model.Event)
+	return ret0
+}
+
+// After indicates an expected call of After.
+func (mr *MockEventRepoMockRecorder) After(ctx, userID, id, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "After", reflect.TypeOf((*MockEventRepo)(nil).After), ctx, userID, id, limit)
+}
+
+// Create mocks base method.
+func (m *MockEventRepo) Create(ctx context.Context, userID int, eventType, payload string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userID, eventType, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockEventRepoMockRecorder) Create(ctx, userID, eventType, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockEventRepo)(nil).Create), ctx, userID, eventType, payload)
+}
+
+// Purge mocks base method.
+func (m *MockEventRepo) Purge(ctx context.Context, retention time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, retention)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockEventRepoMockRecorder) Purge(ctx, retention interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockEventRepo)(nil).Purge), ctx, retention)
+}
+
+// MockWebhookRepo is a mock of WebhookRepo interface.
+type MockWebhookRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepoMockRecorder
+}
+
+// MockWebhookRepoMockRecorder is the mock recorder for MockWebhookRepo.
+type MockWebhookRepoMockRecorder struct {
+	mock *MockWebhookRepo
+}
+
+// NewMockWebhookRepo creates a new mock instance.
+func NewMockWebhookRepo(ctrl *gomock.Controller) *MockWebhookRepo {
+	mock := &MockWebhookRepo{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepo) EXPECT() *MockWebhookRepoMockRecorder {
+	return m.recorder
+}
+
+// CancelPending mocks base method.
+func (m *MockWebhookRepo) CancelPending(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelPending", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelPending indicates an expected call of CancelPending.
+func (mr *MockWebhookRepoMockRecorder) CancelPending(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelPending", reflect.TypeOf((*MockWebhookRepo)(nil).CancelPending), ctx, id)
+}
+
+// Claim mocks base method.
+func (m *MockWebhookRepo) Claim(ctx context.Context, limit int, lease time.Duration) []model.WebhookDelivery {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", ctx, limit, lease)
+	ret0, _ := ret[0].([]model.WebhookDelivery)
+	return ret0
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *MockWebhookRepoMockRecorder) Claim(ctx, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockWebhookRepo)(nil).Claim), ctx, limit, lease)
+}
+
+// Create mocks base method.
+func (m *MockWebhookRepo) Create(ctx context.Context, userID int, url, secret string, events []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userID, url, secret, events)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWebhookRepoMockRecorder) Create(ctx, userID, url, secret, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWebhookRepo)(nil).Create), ctx, userID, url, secret, events)
+}
+
+// Deactivate mocks base method.
+func (m *MockWebhookRepo) Deactivate(ctx context.Context, id int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deactivate", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deactivate indicates an expected call of Deactivate.
+func (mr *MockWebhookRepoMockRecorder) Deactivate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deactivate", reflect.TypeOf((*MockWebhookRepo)(nil).Deactivate), ctx, id)
+}
+
+// Delivered mocks base method.
+func (m *MockWebhookRepo) Delivered(ctx context.Context, id int64, statusCode int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delivered", ctx, id, statusCode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delivered indicates an expected call of Delivered.
+func (mr *MockWebhookRepoMockRecorder) Delivered(ctx, id, statusCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delivered", reflect.TypeOf((*MockWebhookRepo)(nil).Delivered), ctx, id, statusCode)
+}
+
+// Deliveries mocks base method.
+func (m *MockWebhookRepo) Deliveries(ctx context.Context, webhookID, limit int) []model.WebhookDelivery {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deliveries", ctx, webhookID, limit)
+	ret0, _ := ret[0].([]model.WebhookDelivery)
+	return ret0
+}
+
+// Deliveries indicates an expected call of Deliveries.
+func (mr *MockWebhookRepoMockRecorder) Deliveries(ctx, webhookID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deliveries", reflect.TypeOf((*MockWebhookRepo)(nil).Deliveries), ctx, webhookID, limit)
+}
+
+// Enqueue mocks base method.
+func (m *MockWebhookRepo) Enqueue(ctx context.Context, userID int, eventType, payload string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", ctx, userID, eventType, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockWebhookRepoMockRecorder) Enqueue(ctx, userID, eventType, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockWebhookRepo)(nil).Enqueue), ctx, userID, eventType, payload)
+}
+
+// Failed mocks base method.
+func (m *MockWebhookRepo) Failed(ctx context.Context, id int64, statusCode int, reason string, retryIn time.Duration, dead bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Failed", ctx, id, statusCode, reason, retryIn, dead)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Failed indicates an expected call of Failed.
+func (mr *MockWebhookRepoMockRecorder) Failed(ctx, id, statusCode, reason, retryIn, dead interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Failed", reflect.TypeOf((*MockWebhookRepo)(nil).Failed), ctx, id, statusCode, reason, retryIn, dead)
+}
+
+// List mocks base method.
+func (m *MockWebhookRepo) List(ctx context.Context) []model.Webhook {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]model.Webhook)
+	return ret0
+}
+
+// List indicates an expected call of List.
+func (mr *MockWebhookRepoMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockWebhookRepo)(nil).List), ctx)
+}
+
+// MockOutboxRepo is a mock of OutboxRepo interface.
+type MockOutboxRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepoMockRecorder
+}
+
+// MockOutboxRepoMockRecorder is the mock recorder for MockOutboxRepo.
+type MockOutboxRepoMockRecorder struct {
+	mock *MockOutboxRepo
+}
+
+// NewMockOutboxRepo creates a new mock instance.
+func NewMockOutboxRepo(ctrl *gomock.Controller) *MockOutboxRepo {
+	mock := &MockOutboxRepo{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepo) EXPECT() *MockOutboxRepoMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockOutboxRepo) Add(ctx context.Context, eventType, payload string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, eventType, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockOutboxRepoMockRecorder) Add(ctx, eventType, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockOutboxRepo)(nil).Add), ctx, eventType, payload)
+}
+
+// MarkPublished mocks base method.
+func (m *MockOutboxRepo) MarkPublished(ctx context.Context, ids []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPublished", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkPublished indicates an expected call of MarkPublished.
+func (mr *MockOutboxRepoMockRecorder) MarkPublished(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPublished", reflect.TypeOf((*MockOutboxRepo)(nil).MarkPublished), ctx, ids)
+}
+
+// Pending mocks base method.
+func (m *MockOutboxRepo) Pending(ctx context.Context, limit int) []model.OutboxMessage {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pending", ctx, limit)
+	ret0, _ := ret[0].([]model.OutboxMessage)
+	return ret0
+}
+
+// Pending indicates an expected call of Pending.
+func (mr *MockOutboxRepoMockRecorder) Pending(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pending", reflect.TypeOf((*MockOutboxRepo)(nil).Pending), ctx, limit)
+}
+
+// Purge mocks base method.
+func (m *MockOutboxRepo) Purge(ctx context.Context, retention time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, retention)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockOutboxRepoMockRecorder) Purge(ctx, retention interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockOutboxRepo)(nil).Purge), ctx, retention)
+}
+
+// MockRecoveryCodeRepo is a mock of RecoveryCodeRepo interface.
+type MockRecoveryCodeRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecoveryCodeRepoMockRecorder
+}
+
+// MockRecoveryCodeRepoMockRecorder is the mock recorder for MockRecoveryCodeRepo.
+type MockRecoveryCodeRepoMockRecorder struct {
+	mock *MockRecoveryCodeRepo
+}
+
+// NewMockRecoveryCodeRepo creates a new mock instance.
+func NewMockRecoveryCodeRepo(ctrl *gomock.Controller) *MockRecoveryCodeRepo {
+	mock := &MockRecoveryCodeRepo{ctrl: ctrl}
+	mock.recorder = &MockRecoveryCodeRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRecoveryCodeRepo) EXPECT() *MockRecoveryCodeRepoMockRecorder {
+	return m.recorder
+}
+
+// Replace mocks base method.
+func (m *MockRecoveryCodeRepo) Replace(ctx context.Context, userID int, hashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replace", ctx, userID, hashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Replace indicates an expected call of Replace.
+func (mr *MockRecoveryCodeRepoMockRecorder) Replace(ctx, userID, hashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replace", reflect.TypeOf((*MockRecoveryCodeRepo)(nil).Replace), ctx, userID, hashes)
+}
+
+// Use mocks base method.
+func (m *MockRecoveryCodeRepo) Use(ctx context.Context, userID int, hash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Use", ctx, userID, hash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Use indicates an expected call of Use.
+func (mr *MockRecoveryCodeRepoMockRecorder) Use(ctx, userID, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Use", reflect.TypeOf((*MockRecoveryCodeRepo)(nil).Use), ctx, userID, hash)
+}
+
 // MockOrderRepo is a mock of OrderRepo interface.
 type MockOrderRepo struct {
 	ctrl     *gomock.Controller
@@ -103,11 +764,12 @@ func (m *MockOrderRepo) EXPECT() *MockOrderRepoMockRecorder {
 }
 
 // AccrualByID mocks base method.
-func (m *MockOrderRepo) AccrualByID(ctx context.Context, sum float64, status model.OrderStatus, id int) error {
+func (m *MockOrderRepo) AccrualByID(ctx context.Context, sum float64, status model.OrderStatus, id int) (bool, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "AccrualByID", ctx, sum, status, id)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // AccrualByID indicates an expected call of AccrualByID.
@@ -116,6 +778,21 @@ func (mr *MockOrderRepoMockRecorder) AccrualByID(ctx, sum, status, id interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccrualByID", reflect.TypeOf((*MockOrderRepo)(nil).AccrualByID), ctx, sum, status, id)
 }
 
+// CheckedByID mocks base method.
+func (m *MockOrderRepo) CheckedByID(ctx context.Context, status model.OrderStatus, id int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckedByID", ctx, status, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckedByID indicates an expected call of CheckedByID.
+func (mr *MockOrderRepoMockRecorder) CheckedByID(ctx, status, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckedByID", reflect.TypeOf((*MockOrderRepo)(nil).CheckedByID), ctx, status, id)
+}
+
 // Create mocks base method.
 func (m *MockOrderRepo) Create(ctx context.Context, userID int, status model.OrderStatus, number string) error {
 	m.ctrl.T.Helper()
@@ -187,6 +864,62 @@ func (mr *MockOrderRepoMockRecorder) GetWithdrawalsByUserID(ctx, userID interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalsByUserID", reflect.TypeOf((*MockOrderRepo)(nil).GetWithdrawalsByUserID), ctx, userID)
 }
 
+// PageByUserID mocks base method.
+func (m *MockOrderRepo) PageByUserID(ctx context.Context, userID int, filter model.ListFilter) []model.Order {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PageByUserID", ctx, userID, filter)
+	ret0, _ := ret[0].([]model.Order)
+	return ret0
+}
+
+// PageByUserID indicates an expected call of PageByUserID.
+func (mr *MockOrderRepoMockRecorder) PageByUserID(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PageByUserID", reflect.TypeOf((*MockOrderRepo)(nil).PageByUserID), ctx, userID, filter)
+}
+
+// PageWithdrawalsByUserID mocks base method.
+func (m *MockOrderRepo) PageWithdrawalsByUserID(ctx context.Context, userID int, filter model.ListFilter) []model.Withdrawal {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PageWithdrawalsByUserID", ctx, userID, filter)
+	ret0, _ := ret[0].([]model.Withdrawal)
+	return ret0
+}
+
+// PageWithdrawalsByUserID indicates an expected call of PageWithdrawalsByUserID.
+func (mr *MockOrderRepoMockRecorder) PageWithdrawalsByUserID(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PageWithdrawalsByUserID", reflect.TypeOf((*MockOrderRepo)(nil).PageWithdrawalsByUserID), ctx, userID, filter)
+}
+
+// StatusByID mocks base method.
+func (m *MockOrderRepo) StatusByID(ctx context.Context, status model.OrderStatus, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatusByID", ctx, status, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StatusByID indicates an expected call of StatusByID.
+func (mr *MockOrderRepoMockRecorder) StatusByID(ctx, status, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatusByID", reflect.TypeOf((*MockOrderRepo)(nil).StatusByID), ctx, status, id)
+}
+
+// StatusHistory mocks base method.
+func (m *MockOrderRepo) StatusHistory(ctx context.Context, orderID int) []model.OrderStatusChange {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatusHistory", ctx, orderID)
+	ret0, _ := ret[0].([]model.OrderStatusChange)
+	return ret0
+}
+
+// StatusHistory indicates an expected call of StatusHistory.
+func (mr *MockOrderRepoMockRecorder) StatusHistory(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatusHistory", reflect.TypeOf((*MockOrderRepo)(nil).StatusHistory), ctx, orderID)
+}
+
 // WithStatusNew mocks base method.
 func (m *MockOrderRepo) WithStatusNew(ctx context.Context) []model.Order {
 	m.ctrl.T.Helper()
@@ -201,6 +934,43 @@ func (mr *MockOrderRepoMockRecorder) WithStatusNew(ctx interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithStatusNew", reflect.TypeOf((*MockOrderRepo)(nil).WithStatusNew), ctx)
 }
 
+// MockAuditRepo is a mock of AuditRepo interface.
+type MockAuditRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepoMockRecorder
+}
+
+// MockAuditRepoMockRecorder is the mock recorder for MockAuditRepo.
+type MockAuditRepoMockRecorder struct {
+	mock *MockAuditRepo
+}
+
+// NewMockAuditRepo creates a new mock instance.
+func NewMockAuditRepo(ctrl *gomock.Controller) *MockAuditRepo {
+	mock := &MockAuditRepo{ctrl: ctrl}
+	mock.recorder = &MockAuditRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepo) EXPECT() *MockAuditRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAuditRepo) Create(ctx context.Context, record model.AuditRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAuditRepoMockRecorder) Create(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAuditRepo)(nil).Create), ctx, record)
+}
+
 // MockBalanceRepo is a mock of BalanceRepo interface.
 type MockBalanceRepo struct {
 	ctrl     *gomock.Controller
@@ -224,6 +994,22 @@ func (m *MockBalanceRepo) EXPECT() *MockBalanceRepoMockRecorder {
 	return m.recorder
 }
 
+// AddCurrent mocks base method.
+func (m *MockBalanceRepo) AddCurrent(ctx context.Context, userID int, amount float64) (*model.Balance, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCurrent", ctx, userID, amount)
+	ret0, _ := ret[0].(*model.Balance)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AddCurrent indicates an expected call of AddCurrent.
+func (mr *MockBalanceRepoMockRecorder) AddCurrent(ctx, userID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCurrent", reflect.TypeOf((*MockBalanceRepo)(nil).AddCurrent), ctx, userID, amount)
+}
+
 // FindByUserID mocks base method.
 func (m *MockBalanceRepo) FindByUserID(ctx context.Context, userID int) (*model.Balance, bool) {
 	m.ctrl.T.Helper()
@@ -253,6 +1039,81 @@ func (mr *MockBalanceRepoMockRecorder) UpdateByID(ctx, id, current, withdrawn in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByID", reflect.TypeOf((*MockBalanceRepo)(nil).UpdateByID), ctx, id, current, withdrawn)
 }
 
+// MockAccrualChecker is a mock of AccrualChecker interface.
+type MockAccrualChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccrualCheckerMockRecorder
+}
+
+// MockAccrualCheckerMockRecorder is the mock recorder for MockAccrualChecker.
+type MockAccrualCheckerMockRecorder struct {
+	mock *MockAccrualChecker
+}
+
+// NewMockAccrualChecker creates a new mock instance.
+func NewMockAccrualChecker(ctrl *gomock.Controller) *MockAccrualChecker {
+	mock := &MockAccrualChecker{ctrl: ctrl}
+	mock.recorder = &MockAccrualCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccrualChecker) EXPECT() *MockAccrualCheckerMockRecorder {
+	return m.recorder
+}
+
+// Recheck mocks base method.
+func (m *MockAccrualChecker) Recheck(ctx context.Context, order *model.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recheck", ctx, order)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Recheck indicates an expected call of Recheck.
+func (mr *MockAccrualCheckerMockRecorder) Recheck(ctx, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recheck", reflect.TypeOf((*MockAccrualChecker)(nil).Recheck), ctx, order)
+}
+
+// MockBreachedList is a mock of BreachedList interface.
+type MockBreachedList struct {
+	ctrl     *gomock.Controller
+	recorder *MockBreachedListMockRecorder
+}
+
+// MockBreachedListMockRecorder is the mock recorder for MockBreachedList.
+type MockBreachedListMockRecorder struct {
+	mock *MockBreachedList
+}
+
+// NewMockBreachedList creates a new mock instance.
+func NewMockBreachedList(ctrl *gomock.Controller) *MockBreachedList {
+	mock := &MockBreachedList{ctrl: ctrl}
+	mock.recorder = &MockBreachedListMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBreachedList) EXPECT() *MockBreachedListMockRecorder {
+	return m.recorder
+}
+
+// Contains mocks base method.
+func (m *MockBreachedList) Contains(pwd string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Contains", pwd)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Contains indicates an expected call of Contains.
+func (mr *MockBreachedListMockRecorder) Contains(pwd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Contains", reflect.TypeOf((*MockBreachedList)(nil).Contains), pwd)
+}
+
 // MockTrManager is a mock of TrManager interface.
 type MockTrManager struct {
 	ctrl     *gomock.Controller
@@ -4,33 +4,139 @@ import (
 	"flag"
 	"fmt"
 
+	"github.com/arefev/gophermart/internal/service/password"
 	"github.com/caarlos0/env"
 )
 
 const (
-	address        string = "localhost:8081"
-	logLevel       string = "info"
-	databaseDSN    string = ""
-	tokenSecret    string = "123"
-	accrualAddress string = "localhost:8082"
-	tokenDuration  int    = 60
-	pollInterval   int    = 2
-	rateLimit      int    = 10
+	address            string = "localhost:8081"
+	logLevel           string = "info"
+	databaseDSN        string = ""
+	tokenSecret        string = "123"
+	accrualAddress     string = "localhost:8082"
+	pwdAlgorithm       string = password.AlgorithmArgon2id
+	pwdBreached        string = ""
+	totpIssuer         string = "Gophermart"
+	oidcIssuer         string = ""
+	oidcClientID       string = ""
+	oidcSecret         string = ""
+	oidcRedirect       string = ""
+	outboxPublisher    string = "none"
+	outboxFile         string = "outbox.jsonl"
+	grpcAddress        string = ""
+	adminAddress       string = "localhost:9091"
+	traceExporter      string = "none"
+	traceEndpoint      string = ""
+	configFile         string = ""
+	tlsCert            string = ""
+	tlsKey             string = ""
+	tlsMinVersion      string = "1.2"
+	tlsCiphers         string = ""
+	adminClientCA      string = ""
+	tokenDuration      int    = 60
+	pollInterval       int    = 2
+	rateLimit          int    = 10
+	bcryptCost         int    = 10
+	argon2Memory       int    = int(password.Argon2idMemory)
+	argon2Time         int    = int(password.Argon2idIterations)
+	argon2Threads      int    = int(password.Argon2idParallelism)
+	pwdMinLength       int    = 8
+	pwdMaxLength       int    = 64
+	pwdMinClasses      int    = 3
+	twoFactorTTL       int    = 5
+	twoFactorAttempts  int    = 5
+	twoFactorLockout   int    = 15
+	orderBatchMax      int    = 100
+	webhookInterval    int    = 5
+	webhookMaxAttempts int    = 8
+	outboxInterval     int    = 1
+	outboxRetention    int    = 168
+	eventsRetention    int    = 168
+	healthWorkerMaxAge int    = 120
+	shutdownTimeout    int    = 30
+	readHeaderTimeout  int    = 5
+	readTimeout        int    = 30
+	writeTimeout       int    = 0
+	idleTimeout        int    = 120
+	tlsReloadInterval  int    = 10
+	openapiValidate    bool   = false
+	healthAccrual      bool   = false
+	devMode            bool   = false
+	http2              bool   = true
+	autoMigrate        bool   = true
+	oidcLinkExisting   bool   = false
 )
 
 type Config struct {
-	TokenSecret    string `env:"TOKEN_SECRET"`
-	Address        string `env:"RUN_ADDRESS"`
-	LogLevel       string `env:"LOG_LEVEL"`
-	DatabaseDSN    string `env:"DATABASE_URI"`
-	AccrualAddress string `env:"ACCRUAL_SYSTEM_ADDRESS"`
-	TokenDuration  int    `env:"TOKEN_DURATION"`
-	PollInterval   int    `env:"POLL_INTERVAL"`
-	RateLimit      int    `env:"RATE_LIMIT"`
+	TokenSecret        string `env:"TOKEN_SECRET"`
+	Address            string `env:"RUN_ADDRESS"`
+	LogLevel           string `env:"LOG_LEVEL"`
+	DatabaseDSN        string `env:"DATABASE_URI"`
+	AccrualAddress     string `env:"ACCRUAL_SYSTEM_ADDRESS"`
+	PwdAlgorithm       string `env:"PASSWORD_ALGORITHM"`
+	PwdBreached        string `env:"PASSWORD_BREACHED_PATH"`
+	TOTPIssuer         string `env:"TOTP_ISSUER"`
+	OIDCIssuer         string `env:"OIDC_ISSUER"`
+	OIDCClientID       string `env:"OIDC_CLIENT_ID"`
+	OIDCSecret         string `env:"OIDC_CLIENT_SECRET"`
+	OIDCRedirect       string `env:"OIDC_REDIRECT_URL"`
+	OutboxPublisher    string `env:"OUTBOX_PUBLISHER"`
+	OutboxFile         string `env:"OUTBOX_FILE"`
+	GRPCAddress        string `env:"GRPC_ADDRESS"`
+	AdminAddress       string `env:"ADMIN_ADDRESS"`
+	TraceExporter      string `env:"TRACE_EXPORTER"`
+	TraceEndpoint      string `env:"TRACE_OTLP_ENDPOINT"`
+	ConfigFile         string `env:"CONFIG_FILE"`
+	TLSCert            string `env:"TLS_CERT_FILE"`
+	TLSKey             string `env:"TLS_KEY_FILE"`
+	TLSMinVersion      string `env:"TLS_MIN_VERSION"`
+	TLSCiphers         string `env:"TLS_CIPHER_SUITES"`
+	AdminClientCA      string `env:"ADMIN_CLIENT_CA_FILE"`
+	TokenDuration      int    `env:"TOKEN_DURATION"`
+	PollInterval       int    `env:"POLL_INTERVAL"`
+	RateLimit          int    `env:"RATE_LIMIT"`
+	BcryptCost         int    `env:"BCRYPT_COST"`
+	Argon2Memory       int    `env:"ARGON2_MEMORY"`
+	Argon2Time         int    `env:"ARGON2_TIME"`
+	Argon2Threads      int    `env:"ARGON2_THREADS"`
+	PwdMinLength       int    `env:"PASSWORD_MIN_LENGTH"`
+	PwdMaxLength       int    `env:"PASSWORD_MAX_LENGTH"`
+	PwdMinClasses      int    `env:"PASSWORD_MIN_CLASSES"`
+	TwoFactorTTL       int    `env:"TWO_FACTOR_TOKEN_DURATION"`
+	TwoFactorAttempts  int    `env:"TWO_FACTOR_MAX_ATTEMPTS"`
+	TwoFactorLockout   int    `env:"TWO_FACTOR_LOCKOUT"`
+	OrderBatchMax      int    `env:"ORDER_BATCH_MAX_SIZE"`
+	WebhookInterval    int    `env:"WEBHOOK_INTERVAL"`
+	WebhookMaxAttempts int    `env:"WEBHOOK_MAX_ATTEMPTS"`
+	OutboxInterval     int    `env:"OUTBOX_INTERVAL"`
+	OutboxRetention    int    `env:"OUTBOX_RETENTION"`
+	EventsRetention    int    `env:"EVENTS_RETENTION"`
+	HealthWorkerMaxAge int    `env:"HEALTH_WORKER_MAX_AGE"`
+	ShutdownTimeout    int    `env:"SHUTDOWN_TIMEOUT"`
+	ReadHeaderTimeout  int    `env:"READ_HEADER_TIMEOUT"`
+	ReadTimeout        int    `env:"READ_TIMEOUT"`
+	WriteTimeout       int    `env:"WRITE_TIMEOUT"`
+	IdleTimeout        int    `env:"IDLE_TIMEOUT"`
+	TLSReloadInterval  int    `env:"TLS_RELOAD_INTERVAL"`
+	OpenAPIValidate    bool   `env:"OPENAPI_VALIDATE"`
+	HealthAccrual      bool   `env:"HEALTH_CHECK_ACCRUAL"`
+	DevMode            bool   `env:"DEV_MODE"`
+	HTTP2              bool   `env:"HTTP2_ENABLED"`
+	AutoMigrate        bool   `env:"AUTO_MIGRATE"`
+	OIDCLinkExisting   bool   `env:"OIDC_LINK_EXISTING"`
+	PrintConfig        bool
 }
 
+// NewConfig собирает конфигурацию. Порядок приоритета, от низшего к высшему:
+// значения по умолчанию, файл конфигурации (-config или CONFIG_FILE), флаги, переменные окружения.
 func NewConfig(params []string) (Config, error) {
-	cnf := Config{}
+	cnf := defaults()
+
+	if path := configPath(params); path != "" {
+		if err := cnf.loadFile(path); err != nil {
+			return Config{}, err
+		}
+	}
 
 	if err := cnf.initFlags(params); err != nil {
 		return Config{}, err
@@ -43,16 +149,126 @@ func NewConfig(params []string) (Config, error) {
 	return cnf, nil
 }
 
+func defaults() Config {
+	return Config{
+		TokenSecret:        tokenSecret,
+		Address:            address,
+		LogLevel:           logLevel,
+		DatabaseDSN:        databaseDSN,
+		AccrualAddress:     accrualAddress,
+		PwdAlgorithm:       pwdAlgorithm,
+		PwdBreached:        pwdBreached,
+		TOTPIssuer:         totpIssuer,
+		OIDCIssuer:         oidcIssuer,
+		OIDCClientID:       oidcClientID,
+		OIDCSecret:         oidcSecret,
+		OIDCRedirect:       oidcRedirect,
+		OutboxPublisher:    outboxPublisher,
+		OutboxFile:         outboxFile,
+		GRPCAddress:        grpcAddress,
+		AdminAddress:       adminAddress,
+		TraceExporter:      traceExporter,
+		TraceEndpoint:      traceEndpoint,
+		ConfigFile:         configFile,
+		TLSCert:            tlsCert,
+		TLSKey:             tlsKey,
+		TLSMinVersion:      tlsMinVersion,
+		TLSCiphers:         tlsCiphers,
+		AdminClientCA:      adminClientCA,
+		TokenDuration:      tokenDuration,
+		PollInterval:       pollInterval,
+		RateLimit:          rateLimit,
+		BcryptCost:         bcryptCost,
+		Argon2Memory:       argon2Memory,
+		Argon2Time:         argon2Time,
+		Argon2Threads:      argon2Threads,
+		PwdMinLength:       pwdMinLength,
+		PwdMaxLength:       pwdMaxLength,
+		PwdMinClasses:      pwdMinClasses,
+		TwoFactorTTL:       twoFactorTTL,
+		TwoFactorAttempts:  twoFactorAttempts,
+		TwoFactorLockout:   twoFactorLockout,
+		OrderBatchMax:      orderBatchMax,
+		WebhookInterval:    webhookInterval,
+		WebhookMaxAttempts: webhookMaxAttempts,
+		OutboxInterval:     outboxInterval,
+		OutboxRetention:    outboxRetention,
+		EventsRetention:    eventsRetention,
+		HealthWorkerMaxAge: healthWorkerMaxAge,
+		ShutdownTimeout:    shutdownTimeout,
+		ReadHeaderTimeout:  readHeaderTimeout,
+		ReadTimeout:        readTimeout,
+		WriteTimeout:       writeTimeout,
+		IdleTimeout:        idleTimeout,
+		TLSReloadInterval:  tlsReloadInterval,
+		OpenAPIValidate:    openapiValidate,
+		HealthAccrual:      healthAccrual,
+		DevMode:            devMode,
+		HTTP2:              http2,
+		AutoMigrate:        autoMigrate,
+		OIDCLinkExisting:   oidcLinkExisting,
+	}
+}
+
 func (cnf *Config) initFlags(params []string) error {
 	f := flag.NewFlagSet("main", flag.ExitOnError)
-	f.StringVar(&cnf.Address, "a", address, "address and port to run server")
-	f.StringVar(&cnf.LogLevel, "l", logLevel, "log level")
-	f.StringVar(&cnf.DatabaseDSN, "d", databaseDSN, "db connection string")
-	f.StringVar(&cnf.TokenSecret, "s", tokenSecret, "token secret")
-	f.StringVar(&cnf.AccrualAddress, "r", accrualAddress, "address and port accrual service")
-	f.IntVar(&cnf.TokenDuration, "t", tokenDuration, "token lifetime duration in minutes")
-	f.IntVar(&cnf.PollInterval, "i", pollInterval, "status poll interval in seconds")
-	f.IntVar(&cnf.RateLimit, "rate-limit", rateLimit, "rate limit in seconds")
+	f.StringVar(&cnf.Address, "a", cnf.Address, "address and port to run server")
+	f.StringVar(&cnf.GRPCAddress, "g", cnf.GRPCAddress, "address and port to run grpc server, disabled if empty")
+	f.StringVar(&cnf.AdminAddress, "admin-address", cnf.AdminAddress, "admin listener address, empty disables it")
+	f.StringVar(&cnf.LogLevel, "l", cnf.LogLevel, "log level")
+	f.StringVar(&cnf.DatabaseDSN, "d", cnf.DatabaseDSN, "db connection string")
+	f.StringVar(&cnf.TokenSecret, "s", cnf.TokenSecret, "token secret")
+	f.StringVar(&cnf.AccrualAddress, "r", cnf.AccrualAddress, "address and port accrual service")
+	f.IntVar(&cnf.TokenDuration, "t", cnf.TokenDuration, "token lifetime duration in minutes")
+	f.IntVar(&cnf.PollInterval, "i", cnf.PollInterval, "status poll interval in seconds")
+	f.IntVar(&cnf.RateLimit, "rate-limit", cnf.RateLimit, "rate limit in seconds")
+	f.StringVar(&cnf.PwdAlgorithm, "pwd-algorithm", cnf.PwdAlgorithm, "password hash algorithm: argon2id or bcrypt")
+	f.IntVar(&cnf.BcryptCost, "bcrypt-cost", cnf.BcryptCost, "bcrypt cost")
+	f.IntVar(&cnf.Argon2Memory, "argon2-memory", cnf.Argon2Memory, "argon2id memory in KiB")
+	f.IntVar(&cnf.Argon2Time, "argon2-time", cnf.Argon2Time, "argon2id iterations")
+	f.IntVar(&cnf.Argon2Threads, "argon2-threads", cnf.Argon2Threads, "argon2id parallelism")
+	f.IntVar(&cnf.PwdMinLength, "pwd-min-length", cnf.PwdMinLength, "password min length")
+	f.IntVar(&cnf.PwdMaxLength, "pwd-max-length", cnf.PwdMaxLength, "password max length")
+	f.IntVar(&cnf.PwdMinClasses, "pwd-min-classes", cnf.PwdMinClasses, "password min character classes count")
+	f.StringVar(&cnf.PwdBreached, "pwd-breached", cnf.PwdBreached, "breached passwords file or k-anonymity prefix dir")
+	f.StringVar(&cnf.TOTPIssuer, "totp-issuer", cnf.TOTPIssuer, "issuer name in totp otpauth uri")
+	f.IntVar(&cnf.TwoFactorTTL, "2fa-duration", cnf.TwoFactorTTL, "two factor login step token lifetime in minutes")
+	f.IntVar(&cnf.TwoFactorAttempts, "2fa-max-attempts", cnf.TwoFactorAttempts, "wrong 2fa codes before lockout")
+	f.IntVar(&cnf.TwoFactorLockout, "2fa-lockout", cnf.TwoFactorLockout, "2fa lockout after max wrong codes in minutes")
+	f.IntVar(&cnf.OrderBatchMax, "order-batch-max", cnf.OrderBatchMax, "max order numbers in one batch upload")
+	f.StringVar(&cnf.OIDCIssuer, "oidc-issuer", cnf.OIDCIssuer, "openid connect issuer url, empty disables sso login")
+	f.StringVar(&cnf.OIDCClientID, "oidc-client-id", cnf.OIDCClientID, "openid connect client id")
+	f.StringVar(&cnf.OIDCSecret, "oidc-client-secret", cnf.OIDCSecret, "openid connect client secret")
+	f.StringVar(&cnf.OIDCRedirect, "oidc-redirect-url", cnf.OIDCRedirect, "openid connect callback url")
+	f.BoolVar(&cnf.OIDCLinkExisting, "oidc-link-existing", cnf.OIDCLinkExisting, "link sso to local user by email login")
+	f.IntVar(&cnf.WebhookInterval, "webhook-interval", cnf.WebhookInterval, "webhook deliveries poll interval in seconds")
+	f.IntVar(&cnf.WebhookMaxAttempts, "webhook-max-attempts", cnf.WebhookMaxAttempts, "webhook attempts before dead")
+	f.StringVar(&cnf.OutboxPublisher, "outbox-publisher", cnf.OutboxPublisher, "domain events: none, stdout or file")
+	f.StringVar(&cnf.OutboxFile, "outbox-file", cnf.OutboxFile, "json lines file for file domain events publisher")
+	f.IntVar(&cnf.OutboxInterval, "outbox-interval", cnf.OutboxInterval, "domain events relay interval in seconds")
+	f.IntVar(&cnf.OutboxRetention, "outbox-retention", cnf.OutboxRetention, "published domain events retention in hours")
+	f.IntVar(&cnf.EventsRetention, "events-retention", cnf.EventsRetention, "user events replay retention in hours")
+	f.StringVar(&cnf.TraceExporter, "trace-exporter", cnf.TraceExporter, "trace exporter: none, stdout or otlp")
+	f.StringVar(&cnf.TraceEndpoint, "trace-endpoint", cnf.TraceEndpoint, "otlp http endpoint url, empty uses sdk default")
+	f.IntVar(&cnf.ShutdownTimeout, "shutdown-timeout", cnf.ShutdownTimeout, "graceful shutdown timeout in seconds")
+	f.BoolVar(&cnf.OpenAPIValidate, "openapi-validate", cnf.OpenAPIValidate, "dev mode: validate api by openapi spec")
+	f.IntVar(&cnf.HealthWorkerMaxAge, "health-worker-max-age", cnf.HealthWorkerMaxAge, "worker heartbeat max age, seconds")
+	f.BoolVar(&cnf.HealthAccrual, "health-check-accrual", cnf.HealthAccrual, "check accrual reachability in readiness")
+	f.StringVar(&cnf.TLSCert, "tls-cert", cnf.TLSCert, "tls certificate file, empty serves plain http")
+	f.StringVar(&cnf.TLSKey, "tls-key", cnf.TLSKey, "tls private key file")
+	f.StringVar(&cnf.TLSMinVersion, "tls-min-version", cnf.TLSMinVersion, "min tls version: 1.2 or 1.3")
+	f.StringVar(&cnf.TLSCiphers, "tls-ciphers", cnf.TLSCiphers, "comma separated tls 1.2 cipher suites")
+	f.IntVar(&cnf.TLSReloadInterval, "tls-reload-interval", cnf.TLSReloadInterval, "tls files check interval, seconds")
+	f.StringVar(&cnf.AdminClientCA, "admin-client-ca", cnf.AdminClientCA, "client ca file, enables admin mtls")
+	f.BoolVar(&cnf.HTTP2, "http2", cnf.HTTP2, "serve http/2 over tls")
+	f.IntVar(&cnf.ReadHeaderTimeout, "read-header-timeout", cnf.ReadHeaderTimeout, "read header timeout, seconds")
+	f.IntVar(&cnf.ReadTimeout, "read-timeout", cnf.ReadTimeout, "request read timeout, seconds, 0 disables")
+	f.IntVar(&cnf.WriteTimeout, "write-timeout", cnf.WriteTimeout, "response write timeout, seconds, 0 disables")
+	f.IntVar(&cnf.IdleTimeout, "idle-timeout", cnf.IdleTimeout, "keep-alive idle timeout, seconds")
+	f.BoolVar(&cnf.AutoMigrate, "auto-migrate", cnf.AutoMigrate, "apply migrations at server start")
+	f.BoolVar(&cnf.DevMode, "dev", cnf.DevMode, "dev mode: allow built-in token secret")
+	f.StringVar(&cnf.ConfigFile, "config", cnf.ConfigFile, "yaml or toml config file")
+	f.BoolVar(&cnf.PrintConfig, "print-config", cnf.PrintConfig, "print effective config with secrets redacted and exit")
 	if err := f.Parse(params); err != nil {
 		return fmt.Errorf("InitFlags: parse flags fail: %w", err)
 	}
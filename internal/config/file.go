@@ -0,0 +1,124 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrFileFormat     = errors.New("unsupported config file format")
+	ErrFileUnknownKey = errors.New("unknown config file key")
+	ErrFileValueType  = errors.New("wrong config file value type")
+)
+
+// configPath путь к файлу конфигурации из флага -config или CONFIG_FILE.
+// Файл читается до разбора флагов, поэтому флаг ищется в параметрах вручную.
+func configPath(params []string) string {
+	if path, ok := os.LookupEnv("CONFIG_FILE"); ok {
+		return path
+	}
+
+	for i, p := range params {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(p, "-"), "=")
+		if !strings.HasPrefix(p, "-") || name != "config" {
+			continue
+		}
+
+		if hasValue {
+			return value
+		}
+
+		if i+1 < len(params) {
+			return params[i+1]
+		}
+	}
+
+	return ""
+}
+
+// loadFile читает YAML или TOML файл. Ключи файла - имена переменных окружения
+// в нижнем регистре, например run_address или rate_limit.
+func (cnf *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("loadFile: read config file fail: %w", err)
+	}
+
+	values := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("loadFile: %w: %q", ErrFileFormat, ext)
+	}
+
+	if err != nil {
+		return fmt.Errorf("loadFile: parse %s fail: %w", path, err)
+	}
+
+	fields := cnf.fields()
+	for key, raw := range values {
+		field, ok := fields[key]
+		if !ok {
+			return fmt.Errorf("loadFile: %w: %s", ErrFileUnknownKey, key)
+		}
+
+		if err := setField(field, raw); err != nil {
+			return fmt.Errorf("loadFile: key %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// fields поля конфигурации по ключам файла.
+func (cnf *Config) fields() map[string]reflect.Value {
+	v := reflect.ValueOf(cnf).Elem()
+	fields := map[string]reflect.Value{}
+	for i := range v.NumField() {
+		if name := fileKey(v.Type().Field(i)); name != "" {
+			fields[name] = v.Field(i)
+		}
+	}
+
+	return fields
+}
+
+func fileKey(f reflect.StructField) string {
+	return strings.ToLower(f.Tag.Get("env"))
+}
+
+func setField(field reflect.Value, raw any) error {
+	switch field.Kind() {
+	case reflect.String:
+		if v, ok := raw.(string); ok {
+			field.SetString(v)
+			return nil
+		}
+	case reflect.Int:
+		switch v := raw.(type) {
+		case int:
+			field.SetInt(int64(v))
+			return nil
+		case int64:
+			field.SetInt(v)
+			return nil
+		}
+	case reflect.Bool:
+		if v, ok := raw.(bool); ok {
+			field.SetBool(v)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: expected %s, got %T", ErrFileValueType, field.Kind(), raw)
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "xxxxx"
+
+var dsnPassword = regexp.MustCompile(`password=\S+`)
+
+// Print выводит итоговую конфигурацию в формате файла конфигурации, секреты скрыты.
+func (cnf *Config) Print(w io.Writer) error {
+	doc := yaml.Node{Kind: yaml.MappingNode}
+	v := reflect.ValueOf(cnf).Elem()
+	for i := range v.NumField() {
+		key := fileKey(v.Type().Field(i))
+		if key == "" {
+			continue
+		}
+
+		value := yaml.Node{}
+		if err := value.Encode(redact(key, v.Field(i).Interface())); err != nil {
+			return fmt.Errorf("Print: encode %s fail: %w", key, err)
+		}
+
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &value)
+	}
+
+	enc := yaml.NewEncoder(w)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("Print: encode config fail: %w", err)
+	}
+
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("Print: close encoder fail: %w", err)
+	}
+
+	return nil
+}
+
+func redact(key string, value any) any {
+	s, ok := value.(string)
+	if !ok || s == "" {
+		return value
+	}
+
+	switch key {
+	case "token_secret", "oidc_client_secret":
+		return redacted
+	case "database_uri":
+		if u, err := url.Parse(s); err == nil && u.User != nil {
+			return u.Redacted()
+		}
+
+		return dsnPassword.ReplaceAllString(s, "password="+redacted)
+	}
+
+	return value
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/arefev/gophermart/internal/tlsconf"
+	"go.uber.org/zap/zapcore"
+)
+
+var ErrInvalid = errors.New("invalid config")
+
+// Validate проверяет конфигурацию до запуска сервиса и возвращает все найденные ошибки сразу.
+func (cnf *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(cnf.Address != "", "run_address must not be empty")
+
+	_, err := zapcore.ParseLevel(cnf.LogLevel)
+	check(err == nil, "log_level %q is unknown", cnf.LogLevel)
+
+	check(cnf.TokenSecret != "", "token_secret must not be empty")
+	check(cnf.TokenSecret != tokenSecret || cnf.DevMode,
+		"token_secret is the built-in default, set a real secret or enable dev_mode")
+
+	// Без обработчиков очередь воркера не разбирается и опрос заказов зависает
+	check(cnf.RateLimit > 0, "rate_limit must be at least 1, got %d", cnf.RateLimit)
+
+	positive := []struct {
+		name  string
+		value int
+	}{
+		{"poll_interval", cnf.PollInterval},
+		{"token_duration", cnf.TokenDuration},
+		{"two_factor_token_duration", cnf.TwoFactorTTL},
+		{"two_factor_max_attempts", cnf.TwoFactorAttempts},
+		{"two_factor_lockout", cnf.TwoFactorLockout},
+		{"order_batch_max_size", cnf.OrderBatchMax},
+		{"webhook_interval", cnf.WebhookInterval},
+		{"webhook_max_attempts", cnf.WebhookMaxAttempts},
+		{"outbox_interval", cnf.OutboxInterval},
+		{"outbox_retention", cnf.OutboxRetention},
+		{"events_retention", cnf.EventsRetention},
+		{"health_worker_max_age", cnf.HealthWorkerMaxAge},
+		{"shutdown_timeout", cnf.ShutdownTimeout},
+		{"argon2_memory", cnf.Argon2Memory},
+		{"argon2_time", cnf.Argon2Time},
+		{"argon2_threads", cnf.Argon2Threads},
+		{"password_min_length", cnf.PwdMinLength},
+		{"tls_reload_interval", cnf.TLSReloadInterval},
+	}
+	for _, p := range positive {
+		check(p.value > 0, "%s must be positive, got %d", p.name, p.value)
+	}
+
+	// Цикл воркера должен успеть хотя бы один опрос, пока отметка готовности не устарела
+	check(cnf.HealthWorkerMaxAge > cnf.PollInterval, "health_worker_max_age %d must be greater than poll_interval %d",
+		cnf.HealthWorkerMaxAge, cnf.PollInterval)
+
+	// Параметры argon2id передаются в uint32 и uint8, большие значения переполнятся
+	check(int64(cnf.Argon2Memory) <= math.MaxUint32, "argon2_memory must be at most %d, got %d",
+		uint32(math.MaxUint32), cnf.Argon2Memory)
+	check(int64(cnf.Argon2Time) <= math.MaxUint32, "argon2_time must be at most %d, got %d",
+		uint32(math.MaxUint32), cnf.Argon2Time)
+	check(cnf.Argon2Threads <= math.MaxUint8, "argon2_threads must be at most %d, got %d",
+		math.MaxUint8, cnf.Argon2Threads)
+
+	check(cnf.PwdMaxLength >= cnf.PwdMinLength, "password_max_length %d is less than password_min_length %d",
+		cnf.PwdMaxLength, cnf.PwdMinLength)
+	check(cnf.PwdMinClasses >= 0 && cnf.PwdMinClasses <= 4,
+		"password_min_classes must be in range 0..4, got %d", cnf.PwdMinClasses)
+	check(cnf.PwdAlgorithm == "argon2id" || cnf.PwdAlgorithm == "bcrypt",
+		"password_algorithm %q is unknown, use argon2id or bcrypt", cnf.PwdAlgorithm)
+	check(cnf.BcryptCost >= 4 && cnf.BcryptCost <= 31, "bcrypt_cost must be in range 4..31, got %d", cnf.BcryptCost)
+
+	check(cnf.OutboxPublisher == "none" || cnf.OutboxPublisher == "stdout" || cnf.OutboxPublisher == "file",
+		"outbox_publisher %q is unknown, use none, stdout or file", cnf.OutboxPublisher)
+	check(cnf.TraceExporter == "none" || cnf.TraceExporter == "stdout" || cnf.TraceExporter == "otlp",
+		"trace_exporter %q is unknown, use none, stdout or otlp", cnf.TraceExporter)
+
+	timeouts := []struct {
+		name  string
+		value int
+	}{
+		{"read_header_timeout", cnf.ReadHeaderTimeout},
+		{"read_timeout", cnf.ReadTimeout},
+		{"write_timeout", cnf.WriteTimeout},
+		{"idle_timeout", cnf.IdleTimeout},
+	}
+	for _, p := range timeouts {
+		check(p.value >= 0, "%s must not be negative, got %d", p.name, p.value)
+	}
+
+	check((cnf.TLSCert == "") == (cnf.TLSKey == ""), "tls_cert_file and tls_key_file must be set together")
+	check(cnf.AdminClientCA == "" || cnf.TLSCert != "", "admin_client_ca_file requires tls_cert_file and tls_key_file")
+
+	_, err = tlsconf.ParseVersion(cnf.TLSMinVersion)
+	check(err == nil, "tls_min_version: %v", err)
+
+	_, err = tlsconf.ParseCiphers(cnf.TLSCiphers)
+	check(err == nil, "tls_cipher_suites: %v", err)
+
+	if cnf.OIDCIssuer != "" {
+		check(cnf.OIDCClientID != "", "oidc_client_id is required when oidc_issuer is set")
+		check(cnf.OIDCRedirect != "", "oidc_redirect_url is required when oidc_issuer is set")
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %w", ErrInvalid, errors.Join(errs...))
+}
@@ -0,0 +1,88 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/arefev/gophermart/internal/model"
+)
+
+const subscriberBuffer = 16
+
+// Hub рассылает события подписчикам внутри процесса.
+type Hub struct {
+	subs   map[int]map[chan model.Event]struct{}
+	mu     sync.Mutex
+	closed bool
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subs: map[int]map[chan model.Event]struct{}{},
+	}
+}
+
+// Subscribe подписывает на события пользователя. Канал закрывается при отписке
+// или если подписчик не успевает читать: клиент переподключится с Last-Event-ID
+// и получит пропущенные события из БД.
+func (h *Hub) Subscribe(userID int) (<-chan model.Event, func()) {
+	ch := make(chan model.Event, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+
+	if h.subs[userID] == nil {
+		h.subs[userID] = map[chan model.Event]struct{}{}
+	}
+	h.subs[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(userID, ch)
+	}
+}
+
+func (h *Hub) Publish(ev model.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs[ev.UserID] {
+		select {
+		case ch <- ev:
+		default:
+			h.remove(ev.UserID, ch)
+		}
+	}
+}
+
+// Close закрывает каналы всех подписчиков, чтобы открытые потоки завершились
+// при остановке сервера. Новые подписки после Close сразу получают закрытый канал.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for userID, subs := range h.subs {
+		for ch := range subs {
+			h.remove(userID, ch)
+		}
+	}
+}
+
+func (h *Hub) remove(userID int, ch chan model.Event) {
+	if _, ok := h.subs[userID][ch]; !ok {
+		return
+	}
+
+	delete(h.subs[userID], ch)
+	if len(h.subs[userID]) == 0 {
+		delete(h.subs, userID)
+	}
+
+	close(ch)
+}
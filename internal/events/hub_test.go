@@ -0,0 +1,81 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHubSlowSubscriber(t *testing.T) {
+	t.Run("slow subscriber is dropped", func(t *testing.T) {
+		hub := NewHub()
+		ch, unsubscribe := hub.Subscribe(1)
+		defer unsubscribe()
+
+		for i := range subscriberBuffer + 1 {
+			hub.Publish(model.Event{ID: int64(i + 1), UserID: 1})
+		}
+
+		count := 0
+		for range ch {
+			count++
+		}
+
+		require.Equal(t, subscriberBuffer, count)
+	})
+}
+
+func TestHubClose(t *testing.T) {
+	t.Run("close ends all subscriptions", func(t *testing.T) {
+		hub := NewHub()
+		first, unsubscribeFirst := hub.Subscribe(1)
+		defer unsubscribeFirst()
+		second, unsubscribeSecond := hub.Subscribe(2)
+		defer unsubscribeSecond()
+
+		hub.Close()
+		hub.Publish(model.Event{ID: 1, UserID: 1})
+
+		_, ok := <-first
+		require.False(t, ok)
+		_, ok = <-second
+		require.False(t, ok)
+
+		late, unsubscribe := hub.Subscribe(1)
+		defer unsubscribe()
+		_, ok = <-late
+		require.False(t, ok)
+	})
+}
+
+func TestRecent(t *testing.T) {
+	t.Run("recent skips repeated ids out of order", func(t *testing.T) {
+		r := NewRecent()
+		require.True(t, r.Add(10))
+		require.True(t, r.Add(9))
+		require.False(t, r.Add(10))
+		require.False(t, r.Add(9))
+
+		// Старые ID вытесняются, размер набора не растет
+		for i := range recentSize {
+			require.True(t, r.Add(int64(100+i)))
+		}
+		require.True(t, r.Add(10))
+		require.Len(t, r.ids, recentSize)
+	})
+}
+
+func TestDecodeNotification(t *testing.T) {
+	t.Run("decode notification", func(t *testing.T) {
+		payload := `{"id" : 3, "user_id" : 1, "type" : "order", ` +
+			`"payload" : {"number": "1", "status": "NEW"}, "created_at" : "2026-10-19T12:00:00.123456"}`
+
+		ev, err := decode(payload)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), ev.ID)
+		require.Equal(t, 1, ev.UserID)
+		require.Equal(t, model.EventOrder, ev.Type)
+		require.JSONEq(t, `{"number": "1", "status": "NEW"}`, ev.Payload)
+	})
+}
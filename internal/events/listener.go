@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	Channel        = "users_events"
+	reconnectDelay = 5 * time.Second
+)
+
+type notification struct {
+	CreatedAt string          `json:"created_at"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	ID        int64           `json:"id"`
+	UserID    int             `json:"user_id"`
+}
+
+// Listen слушает канал PostgreSQL users_events и публикует события в hub,
+// так события от воркера любой реплики доходят до клиентов этой реплики.
+func Listen(ctx context.Context, dsn string, hub *Hub, log *zap.Logger) error {
+	for {
+		err := listen(ctx, dsn, hub, log)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		log.Warn("events listener fail, reconnecting", zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func listen(ctx context.Context, dsn string, hub *Hub, log *zap.Logger) error {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return fmt.Errorf("listen connect fail: %w", err)
+	}
+
+	defer func() {
+		if err := conn.Close(context.Background()); err != nil {
+			log.Warn("events listener close fail", zap.Error(err))
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return fmt.Errorf("listen exec fail: %w", err)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("wait for notification fail: %w", err)
+		}
+
+		ev, err := decode(n.Payload)
+		if err != nil {
+			log.Warn("events listener decode fail", zap.Error(err))
+			continue
+		}
+
+		hub.Publish(*ev)
+	}
+}
+
+func decode(payload string) (*model.Event, error) {
+	n := notification{}
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		return nil, fmt.Errorf("decode notification fail: %w", err)
+	}
+
+	// json_build_object отдает timestamp без зоны
+	createdAt, err := time.Parse("2006-01-02T15:04:05.999999", n.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("decode notification time fail: %w", err)
+	}
+
+	return &model.Event{
+		ID:        n.ID,
+		UserID:    n.UserID,
+		Type:      n.Type,
+		Payload:   string(n.Payload),
+		CreatedAt: createdAt,
+	}, nil
+}
@@ -0,0 +1,36 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/arefev/gophermart/internal/model"
+)
+
+type OrderPayload struct {
+	Number  string  `json:"number"`
+	Status  string  `json:"status"`
+	Accrual float64 `json:"accrual,omitempty"`
+}
+
+type BalancePayload struct {
+	Current   float64 `json:"current"`
+	Withdrawn float64 `json:"withdrawn"`
+}
+
+func NewOrderPayload(number string, status model.OrderStatus, accrual float64) (string, error) {
+	return marshal(OrderPayload{Number: number, Status: status.String(), Accrual: accrual})
+}
+
+func NewBalancePayload(current, withdrawn float64) (string, error) {
+	return marshal(BalancePayload{Current: current, Withdrawn: withdrawn})
+}
+
+func marshal(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("marshal event payload fail: %w", err)
+	}
+
+	return string(b), nil
+}
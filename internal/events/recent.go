@@ -0,0 +1,39 @@
+package events
+
+// recentSize сколько последних ID помнит поток. События из БД и уведомления
+// приходят с небольшим разбросом, повторы дальше этого окна не встречаются.
+const recentSize = 256
+
+// Recent помнит ID последних отправленных событий потока. ID растут не строго
+// по порядку доставки: транзакция с меньшим ID может завершиться позже,
+// поэтому повторы отсекаются по набору, а не по наибольшему ID.
+type Recent struct {
+	ids   map[int64]struct{}
+	order []int64
+	next  int
+}
+
+func NewRecent() *Recent {
+	return &Recent{
+		ids:   make(map[int64]struct{}, recentSize),
+		order: make([]int64, 0, recentSize),
+	}
+}
+
+// Add запоминает ID и возвращает false, если событие уже отправлялось.
+func (r *Recent) Add(id int64) bool {
+	if _, ok := r.ids[id]; ok {
+		return false
+	}
+
+	if len(r.order) < recentSize {
+		r.order = append(r.order, id)
+	} else {
+		delete(r.ids, r.order[r.next])
+		r.order[r.next] = id
+		r.next = (r.next + 1) % recentSize
+	}
+	r.ids[id] = struct{}{}
+
+	return true
+}
@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	action "github.com/arefev/gophermart/internal/action/admin"
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/problem"
+	"github.com/arefev/gophermart/internal/response"
+	"github.com/arefev/gophermart/internal/service"
+	"go.uber.org/zap"
+)
+
+type admin struct {
+	app *application.App
+}
+
+func NewAdmin(app *application.App) *admin {
+	return &admin{app: app}
+}
+
+func (a *admin) GrantRole(w http.ResponseWriter, r *http.Request) {
+	err := action.NewGrantRoleAction(a.app).Handle(r)
+	a.roleResponse(w, r, err, "Grant role admin handler")
+}
+
+func (a *admin) RevokeRole(w http.ResponseWriter, r *http.Request) {
+	err := action.NewRevokeRoleAction(a.app).Handle(r)
+	a.roleResponse(w, r, err, "Revoke role admin handler")
+}
+
+func (a *admin) roleResponse(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrRoleJSONDecodeFail), errors.Is(err, service.ErrRoleValidateFail):
+		problem.Write(w, r, http.StatusBadRequest, err)
+	case errors.Is(err, service.ErrRoleUnknown):
+		problem.Write(w, r, http.StatusUnprocessableEntity, err)
+	case errors.Is(err, service.ErrRoleUserNotFound):
+		problem.Write(w, r, http.StatusNotFound, err)
+	case err != nil:
+		a.app.Logger(r.Context()).Error(msg, zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+	}
+}
+
+func (a *admin) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	key, err := action.NewAPIKeyCreateAction(a.app).Handle(r)
+
+	switch {
+	case errors.Is(err, service.ErrAPIKeyJSONDecodeFail), errors.Is(err, service.ErrAPIKeyValidateFail):
+		problem.Write(w, r, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, service.ErrAPIKeyUnknownScope):
+		problem.Write(w, r, http.StatusUnprocessableEntity, err)
+		return
+	case err != nil:
+		a.app.Logger(r.Context()).Error("Create api key admin handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := service.JSONResponse(w, key); err != nil {
+		a.app.Logger(r.Context()).Error("Create api key admin handler", zap.Error(err))
+	}
+}
+
+func (a *admin) APIKeys(w http.ResponseWriter, r *http.Request) {
+	list, err := action.NewAPIKeyListAction(a.app).Handle(r)
+	if err != nil {
+		a.app.Logger(r.Context()).Error("Api keys admin handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := service.JSONResponse(w, response.NewAPIKeys(list)); err != nil {
+		a.app.Logger(r.Context()).Error("Api keys admin handler", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (a *admin) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	err := action.NewAPIKeyRevokeAction(a.app).Handle(r)
+
+	switch {
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		problem.Write(w, r, http.StatusNotFound, err)
+		return
+	case err != nil:
+		a.app.Logger(r.Context()).Error("Revoke api key admin handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+}
+
+func (a *admin) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, err := action.NewWebhookCreateAction(a.app).Handle(r)
+
+	switch {
+	case errors.Is(err, service.ErrWebhookJSONDecodeFail), errors.Is(err, service.ErrWebhookValidateFail):
+		problem.Write(w, r, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, service.ErrWebhookUnknownEvent):
+		problem.Write(w, r, http.StatusUnprocessableEntity, err)
+		return
+	case errors.Is(err, service.ErrWebhookUserNotFound):
+		problem.Write(w, r, http.StatusNotFound, err)
+		return
+	case err != nil:
+		a.app.Logger(r.Context()).Error("Create webhook admin handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := service.JSONResponse(w, hook); err != nil {
+		a.app.Logger(r.Context()).Error("Create webhook admin handler", zap.Error(err))
+	}
+}
+
+func (a *admin) Webhooks(w http.ResponseWriter, r *http.Request) {
+	list, err := action.NewWebhookListAction(a.app).Handle(r)
+	if err != nil {
+		a.app.Logger(r.Context()).Error("Webhooks admin handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := service.JSONResponse(w, response.NewWebhooks(list)); err != nil {
+		a.app.Logger(r.Context()).Error("Webhooks admin handler", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (a *admin) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	err := action.NewWebhookDeleteAction(a.app).Handle(r)
+
+	switch {
+	case errors.Is(err, service.ErrWebhookNotFound):
+		problem.Write(w, r, http.StatusNotFound, err)
+		return
+	case err != nil:
+		a.app.Logger(r.Context()).Error("Delete webhook admin handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+}
+
+func (a *admin) WebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	list, err := action.NewWebhookDeliveriesAction(a.app).Handle(r)
+
+	switch {
+	case errors.Is(err, service.ErrWebhookNotFound):
+		problem.Write(w, r, http.StatusNotFound, err)
+		return
+	case err != nil:
+		a.app.Logger(r.Context()).Error("Webhook deliveries admin handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := service.JSONResponse(w, response.NewWebhookDeliveries(list)); err != nil {
+		a.app.Logger(r.Context()).Error("Webhook deliveries admin handler", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (a *admin) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	list, err := action.NewUserSearchAction(a.app).Handle(r)
+	if a.supportError(w, r, err, "Search users admin handler") {
+		return
+	}
+
+	a.supportJSON(w, r, response.NewUsers(list), "Search users admin handler")
+}
+
+func (a *admin) User(w http.ResponseWriter, r *http.Request) {
+	user, err := action.NewUserFindAction(a.app).Handle(r)
+	if a.supportError(w, r, err, "User admin handler") {
+		return
+	}
+
+	a.supportJSON(w, r, response.NewUser(user), "User admin handler")
+}
+
+func (a *admin) UserBalance(w http.ResponseWriter, r *http.Request) {
+	balance, err := action.NewUserBalanceAction(a.app).Handle(r)
+	if a.supportError(w, r, err, "User balance admin handler") {
+		return
+	}
+
+	a.supportJSON(w, r, balance, "User balance admin handler")
+}
+
+func (a *admin) UserOrders(w http.ResponseWriter, r *http.Request) {
+	list, err := action.NewUserOrdersAction(a.app).Handle(r)
+	if a.supportError(w, r, err, "User orders admin handler") {
+		return
+	}
+
+	a.supportJSON(w, r, response.NewOrders(list), "User orders admin handler")
+}
+
+func (a *admin) UserWithdrawals(w http.ResponseWriter, r *http.Request) {
+	list, err := action.NewUserWithdrawalsAction(a.app).Handle(r)
+	if a.supportError(w, r, err, "User withdrawals admin handler") {
+		return
+	}
+
+	a.supportJSON(w, r, response.NewWithdrawals(list), "User withdrawals admin handler")
+}
+
+func (a *admin) AdjustBalance(w http.ResponseWriter, r *http.Request) {
+	balance, err := action.NewBalanceAdjustAction(a.app).Handle(r)
+	if a.supportError(w, r, err, "Adjust balance admin handler") {
+		return
+	}
+
+	a.supportJSON(w, r, balance, "Adjust balance admin handler")
+}
+
+func (a *admin) OrderStatus(w http.ResponseWriter, r *http.Request) {
+	err := action.NewOrderStatusAction(a.app).Handle(r)
+	a.supportError(w, r, err, "Order status admin handler")
+}
+
+func (a *admin) RecheckOrder(w http.ResponseWriter, r *http.Request) {
+	order, err := action.NewOrderRecheckAction(a.app).Handle(r)
+	if a.supportError(w, r, err, "Recheck order admin handler") {
+		return
+	}
+
+	a.supportJSON(w, r, response.NewOrder(order), "Recheck order admin handler")
+}
+
+// supportError пишет ответ с ошибкой операции поддержки, true если ошибка была.
+func (a *admin) supportError(w http.ResponseWriter, r *http.Request, err error, msg string) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, service.ErrSupportJSONDecodeFail), errors.Is(err, service.ErrSupportValidateFail):
+		problem.Write(w, r, http.StatusBadRequest, err)
+	case errors.Is(err, service.ErrSupportUserNotFound), errors.Is(err, service.ErrSupportOrderNotFound):
+		problem.Write(w, r, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrSupportNegativeBalance), errors.Is(err, service.ErrSupportOrderProcessed):
+		problem.Write(w, r, http.StatusConflict, err)
+	case errors.Is(err, service.ErrSupportReasonRequired),
+		errors.Is(err, service.ErrSupportAmountInvalid),
+		errors.Is(err, service.ErrSupportStatusInvalid):
+		problem.Write(w, r, http.StatusUnprocessableEntity, err)
+	case errors.Is(err, service.ErrSupportAccrualFail):
+		a.app.Logger(r.Context()).Warn(msg, zap.Error(err))
+		problem.Write(w, r, http.StatusBadGateway, err)
+	case errors.Is(err, service.ErrUserNotAuthorized):
+		problem.Write(w, r, http.StatusUnauthorized, err)
+	default:
+		a.app.Logger(r.Context()).Error(msg, zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+	}
+
+	return true
+}
+
+func (a *admin) supportJSON(w http.ResponseWriter, r *http.Request, v any, msg string) {
+	if err := service.JSONResponse(w, v); err != nil {
+		a.app.Logger(r.Context()).Error(msg, zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
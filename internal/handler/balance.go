@@ -7,6 +7,7 @@ import (
 	b_action "github.com/arefev/gophermart/internal/action/balance"
 	w_action "github.com/arefev/gophermart/internal/action/withdrawal"
 	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/problem"
 	"github.com/arefev/gophermart/internal/response"
 	"github.com/arefev/gophermart/internal/service"
 	"go.uber.org/zap"
@@ -24,13 +25,13 @@ func (b *balance) Find(w http.ResponseWriter, r *http.Request) {
 	balance, err := b_action.NewBalanceAction(b.app).Handle(r)
 
 	if err != nil {
-		b.app.Log.Error("Find balance handler", zap.Error(err))
-		w.WriteHeader(http.StatusInternalServerError)
+		b.app.Logger(r.Context()).Error("Find balance handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
 		return
 	}
 
 	if err := service.JSONResponse(w, balance); err != nil {
-		b.app.Log.Error("Find balance handler", zap.Error(err))
+		b.app.Logger(r.Context()).Error("Find balance handler", zap.Error(err))
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -41,33 +42,38 @@ func (b *balance) Withdraw(w http.ResponseWriter, r *http.Request) {
 
 	switch {
 	case errors.Is(err, w_action.ErrNotEnoughBalance):
-		w.WriteHeader(http.StatusPaymentRequired)
+		problem.Write(w, r, http.StatusPaymentRequired, err)
 		return
 	case errors.Is(err, w_action.ErrValidationWithdrawal):
-		w.WriteHeader(http.StatusUnprocessableEntity)
+		problem.Write(w, r, http.StatusUnprocessableEntity, err)
 		return
 	case err != nil:
-		b.app.Log.Error("Withdraw balance handler", zap.Error(err))
-		w.WriteHeader(http.StatusInternalServerError)
+		b.app.Logger(r.Context()).Error("Withdraw balance handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
 		return
 	}
 }
 
 func (b *balance) Withdrawals(w http.ResponseWriter, r *http.Request) {
-	list, err := w_action.NewListAction(b.app).Handle(r)
+	list, next, err := w_action.NewListAction(b.app).Handle(r)
 
 	switch {
+	case errors.Is(err, service.ErrListQueryInvalid):
+		problem.Write(w, r, http.StatusBadRequest, err)
+		return
 	case len(list) == 0:
 		w.WriteHeader(http.StatusNoContent)
 		return
 	case err != nil:
-		b.app.Log.Error("Withdrawals balance handler", zap.Error(err))
-		w.WriteHeader(http.StatusInternalServerError)
+		b.app.Logger(r.Context()).Error("Withdrawals balance handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
 		return
 	}
 
+	setNextPage(w, r, next)
+
 	if err := service.JSONResponse(w, response.NewWithdrawals(list)); err != nil {
-		b.app.Log.Error("Withdrawals balance handler", zap.Error(err))
+		b.app.Logger(r.Context()).Error("Withdrawals balance handler", zap.Error(err))
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
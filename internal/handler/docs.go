@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/openapi"
+	"go.uber.org/zap"
+)
+
+type docs struct {
+	app *application.App
+}
+
+func NewDocs(app *application.App) *docs {
+	return &docs{app: app}
+}
+
+// OpenAPI отдаёт спецификацию API.
+func (d *docs) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	if _, err := w.Write(openapi.Spec()); err != nil {
+		d.app.Logger(r.Context()).Error("OpenAPI docs handler", zap.Error(err))
+	}
+}
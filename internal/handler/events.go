@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arefev/gophermart/internal/application"
+	events_hub "github.com/arefev/gophermart/internal/events"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/problem"
+	"github.com/arefev/gophermart/internal/service"
+	"go.uber.org/zap"
+)
+
+const sseHeartbeat = 15 * time.Second
+
+var errEventsUnavailable = errors.New("events unavailable")
+
+type events struct {
+	app *application.App
+}
+
+func NewEvents(app *application.App) *events {
+	return &events{app: app}
+}
+
+// Stream отправляет пользователю изменения статусов заказов и баланса (Server-Sent Events).
+// При переподключении с Last-Event-ID сначала отдаются пропущенные события из БД.
+func (e *events) Stream(w http.ResponseWriter, r *http.Request) {
+	user, err := service.NewUserService(e.app).Authorized(r.Context())
+	if err != nil {
+		problem.Write(w, r, http.StatusUnauthorized, service.ErrUserNotAuthorized)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok || e.app.Events == nil {
+		problem.Write(w, r, http.StatusServiceUnavailable, errEventsUnavailable)
+		return
+	}
+
+	lastID, err := lastEventID(r)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	// Подписка раньше чтения из БД, чтобы не потерять события между ними
+	ch, unsubscribe := e.app.Events.Subscribe(user.ID)
+	defer unsubscribe()
+
+	var missed []model.Event
+	if lastID > 0 {
+		if missed, err = service.NewEventService(e.app).After(r.Context(), user.ID, lastID); err != nil {
+			e.app.Logger(r.Context()).Error("Stream events handler", zap.Error(err))
+			problem.Write(w, r, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// lastID граница, заданная клиентом; дальше повторы отсекаются по набору ID
+	sent := events_hub.NewRecent()
+	for i := range missed {
+		if err := writeEvent(w, &missed[i]); err != nil {
+			return
+		}
+		sent.Add(missed[i].ID)
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(sseHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			if ev.ID <= lastID || !sent.Add(ev.ID) {
+				continue
+			}
+
+			if err := writeEvent(w, &ev); err != nil {
+				return
+			}
+		}
+
+		flusher.Flush()
+	}
+}
+
+func writeEvent(w http.ResponseWriter, ev *model.Event) error {
+	_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, ev.Payload)
+	if err != nil {
+		return fmt.Errorf("write event fail: %w", err)
+	}
+
+	return nil
+}
+
+func lastEventID(r *http.Request) (int64, error) {
+	v := r.Header.Get("Last-Event-ID")
+	if v == "" {
+		v = r.URL.Query().Get("last_event_id")
+	}
+
+	if v == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("%w: last event id must be a positive number", service.ErrListQueryInvalid)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/health"
+	"github.com/arefev/gophermart/internal/service"
+	"go.uber.org/zap"
+)
+
+type healthProbe struct {
+	app *application.App
+}
+
+func NewHealth(app *application.App) *healthProbe {
+	return &healthProbe{app: app}
+}
+
+// Live отвечает, пока процесс жив и обрабатывает запросы.
+func (hp *healthProbe) Live(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := service.JSONResponse(w, health.Report{Status: health.StatusOK}); err != nil {
+		hp.app.Logger(r.Context()).Error("Live health handler", zap.Error(err))
+	}
+}
+
+// Ready проверяет зависимости, при любой неудаче или после начала остановки отвечает 503.
+func (hp *healthProbe) Ready(w http.ResponseWriter, r *http.Request) {
+	report := &health.Report{Status: health.StatusFail}
+	ok := false
+	if hp.app.Health != nil {
+		report, ok = hp.app.Health.Ready(r.Context())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		hp.app.Logger(r.Context()).Warn("service is not ready", zap.Any("report", report))
+	}
+
+	if err := service.JSONResponse(w, report); err != nil {
+		hp.app.Logger(r.Context()).Error("Ready health handler", zap.Error(err))
+	}
+}
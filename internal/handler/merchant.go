@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	m_action "github.com/arefev/gophermart/internal/action/merchant"
+	o_action "github.com/arefev/gophermart/internal/action/order"
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/problem"
+	"github.com/arefev/gophermart/internal/service"
+	"go.uber.org/zap"
+)
+
+type merchant struct {
+	app *application.App
+}
+
+func NewMerchant(app *application.App) *merchant {
+	return &merchant{app: app}
+}
+
+func (m *merchant) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	err := m_action.NewOrderCreateAction(m.app).Handle(r)
+
+	switch {
+	case errors.Is(err, m_action.ErrOrderJSONDecodeFail), errors.Is(err, m_action.ErrOrderValidateFail):
+		problem.Write(w, r, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, m_action.ErrOrderUserNotFound):
+		problem.Write(w, r, http.StatusNotFound, err)
+		return
+	case errors.Is(err, service.ErrUserBlocked):
+		problem.Write(w, r, http.StatusForbidden, err)
+		return
+	case errors.Is(err, o_action.ErrOrderCreateValidateFail):
+		problem.Write(w, r, http.StatusUnprocessableEntity, err)
+		return
+	case errors.Is(err, o_action.ErrOrderCreateUploadedByCurrentUser):
+		w.WriteHeader(http.StatusOK)
+		return
+	case errors.Is(err, o_action.ErrOrderCreateUploadedByOtherUser):
+		problem.Write(w, r, http.StatusConflict, err)
+		return
+	case err != nil:
+		m.app.Logger(r.Context()).Error("Create order merchant handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -6,6 +6,7 @@ import (
 
 	action "github.com/arefev/gophermart/internal/action/order"
 	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/problem"
 	"github.com/arefev/gophermart/internal/response"
 	"github.com/arefev/gophermart/internal/service"
 	"go.uber.org/zap"
@@ -24,29 +25,55 @@ func (o *order) Create(w http.ResponseWriter, r *http.Request) {
 
 	switch {
 	case errors.Is(err, action.ErrOrderCreateValidateFail):
-		w.WriteHeader(http.StatusUnprocessableEntity)
+		problem.Write(w, r, http.StatusUnprocessableEntity, err)
 		return
 	case errors.Is(err, action.ErrOrderCreateUploadedByCurrentUser):
 		w.WriteHeader(http.StatusOK)
 		return
 	case errors.Is(err, action.ErrOrderCreateUploadedByOtherUser):
-		w.WriteHeader(http.StatusConflict)
+		problem.Write(w, r, http.StatusConflict, err)
 		return
 	case err != nil:
-		o.app.Log.Error("Create order handler", zap.Error(err))
-		w.WriteHeader(http.StatusInternalServerError)
+		o.app.Logger(r.Context()).Error("Create order handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusAccepted)
 }
 
+func (o *order) Batch(w http.ResponseWriter, r *http.Request) {
+	res, err := action.NewBatchAction(o.app).Handle(r)
+
+	switch {
+	case errors.Is(err, action.ErrOrderBatchDecodeFail), errors.Is(err, action.ErrOrderBatchEmpty):
+		problem.Write(w, r, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, action.ErrOrderBatchTooLarge):
+		problem.Write(w, r, http.StatusRequestEntityTooLarge, err)
+		return
+	case err != nil:
+		o.app.Logger(r.Context()).Error("Batch order handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusMultiStatus)
+	if err := service.JSONResponse(w, res); err != nil {
+		o.app.Logger(r.Context()).Error("Batch order handler", zap.Error(err))
+	}
+}
+
 func (o *order) List(w http.ResponseWriter, r *http.Request) {
-	orders, err := action.NewListAction(o.app).Handle(r)
+	orders, next, err := action.NewListAction(o.app).Handle(r)
 
-	if err != nil {
-		o.app.Log.Error("List orders handler", zap.Error(err))
-		w.WriteHeader(http.StatusInternalServerError)
+	switch {
+	case errors.Is(err, service.ErrListQueryInvalid):
+		problem.Write(w, r, http.StatusBadRequest, err)
+		return
+	case err != nil:
+		o.app.Logger(r.Context()).Error("List orders handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -55,8 +82,30 @@ func (o *order) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setNextPage(w, r, next)
+
 	if err := service.JSONResponse(w, response.NewOrders(orders)); err != nil {
-		o.app.Log.Error("List orders handler", zap.Error(err))
+		o.app.Logger(r.Context()).Error("List orders handler", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (o *order) Find(w http.ResponseWriter, r *http.Request) {
+	order, err := action.NewFindAction(o.app).Handle(r)
+
+	switch {
+	case errors.Is(err, action.ErrOrderNotFound):
+		problem.Write(w, r, http.StatusNotFound, err)
+		return
+	case err != nil:
+		o.app.Logger(r.Context()).Error("Find order handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := service.JSONResponse(w, order); err != nil {
+		o.app.Logger(r.Context()).Error("Find order handler", zap.Error(err))
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"net/http"
+)
+
+const NextCursorHeader = "X-Next-Cursor"
+
+// setNextPage добавляет ссылку на следующую страницу списка в заголовки Link и X-Next-Cursor.
+func setNextPage(w http.ResponseWriter, r *http.Request, next string) {
+	if next == "" {
+		return
+	}
+
+	q := r.URL.Query()
+	q.Set("cursor", next)
+
+	u := *r.URL
+	u.RawQuery = q.Encode()
+
+	w.Header().Set(NextCursorHeader, next)
+	w.Header().Add("Link", "<"+u.RequestURI()+`>; rel="next"`)
+}
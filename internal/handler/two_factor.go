@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	action "github.com/arefev/gophermart/internal/action/twofactor"
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/problem"
+	"github.com/arefev/gophermart/internal/service"
+	"go.uber.org/zap"
+)
+
+type twoFactor struct {
+	app *application.App
+}
+
+func NewTwoFactor(app *application.App) *twoFactor {
+	return &twoFactor{app: app}
+}
+
+func (tf *twoFactor) Enroll(w http.ResponseWriter, r *http.Request) {
+	enroll, err := action.NewEnrollAction(tf.app).Handle(r)
+
+	switch {
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled):
+		problem.Write(w, r, http.StatusConflict, err)
+		return
+	case err != nil:
+		tf.app.Logger(r.Context()).Error("Enroll two factor handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := service.JSONResponse(w, enroll); err != nil {
+		tf.app.Logger(r.Context()).Error("Enroll two factor handler", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (tf *twoFactor) Confirm(w http.ResponseWriter, r *http.Request) {
+	codes, err := action.NewConfirmAction(tf.app).Handle(r)
+
+	switch {
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled):
+		problem.Write(w, r, http.StatusConflict, err)
+		return
+	case errors.Is(err, service.ErrTwoFactorJSONDecodeFail), errors.Is(err, service.ErrTwoFactorValidateFail),
+		errors.Is(err, service.ErrTwoFactorNotEnrolled):
+		problem.Write(w, r, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, service.ErrTwoFactorInvalidCode):
+		problem.Write(w, r, http.StatusUnprocessableEntity, err)
+		return
+	case err != nil:
+		tf.app.Logger(r.Context()).Error("Confirm two factor handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := service.JSONResponse(w, codes); err != nil {
+		tf.app.Logger(r.Context()).Error("Confirm two factor handler", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (tf *twoFactor) Login(w http.ResponseWriter, r *http.Request) {
+	token, err := action.NewLoginAction(tf.app).Handle(r)
+
+	switch {
+	case errors.Is(err, service.ErrTwoFactorJSONDecodeFail), errors.Is(err, service.ErrTwoFactorValidateFail):
+		problem.Write(w, r, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, service.ErrTwoFactorInvalidCode),
+		errors.Is(err, service.ErrTwoFactorNotEnrolled),
+		errors.Is(err, service.ErrTwoFactorLocked):
+		problem.Write(w, r, http.StatusUnauthorized, err)
+		return
+	case err != nil:
+		tf.app.Logger(r.Context()).Error("Login two factor handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Authorization", "Bearer "+token.AccessToken)
+}
@@ -6,7 +6,10 @@ import (
 
 	action "github.com/arefev/gophermart/internal/action/user"
 	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/problem"
 	"github.com/arefev/gophermart/internal/service"
+	"github.com/arefev/gophermart/internal/service/jwt"
+	"github.com/arefev/gophermart/internal/service/oidc"
 	"go.uber.org/zap"
 )
 
@@ -25,21 +28,24 @@ func (u *user) Register(w http.ResponseWriter, r *http.Request) {
 
 	switch {
 	case errors.Is(err, service.ErrRegisterUserExists):
-		w.WriteHeader(http.StatusConflict)
+		problem.Write(w, r, http.StatusConflict, err)
 		return
-	case errors.Is(err, service.ErrRegisterJSONDecodeFail), errors.Is(err, service.ErrRegisterValidateFail):
-		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, service.ErrRegisterValidateFail):
+		problem.Write(w, r, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, service.ErrRegisterJSONDecodeFail):
+		problem.Write(w, r, http.StatusBadRequest, err)
 		return
 	case err != nil:
-		u.app.Log.Error("Register user handler", zap.Error(err))
-		w.WriteHeader(http.StatusInternalServerError)
+		u.app.Logger(r.Context()).Error("Register user handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
 		return
 	}
 
 	token, err := service.NewUserService(u.app).Authorize(r.Context(), user.Login, user.Password)
 	if err != nil {
-		u.app.Log.Error("Register user handler authorize", zap.Error(err))
-		w.WriteHeader(http.StatusUnauthorized)
+		u.app.Logger(r.Context()).Error("Register user handler authorize", zap.Error(err))
+		problem.Write(w, r, http.StatusUnauthorized, err)
 		return
 	}
 
@@ -51,16 +57,131 @@ func (u *user) Login(w http.ResponseWriter, r *http.Request) {
 
 	switch {
 	case errors.Is(err, service.ErrAuthUserNotFound):
-		w.WriteHeader(http.StatusUnauthorized)
+		problem.Write(w, r, http.StatusUnauthorized, err)
+		return
+	case errors.Is(err, service.ErrUserBlocked):
+		problem.Write(w, r, http.StatusForbidden, err)
+		return
+	case errors.Is(err, service.ErrTwoFactorLocked):
+		problem.Write(w, r, http.StatusTooManyRequests, err)
 		return
 	case errors.Is(err, service.ErrAuthJSONDecodeFail), errors.Is(err, service.ErrAuthValidateFail):
-		w.WriteHeader(http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, err)
+		return
+	case err != nil:
+		u.app.Logger(r.Context()).Error("Login user handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Для пользователя с 2FA выдается токен только для второго шага входа
+	if token.Scope == jwt.ScopeTwoFactor {
+		w.WriteHeader(http.StatusAccepted)
+		if err := service.JSONResponse(w, token); err != nil {
+			u.app.Logger(r.Context()).Error("Login user handler", zap.Error(err))
+		}
+		return
+	}
+
+	w.Header().Set("Authorization", "Bearer "+token.AccessToken)
+}
+
+func (u *user) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	err := action.NewPasswordAction(u.app).Handle(r)
+
+	switch {
+	case errors.Is(err, service.ErrUserNotAuthorized), errors.Is(err, service.ErrPasswordWrongCurrent):
+		problem.Write(w, r, http.StatusUnauthorized, err)
+		return
+	case errors.Is(err, service.ErrPasswordValidateFail):
+		problem.Write(w, r, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, service.ErrPasswordJSONDecodeFail):
+		problem.Write(w, r, http.StatusBadRequest, err)
+		return
+	case err != nil:
+		u.app.Logger(r.Context()).Error("Change password user handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+}
+
+func (u *user) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	url, state, err := action.NewOIDCLoginAction(u.app).Handle(r)
+
+	switch {
+	case errors.Is(err, service.ErrOIDCDisabled):
+		problem.Write(w, r, http.StatusNotFound, err)
+		return
+	case errors.Is(err, oidc.ErrDiscoveryFail):
+		u.app.Logger(r.Context()).Error("OIDC login user handler", zap.Error(err))
+		problem.Write(w, r, http.StatusBadGateway, err)
+		return
+	case err != nil:
+		u.app.Logger(r.Context()).Error("OIDC login user handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.setOIDCState(w, r, state, int(service.OIDCStateTTL.Seconds()))
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+func (u *user) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	token, err := action.NewOIDCCallbackAction(u.app).Handle(r)
+
+	// Состояние одноразовое, cookie удаляется при любом исходе
+	u.setOIDCState(w, r, "", -1)
+
+	switch {
+	case errors.Is(err, service.ErrOIDCDisabled):
+		problem.Write(w, r, http.StatusNotFound, err)
+		return
+	case errors.Is(err, service.ErrOIDCStateMismatch):
+		problem.Write(w, r, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, service.ErrOIDCDenied):
+		u.app.Logger(r.Context()).Info("OIDC callback user handler", zap.Error(err))
+		problem.Write(w, r, http.StatusUnauthorized, err)
+		return
+	case errors.Is(err, service.ErrOIDCLoginTaken):
+		problem.Write(w, r, http.StatusConflict, err)
+		return
+	case errors.Is(err, service.ErrUserBlocked):
+		problem.Write(w, r, http.StatusForbidden, err)
+		return
+	case errors.Is(err, service.ErrTwoFactorLocked):
+		problem.Write(w, r, http.StatusTooManyRequests, err)
+		return
+	case errors.Is(err, oidc.ErrDiscoveryFail):
+		u.app.Logger(r.Context()).Error("OIDC callback user handler", zap.Error(err))
+		problem.Write(w, r, http.StatusBadGateway, err)
 		return
 	case err != nil:
-		u.app.Log.Error("Login user handler", zap.Error(err))
-		w.WriteHeader(http.StatusInternalServerError)
+		u.app.Logger(r.Context()).Error("OIDC callback user handler", zap.Error(err))
+		problem.Write(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if token.Scope == jwt.ScopeTwoFactor {
+		w.WriteHeader(http.StatusAccepted)
+		if err := service.JSONResponse(w, token); err != nil {
+			u.app.Logger(r.Context()).Error("OIDC callback user handler", zap.Error(err))
+		}
 		return
 	}
 
 	w.Header().Set("Authorization", "Bearer "+token.AccessToken)
 }
+
+func (u *user) setOIDCState(w http.ResponseWriter, r *http.Request, value string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     action.OIDCStateCookie,
+		Value:    value,
+		Path:     "/api/user/oidc",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
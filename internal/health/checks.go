@@ -0,0 +1,95 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrMigrationDirty   = errors.New("migration is dirty")
+	ErrMigrationVersion = errors.New("unexpected migration version")
+	ErrWorkerStale      = errors.New("worker heartbeat is stale")
+)
+
+// Ping проверяет доступность БД.
+func Ping(db *sqlx.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		if err := db.PingContext(ctx); err != nil {
+			return fmt.Errorf("db ping fail: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// Migrations проверяет, что схема БД в версии, с которой запускался сервис.
+func Migrations(db *sqlx.DB, expected uint) CheckFunc {
+	return func(ctx context.Context) error {
+		var row struct {
+			Version uint `db:"version"`
+			Dirty   bool `db:"dirty"`
+		}
+
+		if err := db.GetContext(ctx, &row, "SELECT version, dirty FROM schema_migrations LIMIT 1"); err != nil {
+			return fmt.Errorf("read migration version fail: %w", err)
+		}
+
+		if row.Dirty {
+			return fmt.Errorf("%w: version %d", ErrMigrationDirty, row.Version)
+		}
+
+		if row.Version != expected {
+			return fmt.Errorf("%w: %d, expected %d", ErrMigrationVersion, row.Version, expected)
+		}
+
+		return nil
+	}
+}
+
+// Fresh проверяет, что воркер отмечался не позже maxAge назад.
+func Fresh(hb *Heartbeat, maxAge time.Duration) CheckFunc {
+	return func(context.Context) error {
+		last := hb.Last()
+		if last.IsZero() {
+			return fmt.Errorf("%w: no heartbeat yet", ErrWorkerStale)
+		}
+
+		if age := time.Since(last); age > maxAge {
+			return fmt.Errorf("%w: last %s ago", ErrWorkerStale, age.Round(time.Second))
+		}
+
+		return nil
+	}
+}
+
+// Reachable проверяет, что система начислений отвечает. Любой HTTP ответ считается успехом.
+func Reachable(address string) CheckFunc {
+	url := address
+	if !strings.Contains(url, "://") {
+		url = "http://" + url
+	}
+
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+		if err != nil {
+			return fmt.Errorf("accrual request fail: %w", err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("accrual unreachable: %w", err)
+		}
+
+		if err := resp.Body.Close(); err != nil {
+			return fmt.Errorf("accrual response close fail: %w", err)
+		}
+
+		return nil
+	}
+}
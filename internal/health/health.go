@@ -0,0 +1,146 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK           = "ok"
+	StatusFail         = "fail"
+	StatusShuttingDown = "shutting_down"
+
+	checkTimeout = 2 * time.Second
+)
+
+// CheckFunc проверка зависимости, ошибка означает что сервис не готов.
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	fn   CheckFunc
+	name string
+}
+
+// Result результат одной проверки.
+type Result struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report ответ /readyz.
+type Report struct {
+	Checks map[string]Result `json:"checks,omitempty"`
+	Status string            `json:"status"`
+}
+
+// Health собирает проверки готовности и признак начала остановки.
+type Health struct {
+	worker   *Heartbeat
+	checks   []check
+	shutdown atomic.Bool
+	mu       sync.RWMutex
+}
+
+func New() *Health {
+	return &Health{
+		worker: &Heartbeat{},
+	}
+}
+
+// Register добавляет проверку, выполняемую на каждый запрос /readyz.
+func (h *Health) Register(name string, fn CheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checks = append(h.checks, check{name: name, fn: fn})
+}
+
+// Worker отметки цикла воркера начислений.
+func (h *Health) Worker() *Heartbeat {
+	return h.worker
+}
+
+// Shutdown переводит сервис в неготовое состояние в начале остановки,
+// чтобы балансировщик перестал присылать новые запросы.
+func (h *Health) Shutdown() {
+	h.shutdown.Store(true)
+}
+
+// Ready выполняет проверки параллельно, каждую с ограничением по времени.
+func (h *Health) Ready(ctx context.Context) (*Report, bool) {
+	if h.shutdown.Load() {
+		return &Report{Status: StatusShuttingDown}, false
+	}
+
+	h.mu.RLock()
+	checks := h.checks
+	h.mu.RUnlock()
+
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]Result, len(checks)),
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+
+	for _, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := run(ctx, c.fn)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[c.name] = res
+			if res.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		}()
+	}
+	wg.Wait()
+
+	return &report, report.Status == StatusOK
+}
+
+func run(ctx context.Context, fn CheckFunc) Result {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	res := Result{
+		Status:   StatusOK,
+		Duration: time.Since(start).String(),
+	}
+
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+
+	return res
+}
+
+// Heartbeat время последнего прохода цикла воркера.
+type Heartbeat struct {
+	last atomic.Int64
+}
+
+func (hb *Heartbeat) Beat() {
+	hb.last.Store(time.Now().UnixNano())
+}
+
+func (hb *Heartbeat) Last() time.Time {
+	last := hb.last.Load()
+	if last == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, last)
+}
@@ -0,0 +1,46 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFresh(t *testing.T) {
+	hb := &Heartbeat{}
+	check := Fresh(hb, time.Minute)
+
+	require.ErrorIs(t, check(context.Background()), ErrWorkerStale)
+
+	hb.Beat()
+	require.NoError(t, check(context.Background()))
+
+	hb.last.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	require.ErrorIs(t, check(context.Background()), ErrWorkerStale)
+}
+
+func TestReady(t *testing.T) {
+	h := New()
+	h.Register("ok", func(context.Context) error { return nil })
+
+	report, ok := h.Ready(context.Background())
+	require.True(t, ok)
+	require.Equal(t, StatusOK, report.Status)
+	require.Equal(t, StatusOK, report.Checks["ok"].Status)
+
+	h.Register("broken", func(context.Context) error { return errors.New("boom") })
+
+	report, ok = h.Ready(context.Background())
+	require.False(t, ok)
+	require.Equal(t, StatusFail, report.Status)
+	require.Equal(t, "boom", report.Checks["broken"].Error)
+
+	h.Shutdown()
+
+	report, ok = h.Ready(context.Background())
+	require.False(t, ok)
+	require.Equal(t, StatusShuttingDown, report.Status)
+}
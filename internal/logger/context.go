@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type ctxKey struct{}
+
+// WithContext кладет логер запроса в контекст, чтобы все строки лога запроса
+// несли одинаковые идентификаторы.
+func WithContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext логер запроса из контекста или fallback, если запрос его не задал.
+func FromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if ctx == nil {
+		return fallback
+	}
+
+	if l, ok := ctx.Value(ctxKey{}).(*zap.Logger); ok {
+		return l
+	}
+
+	return fallback
+}
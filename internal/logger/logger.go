@@ -12,6 +12,11 @@ func Build(level string) (*zap.Logger, error) {
 		return nil, fmt.Errorf("zap logger parse level failed: %w", err)
 	}
 
+	return BuildWithLevel(lvl)
+}
+
+// BuildWithLevel строит логер с переданным уровнем, чтобы менять его без перезапуска.
+func BuildWithLevel(lvl zap.AtomicLevel) (*zap.Logger, error) {
 	cfg := zap.NewProductionConfig()
 	cfg.Level = lvl
 
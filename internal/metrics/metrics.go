@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "gophermart"
+
+// Registry отдельный реестр сервиса, чтобы не зависеть от глобального реестра prometheus.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+// HTTP
+var (
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by chi route pattern, method and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by chi route pattern and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+// Транзакции
+var (
+	TrmDuration = factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "trm",
+		Name:      "duration_seconds",
+		Help:      "TrManager.Do duration including commit.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	TrmRollbacks = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "trm",
+		Name:      "rollbacks_total",
+		Help:      "Transactions rolled back because action or commit failed.",
+	})
+)
+
+// Воркер начислений
+var (
+	WorkerQueueDepth = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "worker",
+		Name:      "queue_depth",
+		Help:      "Orders waiting in worker job queue.",
+	})
+
+	AccrualDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "accrual",
+		Name:      "request_duration_seconds",
+		Help:      "Accrual system request latency by status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"status"})
+
+	AccrualPauses = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "accrual",
+		Name:      "pauses_total",
+		Help:      "Worker pauses after 429 Too Many Requests from accrual system.",
+	})
+
+	AccrualPauseSeconds = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "accrual",
+		Name:      "pause_seconds_total",
+		Help:      "Total time worker was paused by accrual system rate limit.",
+	})
+)
+
+// Бизнес метрики
+var (
+	OrdersUploaded = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_uploaded_total",
+		Help:      "Order numbers accepted for accrual.",
+	})
+
+	PointsAccrued = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "points_accrued_total",
+		Help:      "Loyalty points accrued to users.",
+	})
+
+	PointsWithdrawn = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "points_withdrawn_total",
+		Help:      "Loyalty points withdrawn by users.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// RegisterDB добавляет статистику пула соединений БД.
+func RegisterDB(db *sql.DB) error {
+	if err := Registry.Register(collectors.NewDBStatsCollector(db, namespace)); err != nil {
+		return fmt.Errorf("register db stats collector fail: %w", err)
+	}
+
+	return nil
+}
+
+// Handler отдаёт метрики в формате prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/go-chi/chi/v5"
+	chi_middleware "github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	requestIDMaxLen = 64
+	requestIDBytes  = 16
+)
+
+type accessEntryKey struct{}
+
+// accessEntry данные, которые становятся известны глубже по цепочке обработчиков.
+type accessEntry struct {
+	userID int
+}
+
+// AccessLog пишет строку журнала доступа через zap и кладет в контекст логер запроса
+// с request_id. Идентификатор берется из заголовка X-Request-ID или генерируется
+// и возвращается клиенту в том же заголовке.
+func (m *Middleware) AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		entry := &accessEntry{}
+		log := m.app.Log.With(zap.String("request_id", id))
+
+		ctx := context.WithValue(r.Context(), chi_middleware.RequestIDKey, id)
+		ctx = context.WithValue(ctx, accessEntryKey{}, entry)
+		ctx = logger.WithContext(ctx, log)
+
+		ww := chi_middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		fields := []zap.Field{
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+			zap.Int("status", status),
+			zap.Int("bytes", ww.BytesWritten()),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("remote", r.RemoteAddr),
+		}
+
+		if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+			fields = append(fields, zap.String("route", rctx.RoutePattern()))
+		}
+
+		if entry.userID != 0 {
+			fields = append(fields, zap.Int("user_id", entry.userID))
+		}
+
+		log.Info("http request", fields...)
+	})
+}
+
+// withUser добавляет пользователя в журнал доступа и в логер запроса.
+func (m *Middleware) withUser(ctx context.Context, userID int) context.Context {
+	if entry, ok := ctx.Value(accessEntryKey{}).(*accessEntry); ok {
+		entry.userID = userID
+	}
+
+	return logger.WithContext(ctx, m.app.Logger(ctx).With(zap.Int("user_id", userID)))
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > requestIDMaxLen {
+		return false
+	}
+
+	for _, c := range id {
+		isAlnum := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		if !isAlnum && c != '-' && c != '_' && c != '.' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, requestIDBytes)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
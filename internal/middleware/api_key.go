@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/problem"
+	"github.com/arefev/gophermart/internal/service"
+	"go.uber.org/zap"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// APIKey авторизует запросы сервер-сервер по ключу из заголовка X-API-Key.
+// Ключ должен иметь все перечисленные scopes.
+func (m *Middleware) APIKey(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get(APIKeyHeader)
+			if header == "" {
+				m.app.Logger(r.Context()).Debug("header X-API-Key not found")
+				problem.Write(w, r, http.StatusUnauthorized, service.ErrAPIKeyInvalid)
+				return
+			}
+
+			key, err := service.NewAPIKeyService(m.app).Authenticate(r.Context(), header)
+			if err != nil {
+				m.app.Logger(r.Context()).Debug("api key authenticate fail", zap.Error(err))
+				problem.Write(w, r, http.StatusUnauthorized, service.ErrAPIKeyInvalid)
+				return
+			}
+
+			for _, scope := range scopes {
+				if !key.HasScope(scope) {
+					m.app.Logger(r.Context()).Debug(
+						"api key has no scope",
+						zap.String("prefix", key.Prefix),
+						zap.String("scope", scope),
+					)
+					problem.Write(w, r, http.StatusForbidden, service.ErrAccessDenied)
+					return
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), model.APIKeyCtxKey{}, key)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -2,58 +2,51 @@ package middleware
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/problem"
 	"github.com/arefev/gophermart/internal/service"
 	"github.com/arefev/gophermart/internal/service/jwt"
 	"go.uber.org/zap"
 )
 
 func (m *Middleware) Authorized(next http.Handler) http.Handler {
+	return m.authorized(next, "")
+}
+
+// TwoFactorPending пропускает только токены, выданные после первого шага
+// входа пользователя с включенной двухфакторной аутентификацией.
+func (m *Middleware) TwoFactorPending(next http.Handler) http.Handler {
+	return m.authorized(next, jwt.ScopeTwoFactor)
+}
+
+func (m *Middleware) authorized(next http.Handler, scope string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		header := r.Header.Get("Authorization")
 		if header == "" {
-			m.app.Log.Debug("header Authorization not found")
-			w.WriteHeader(http.StatusUnauthorized)
+			m.app.Logger(r.Context()).Debug("header Authorization not found")
+			problem.Write(w, r, http.StatusUnauthorized, service.ErrUserNotAuthorized)
 			return
 		}
 
 		values := strings.Split(header, " ")
 		if len(values) != 2 || values[0] != "Bearer" {
-			m.app.Log.Debug("get token from header fail")
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
-
-		login, err := jwt.NewToken(m.app.Conf.TokenSecret).Parse(values[1]).GetLogin()
-		if err != nil {
-			m.app.Log.Debug("get login fail", zap.Error(err))
-			w.WriteHeader(http.StatusUnauthorized)
+			m.app.Logger(r.Context()).Debug("get token from header fail")
+			problem.Write(w, r, http.StatusUnauthorized, service.ErrUserNotAuthorized)
 			return
 		}
 
-		user, err := m.getUser(r.Context(), login)
+		user, err := service.NewUserService(m.app).Authenticate(r.Context(), values[1], scope)
 		if err != nil {
-			m.app.Log.Debug("get user fail", zap.Error(err))
-			w.WriteHeader(http.StatusUnauthorized)
+			m.app.Logger(r.Context()).Debug("authenticate fail", zap.Error(err))
+			problem.Write(w, r, http.StatusUnauthorized, service.ErrUserNotAuthorized)
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), model.User{}, user)
+		ctx := context.WithValue(r.Context(), model.UserCtxKey{}, user)
+		ctx = m.withUser(ctx, user.ID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
-
-func (m *Middleware) getUser(ctx context.Context, login string) (*model.User, error) {
-	var user *model.User
-
-	user, err := service.NewUserService(m.app).GetUser(ctx, login)
-	if err != nil {
-		return nil, fmt.Errorf("get user fail: %w", err)
-	}
-
-	return user, nil
-}
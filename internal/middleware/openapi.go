@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/openapi"
+	"github.com/arefev/gophermart/internal/problem"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"go.uber.org/zap"
+)
+
+// OpenAPI проверяет запросы и ответы по спецификации, предназначен для разработки.
+// Запрос не по спецификации отклоняется с 400, расхождение в ответе только пишется в лог.
+func (m *Middleware) OpenAPI(doc *openapi3.T) (func(http.Handler) http.Handler, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("openapi router fail: %w", err)
+	}
+
+	opts := &openapi3filter.Options{
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+		IncludeResponseStatus: true,
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, params, err := router.FindRoute(r)
+			if err != nil {
+				m.app.Logger(r.Context()).Warn("route not found in openapi spec", zap.String("path", r.URL.Path), zap.Error(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			in := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: params,
+				Route:      route,
+				Options:    opts,
+			}
+			if err := openapi3filter.ValidateRequest(r.Context(), in); err != nil {
+				problem.Write(w, r, http.StatusBadRequest, fmt.Errorf("%w: %w", openapi.ErrRequestInvalid, err))
+				return
+			}
+
+			rec := &specRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			if rec.stream {
+				return
+			}
+
+			out := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: in,
+				Status:                 rec.code(),
+				Header:                 rec.Header(),
+				Body:                   io.NopCloser(bytes.NewReader(rec.buf.Bytes())),
+				Options:                opts,
+			}
+			if err := openapi3filter.ValidateResponse(r.Context(), out); err != nil {
+				m.app.Logger(r.Context()).Warn(
+					"response does not match openapi spec",
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+					zap.Error(err),
+				)
+			}
+
+			rec.commit()
+		})
+	}, nil
+}
+
+// specRecorder копит ответ для проверки. После первого Flush (поток SSE)
+// переключается на прямую запись и ответ уже не проверяется.
+type specRecorder struct {
+	http.ResponseWriter
+	buf    bytes.Buffer
+	status int
+	stream bool
+}
+
+func (sr *specRecorder) WriteHeader(status int) {
+	if sr.stream {
+		sr.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	if sr.status == 0 {
+		sr.status = status
+	}
+}
+
+func (sr *specRecorder) Write(b []byte) (int, error) {
+	if sr.stream {
+		n, err := sr.ResponseWriter.Write(b)
+		if err != nil {
+			return n, fmt.Errorf("spec recorder write fail: %w", err)
+		}
+		return n, nil
+	}
+
+	n, err := sr.buf.Write(b)
+	if err != nil {
+		return n, fmt.Errorf("spec recorder buffer fail: %w", err)
+	}
+	return n, nil
+}
+
+func (sr *specRecorder) Flush() {
+	if !sr.stream {
+		sr.stream = true
+		sr.commit()
+	}
+
+	if f, ok := sr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (sr *specRecorder) code() int {
+	if sr.status == 0 {
+		return http.StatusOK
+	}
+	return sr.status
+}
+
+func (sr *specRecorder) commit() {
+	sr.ResponseWriter.WriteHeader(sr.code())
+	_, _ = sr.ResponseWriter.Write(sr.buf.Bytes())
+	sr.buf.Reset()
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/problem"
+	"github.com/arefev/gophermart/internal/service"
+	"go.uber.org/zap"
+)
+
+// RequireRole пропускает пользователя, у которого есть хотя бы одна из ролей.
+// Должен подключаться после Authorized.
+func (m *Middleware) RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := service.NewUserService(m.app).Authorized(r.Context())
+			if err != nil {
+				problem.Write(w, r, http.StatusUnauthorized, service.ErrUserNotAuthorized)
+				return
+			}
+
+			if !user.Roles.Has(roles...) {
+				m.app.Logger(r.Context()).Debug(
+					"user has no required role",
+					zap.String("login", user.Login),
+					zap.Strings("roles", roles),
+				)
+				problem.Write(w, r, http.StatusForbidden, service.ErrAccessDenied)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePermission пропускает пользователя, роли которого дают все перечисленные права.
+// Должен подключаться после Authorized.
+func (m *Middleware) RequirePermission(permissions ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := service.NewUserService(m.app).Authorized(r.Context())
+			if err != nil {
+				problem.Write(w, r, http.StatusUnauthorized, service.ErrUserNotAuthorized)
+				return
+			}
+
+			for _, p := range permissions {
+				if !user.Roles.Can(p) {
+					m.app.Logger(r.Context()).Debug(
+						"user has no permission",
+						zap.String("login", user.Login),
+						zap.String("permission", p),
+					)
+					problem.Write(w, r, http.StatusForbidden, service.ErrAccessDenied)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
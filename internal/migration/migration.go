@@ -0,0 +1,170 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+var (
+	ErrSchemaNewer    = errors.New("database schema is newer than this binary")
+	ErrSchemaOutdated = errors.New("database schema is outdated, run gophermart migrate up")
+	ErrSchemaDirty    = errors.New("database schema is dirty, fix it and run gophermart migrate force")
+	ErrStepsInvalid   = errors.New("number of migrations to roll back must be positive")
+)
+
+// Status состояние схемы БД относительно миграций, встроенных в бинарник.
+type Status struct {
+	Version uint
+	Latest  uint
+	Dirty   bool
+}
+
+type Migrator struct {
+	m      *migrate.Migrate
+	latest uint
+}
+
+// New мигратор по миграциям из каталога dir файловой системы fsys.
+func New(fsys fs.FS, dir, dsn string) (*Migrator, error) {
+	src, err := iofs.New(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("migrations source fail: %w", err)
+	}
+
+	latest, err := latest(src)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", src, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("migrations instance fail: %w", err)
+	}
+
+	return &Migrator{m: m, latest: latest}, nil
+}
+
+// Latest последняя версия, известная бинарнику.
+func (mg *Migrator) Latest() uint {
+	return mg.latest
+}
+
+func (mg *Migrator) Up() error {
+	if err := mg.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("migrations up fail: %w", err)
+	}
+
+	return nil
+}
+
+// Down откатывает steps последних миграций. Отрицательное steps в Steps
+// означало бы применение миграций, поэтому принимается только положительное.
+func (mg *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("%w, got %d", ErrStepsInvalid, steps)
+	}
+
+	if err := mg.m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("migrations down fail: %w", err)
+	}
+
+	return nil
+}
+
+// Force помечает схему версией version без выполнения миграций и снимает признак dirty.
+func (mg *Migrator) Force(version int) error {
+	if err := mg.m.Force(version); err != nil {
+		return fmt.Errorf("migrations force fail: %w", err)
+	}
+
+	return nil
+}
+
+func (mg *Migrator) Status() (Status, error) {
+	version, dirty, err := mg.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return Status{}, fmt.Errorf("migrations version fail: %w", err)
+	}
+
+	return Status{Version: version, Latest: mg.latest, Dirty: dirty}, nil
+}
+
+// Ensure готовит схему к запуску сервера: при up применяет новые миграции,
+// затем проверяет, что версия схемы совпадает с известной бинарнику.
+// Схема новее бинарника не трогается: откат старым бинарником потерял бы данные.
+func (mg *Migrator) Ensure(up bool) error {
+	status, err := mg.Status()
+	if err != nil {
+		return err
+	}
+
+	if status.Version > status.Latest {
+		return status.Check()
+	}
+
+	if up {
+		if err := mg.Up(); err != nil {
+			return err
+		}
+	}
+
+	return mg.Check()
+}
+
+// Check проверяет, что сервер может работать с текущей схемой.
+func (mg *Migrator) Check() error {
+	status, err := mg.Status()
+	if err != nil {
+		return err
+	}
+
+	return status.Check()
+}
+
+func (s Status) Check() error {
+	switch {
+	case s.Dirty:
+		return fmt.Errorf("%w: version %d", ErrSchemaDirty, s.Version)
+	case s.Version > s.Latest:
+		return fmt.Errorf("%w: schema %d, binary knows up to %d", ErrSchemaNewer, s.Version, s.Latest)
+	case s.Version < s.Latest:
+		return fmt.Errorf("%w: schema %d, binary expects %d", ErrSchemaOutdated, s.Version, s.Latest)
+	}
+
+	return nil
+}
+
+func (mg *Migrator) Close() error {
+	srcErr, dbErr := mg.m.Close()
+	if err := errors.Join(srcErr, dbErr); err != nil {
+		return fmt.Errorf("migrations close fail: %w", err)
+	}
+
+	return nil
+}
+
+func latest(src source.Driver) (uint, error) {
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("migrations first version fail: %w", err)
+	}
+
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+
+		if err != nil {
+			return 0, fmt.Errorf("migrations next version fail: %w", err)
+		}
+
+		version = next
+	}
+}
@@ -0,0 +1,73 @@
+package migration
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatest(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/1_init.up.sql":          {Data: []byte("SELECT 1")},
+		"m/1_init.down.sql":        {Data: []byte("SELECT 1")},
+		"m/20_add_column.up.sql":   {Data: []byte("SELECT 1")},
+		"m/20_add_column.down.sql": {Data: []byte("SELECT 1")},
+		"m/3_add_index.up.sql":     {Data: []byte("SELECT 1")},
+	}
+
+	src, err := iofs.New(fsys, "m")
+	require.NoError(t, err)
+
+	version, err := latest(src)
+	require.NoError(t, err)
+	require.Equal(t, uint(20), version)
+}
+
+func TestStatusCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		status Status
+		err    error
+	}{
+		{
+			name:   "up to date",
+			status: Status{Version: 3, Latest: 3},
+		},
+		{
+			name:   "schema newer than binary",
+			status: Status{Version: 4, Latest: 3},
+			err:    ErrSchemaNewer,
+		},
+		{
+			name:   "schema outdated",
+			status: Status{Version: 2, Latest: 3},
+			err:    ErrSchemaOutdated,
+		},
+		{
+			name:   "dirty",
+			status: Status{Version: 3, Latest: 3, Dirty: true},
+			err:    ErrSchemaDirty,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.status.Check()
+			if tt.err == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestDownStepsInvalid(t *testing.T) {
+	mg := &Migrator{}
+	for _, steps := range []int{0, -3} {
+		require.ErrorIs(t, mg.Down(steps), ErrStepsInvalid)
+	}
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"database/sql"
+	"slices"
+	"time"
+)
+
+const ScopeOrdersCreate = "orders:create"
+
+var apiKeyScopes = []string{ScopeOrdersCreate}
+
+// APIKeyCtxKey ключ контекста для ключа API, которым авторизован запрос.
+type APIKeyCtxKey struct{}
+
+type APIKey struct {
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+	LastUsedAt sql.NullTime `json:"-" db:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"-" db:"revoked_at"`
+	Name       string       `json:"name" db:"name"`
+	Prefix     string       `json:"prefix" db:"prefix"`
+	KeyHash    string       `json:"-" db:"key_hash"`
+	Scopes     StringList   `json:"scopes" db:"scopes"`
+	ID         int          `json:"id" db:"id"`
+}
+
+func APIKeyScopeExists(scope string) bool {
+	return slices.Contains(apiKeyScopes, scope)
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt.Valid
+}
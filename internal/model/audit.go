@@ -0,0 +1,28 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Действия службы поддержки, которые пишутся в журнал аудита.
+const (
+	AuditBalanceAdjust       = "balance.adjust"
+	AuditOrderRequeue        = "order.requeue"
+	AuditOrderInvalid        = "order.invalidate"
+	AuditOrderStatusOverride = "order.status_override"
+	AuditOrderRecheck        = "order.recheck"
+	AuditUserBlock           = "user.block"
+	AuditUserUnblock         = "user.unblock"
+)
+
+type AuditRecord struct {
+	CreatedAt   time.Time      `json:"createdAt" db:"created_at"`
+	Actor       string         `json:"actor" db:"actor"`
+	Action      string         `json:"action" db:"action"`
+	OrderNumber sql.NullString `json:"orderNumber" db:"order_number"`
+	Reason      string         `json:"reason" db:"reason"`
+	Details     string         `json:"details" db:"details"`
+	UserID      sql.NullInt64  `json:"userId" db:"user_id"`
+	ID          int64          `json:"id" db:"id"`
+}
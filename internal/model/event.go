@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	EventOrder   = "order"
+	EventBalance = "balance"
+)
+
+// Event событие пользователя для отправки клиенту (SSE).
+// Payload содержит JSON, ID сквозной для всех реплик и используется для Last-Event-ID.
+type Event struct {
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	Type      string    `json:"type" db:"type"`
+	Payload   string    `json:"payload" db:"payload"`
+	ID        int64     `json:"id" db:"id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+}
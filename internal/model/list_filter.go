@@ -0,0 +1,55 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrCursorInvalid = errors.New("cursor invalid")
+
+// Cursor позиция последней записи страницы для keyset пагинации.
+type Cursor struct {
+	At time.Time
+	ID int
+}
+
+// ListFilter параметры выборки списка. Нулевые значения означают отсутствие ограничения.
+type ListFilter struct {
+	From     time.Time
+	To       time.Time
+	Cursor   *Cursor
+	Statuses []OrderStatus
+	Limit    int
+	Asc      bool
+}
+
+func (c Cursor) Encode() string {
+	raw := c.At.UTC().Format(time.RFC3339Nano) + "|" + strconv.Itoa(c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrCursorInvalid
+	}
+
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrCursorInvalid
+	}
+
+	c := Cursor{}
+	if c.At, err = time.Parse(time.RFC3339Nano, at); err != nil {
+		return nil, ErrCursorInvalid
+	}
+
+	if c.ID, err = strconv.Atoi(id); err != nil {
+		return nil, ErrCursorInvalid
+	}
+
+	return &c, nil
+}
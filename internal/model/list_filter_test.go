@@ -0,0 +1,26 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	t.Run("cursor encode decode", func(t *testing.T) {
+		c := Cursor{At: time.Date(2026, 10, 19, 12, 0, 0, 123, time.UTC), ID: 42}
+
+		decoded, err := DecodeCursor(c.Encode())
+		require.NoError(t, err)
+		require.Equal(t, c.ID, decoded.ID)
+		require.True(t, c.At.Equal(decoded.At))
+	})
+
+	t.Run("cursor invalid", func(t *testing.T) {
+		for _, s := range []string{"", "!!", "YWJj", "MjAyNnwx"} {
+			_, err := DecodeCursor(s)
+			require.ErrorIs(t, err, ErrCursorInvalid)
+		}
+	})
+}
@@ -12,10 +12,17 @@ type Order struct {
 	Number     string          `json:"number" db:"number"`
 	Status     OrderStatus     `json:"status" db:"status"`
 	Accrual    sql.NullFloat64 `json:"accrual" db:"accrual,omitempty"`
+	CheckedAt  sql.NullTime    `json:"checkedAt" db:"checked_at"`
 	UserID     int             `json:"userId" db:"user_id"`
 	ID         int             `json:"id" db:"id"`
 }
 
+// OrderStatusChange запись истории смены статуса заказа.
+type OrderStatusChange struct {
+	CreatedAt time.Time   `json:"createdAt" db:"created_at"`
+	Status    OrderStatus `json:"status" db:"status"`
+}
+
 type OrderStatus int
 
 const (
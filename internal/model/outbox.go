@@ -0,0 +1,22 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Доменные события, которые публикуются во внешние системы через outbox.
+const (
+	OutboxUserRegistered  = "UserRegistered"
+	OutboxOrderUploaded   = "OrderUploaded"
+	OutboxOrderProcessed  = "OrderProcessed"
+	OutboxPointsWithdrawn = "PointsWithdrawn"
+)
+
+type OutboxMessage struct {
+	CreatedAt   time.Time    `db:"created_at"`
+	PublishedAt sql.NullTime `db:"published_at"`
+	Type        string       `db:"event_type"`
+	Payload     string       `db:"payload"`
+	ID          int64        `db:"id"`
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"slices"
+)
+
+const (
+	RoleAdmin   = "admin"
+	RoleSupport = "support"
+	RoleFinance = "finance"
+)
+
+const (
+	PermissionUsersRead      = "users:read"
+	PermissionUsersWrite     = "users:write"
+	PermissionOrdersRead     = "orders:read"
+	PermissionOrdersWrite    = "orders:write"
+	PermissionBalanceRead    = "balance:read"
+	PermissionBalanceAdjust  = "balance:adjust"
+	PermissionRolesManage    = "roles:manage"
+	PermissionAPIKeysManage  = "api_keys:manage"
+	PermissionWebhooksManage = "webhooks:manage"
+)
+
+var rolePermissions = map[string][]string{
+	RoleAdmin: {
+		PermissionUsersRead,
+		PermissionUsersWrite,
+		PermissionOrdersRead,
+		PermissionOrdersWrite,
+		PermissionBalanceRead,
+		PermissionBalanceAdjust,
+		PermissionRolesManage,
+		PermissionAPIKeysManage,
+		PermissionWebhooksManage,
+	},
+	RoleSupport: {
+		PermissionUsersRead,
+		PermissionUsersWrite,
+		PermissionOrdersRead,
+		PermissionOrdersWrite,
+		PermissionBalanceRead,
+	},
+	RoleFinance: {
+		PermissionUsersRead,
+		PermissionOrdersRead,
+		PermissionBalanceRead,
+		PermissionBalanceAdjust,
+	},
+}
+
+func RoleExists(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+type Roles []string
+
+func (r *Roles) Scan(src any) error {
+	var l StringList
+	if err := l.Scan(src); err != nil {
+		return fmt.Errorf("roles scan fail: %w", err)
+	}
+
+	*r = Roles(l)
+	return nil
+}
+
+func (r Roles) Value() (driver.Value, error) {
+	return StringList(r).Value()
+}
+
+func (r Roles) Has(roles ...string) bool {
+	for _, role := range roles {
+		if slices.Contains(r, role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r Roles) Can(permission string) bool {
+	for _, role := range r {
+		if slices.Contains(rolePermissions[role], permission) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Intersect возвращает только роли, которые есть в обоих списках.
+func (r Roles) Intersect(other []string) Roles {
+	result := Roles{}
+	for _, role := range r {
+		if slices.Contains(other, role) {
+			result = append(result, role)
+		}
+	}
+
+	return result
+}
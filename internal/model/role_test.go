@@ -0,0 +1,41 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRolesScan(t *testing.T) {
+	tests := []struct {
+		src  any
+		name string
+		want Roles
+	}{
+		{name: "nil", src: nil, want: Roles{}},
+		{name: "empty", src: "", want: Roles{}},
+		{name: "string", src: "admin,support", want: Roles{RoleAdmin, RoleSupport}},
+		{name: "bytes", src: []byte("finance"), want: Roles{RoleFinance}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r Roles
+			require.NoError(t, r.Scan(tt.src))
+			require.Equal(t, tt.want, r)
+		})
+	}
+}
+
+func TestRolesPermissions(t *testing.T) {
+	t.Run("roles permissions", func(t *testing.T) {
+		support := Roles{RoleSupport}
+		require.True(t, support.Has(RoleAdmin, RoleSupport))
+		require.False(t, support.Has(RoleAdmin))
+		require.True(t, support.Can(PermissionOrdersWrite))
+		require.False(t, support.Can(PermissionBalanceAdjust))
+		require.True(t, Roles{RoleFinance}.Can(PermissionBalanceAdjust))
+		require.False(t, Roles{}.Can(PermissionUsersRead))
+		require.Equal(t, Roles{RoleSupport}, Roles{RoleAdmin, RoleSupport}.Intersect([]string{RoleSupport}))
+	})
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// StringList хранится в БД как строка со значениями через запятую.
+type StringList []string
+
+func (l *StringList) Scan(src any) error {
+	var value string
+	switch v := src.(type) {
+	case nil:
+		value = ""
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("string list scan: unsupported type %T", src)
+	}
+
+	if value == "" {
+		*l = StringList{}
+		return nil
+	}
+
+	*l = strings.Split(value, ",")
+	return nil
+}
+
+func (l StringList) Value() (driver.Value, error) {
+	return strings.Join(l, ","), nil
+}
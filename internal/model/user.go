@@ -1,11 +1,30 @@
 package model
 
-import "time"
+import (
+	"database/sql"
+	"time"
+)
+
+// UserCtxKey ключ контекста для авторизованного пользователя.
+type UserCtxKey struct{}
 
 type User struct {
-	CreatedAt time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
-	Login     string    `json:"login" db:"login"`
-	Password  string    `json:"password" db:"password"`
-	ID        int       `json:"id" db:"id"`
+	CreatedAt       time.Time      `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time      `json:"updatedAt" db:"updated_at"`
+	Login           string         `json:"login" db:"login"`
+	Password        string         `json:"password" db:"password"`
+	TOTPSecret      sql.NullString `json:"-" db:"totp_secret"`
+	TOTPLockedAt    sql.NullTime   `json:"-" db:"totp_locked_at"`
+	TOTPLockedUntil sql.NullTime   `json:"-" db:"totp_locked_until"`
+	Roles           Roles          `json:"roles" db:"roles"`
+	TOTPLastStep    int64          `json:"-" db:"totp_last_step"`
+	TOTPFailures    int            `json:"-" db:"totp_failures"`
+	ID              int            `json:"id" db:"id"`
+	TOTPEnabled     bool           `json:"-" db:"totp_enabled"`
+	Blocked         bool           `json:"blocked" db:"blocked"`
+}
+
+// TOTPLocked второй шаг входа заблокирован после серии неверных кодов.
+func (u *User) TOTPLocked(now time.Time) bool {
+	return u.TOTPLockedUntil.Valid && now.Before(u.TOTPLockedUntil.Time)
 }
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"database/sql"
+	"slices"
+	"time"
+)
+
+const (
+	WebhookEventPointsAccrued   = "points.accrued"
+	WebhookEventPointsWithdrawn = "points.withdrawn"
+)
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusDead      = "dead"
+	DeliveryStatusCancelled = "cancelled"
+)
+
+var webhookEvents = []string{
+	WebhookEventPointsAccrued,
+	WebhookEventPointsWithdrawn,
+}
+
+type Webhook struct {
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+	URL       string        `json:"url" db:"url"`
+	Secret    string        `json:"-" db:"secret"`
+	Events    StringList    `json:"events" db:"events"`
+	UserID    sql.NullInt64 `json:"-" db:"user_id"`
+	ID        int           `json:"id" db:"id"`
+	Active    bool          `json:"active" db:"active"`
+}
+
+// WebhookDelivery запись outbox для отправки события на webhook и журнал доставки.
+type WebhookDelivery struct {
+	CreatedAt      time.Time      `db:"created_at"`
+	NextAttemptAt  time.Time      `db:"next_attempt_at"`
+	DeliveredAt    sql.NullTime   `db:"delivered_at"`
+	LastError      sql.NullString `db:"last_error"`
+	EventType      string         `db:"event_type"`
+	Payload        string         `db:"payload"`
+	Status         string         `db:"status"`
+	URL            string         `db:"url"`
+	Secret         string         `db:"secret"`
+	ID             int64          `db:"id"`
+	WebhookID      int            `db:"webhook_id"`
+	Attempts       int            `db:"attempts"`
+	LastStatusCode int            `db:"last_status_code"`
+}
+
+func WebhookEventExists(event string) bool {
+	return slices.Contains(webhookEvents, event)
+}
@@ -0,0 +1,35 @@
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ErrRequestInvalid запрос не соответствует спецификации.
+var ErrRequestInvalid = errors.New("request does not match openapi spec")
+
+//go:embed openapi.json
+var spec []byte
+
+// Spec исходный текст спецификации для отдачи клиентам.
+func Spec() []byte {
+	return spec
+}
+
+// Load разбирает и проверяет встроенную спецификацию.
+func Load(ctx context.Context) (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("load openapi spec fail: %w", err)
+	}
+
+	if err := doc.Validate(ctx); err != nil {
+		return nil, fmt.Errorf("validate openapi spec fail: %w", err)
+	}
+
+	return doc, nil
+}
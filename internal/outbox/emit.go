@@ -0,0 +1,47 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/arefev/gophermart/internal/application"
+)
+
+type UserRegistered struct {
+	Login  string `json:"login"`
+	Source string `json:"source"`
+}
+
+type OrderUploaded struct {
+	Number string `json:"number"`
+	UserID int    `json:"user_id"`
+}
+
+type OrderProcessed struct {
+	Number  string  `json:"number"`
+	Status  string  `json:"status"`
+	Accrual float64 `json:"accrual"`
+	UserID  int     `json:"user_id"`
+}
+
+type PointsWithdrawn struct {
+	Order  string  `json:"order"`
+	Sum    float64 `json:"sum"`
+	UserID int     `json:"user_id"`
+}
+
+// Emit сохраняет доменное событие в outbox. Вызывается внутри TrManager.Do,
+// чтобы событие записалось только вместе с изменением данных.
+func Emit(ctx context.Context, app *application.App, eventType string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal %s event fail: %w", eventType, err)
+	}
+
+	if err := app.Rep.Outbox.Add(ctx, eventType, string(b)); err != nil {
+		return fmt.Errorf("emit %s event fail: %w", eventType, err)
+	}
+
+	return nil
+}
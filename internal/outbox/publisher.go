@@ -0,0 +1,135 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/arefev/gophermart/internal/model"
+)
+
+const (
+	PublisherNone   = "none"
+	PublisherStdout = "stdout"
+	PublisherFile   = "file"
+)
+
+var ErrPublisherUnknown = errors.New("outbox publisher unknown")
+
+// Event сообщение, которое получают потребители событий.
+type Event struct {
+	OccurredAt time.Time       `json:"occurred_at"`
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload"`
+	ID         int64           `json:"id"`
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, e *Event) error
+	Close() error
+}
+
+func NewEvent(m *model.OutboxMessage) *Event {
+	return &Event{
+		ID:         m.ID,
+		Type:       m.Type,
+		OccurredAt: m.CreatedAt,
+		Payload:    json.RawMessage(m.Payload),
+	}
+}
+
+// NewPublisher создает публикатор по имени из конфигурации. Публикатор none
+// отбрасывает события, stdout пишет их в общий поток с логами.
+func NewPublisher(name, path string) (Publisher, error) {
+	switch name {
+	case PublisherNone:
+		return NewWriterPublisher(io.Discard), nil
+	case PublisherStdout:
+		return NewWriterPublisher(os.Stdout), nil
+	case PublisherFile:
+		return NewFilePublisher(path)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrPublisherUnknown, name)
+	}
+}
+
+// writerPublisher пишет события в формате JSON lines.
+type writerPublisher struct {
+	w     io.Writer
+	close func() error
+	mu    sync.Mutex
+}
+
+func NewWriterPublisher(w io.Writer) *writerPublisher {
+	return &writerPublisher{
+		w:     w,
+		close: func() error { return nil },
+	}
+}
+
+func NewFilePublisher(path string) (*writerPublisher, error) {
+	const perm = 0o644
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, perm)
+	if err != nil {
+		return nil, fmt.Errorf("open outbox file fail: %w", err)
+	}
+
+	return &writerPublisher{
+		w:     f,
+		close: f.Close,
+	}, nil
+}
+
+func (wp *writerPublisher) Publish(_ context.Context, e *Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal outbox event fail: %w", err)
+	}
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if _, err := wp.w.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("write outbox event fail: %w", err)
+	}
+
+	return nil
+}
+
+func (wp *writerPublisher) Close() error {
+	return wp.close()
+}
+
+// memoryPublisher отдает события в канал, используется в тестах.
+type memoryPublisher struct {
+	ch chan *Event
+}
+
+func NewMemoryPublisher(size int) *memoryPublisher {
+	return &memoryPublisher{
+		ch: make(chan *Event, size),
+	}
+}
+
+func (mp *memoryPublisher) Publish(ctx context.Context, e *Event) error {
+	select {
+	case mp.ch <- e:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("publish outbox event fail: %w", ctx.Err())
+	}
+}
+
+func (mp *memoryPublisher) Events() <-chan *Event {
+	return mp.ch
+}
+
+func (mp *memoryPublisher) Close() error {
+	close(mp.ch)
+	return nil
+}
@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+
+	p, err := NewPublisher(PublisherFile, path)
+	require.NoError(t, err)
+
+	messages := []model.OutboxMessage{
+		{ID: 1, Type: model.OutboxOrderUploaded, Payload: `{"number":"45031620082273","user_id":1}`, CreatedAt: time.Now()},
+		{ID: 2, Type: model.OutboxPointsWithdrawn, Payload: `{"order":"1","sum":10,"user_id":1}`, CreatedAt: time.Now()},
+	}
+
+	for i := range messages {
+		require.NoError(t, p.Publish(context.Background(), NewEvent(&messages[i])))
+	}
+	require.NoError(t, p.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, f.Close())
+	}()
+
+	var lines []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		lines = append(lines, e)
+	}
+
+	require.Len(t, lines, 2)
+	require.Equal(t, model.OutboxOrderUploaded, lines[0].Type)
+	require.JSONEq(t, messages[1].Payload, string(lines[1].Payload))
+}
+
+func TestNewPublisherUnknown(t *testing.T) {
+	_, err := NewPublisher("kafka", "")
+	require.ErrorIs(t, err, ErrPublisherUnknown)
+}
+
+func TestNewPublisherNone(t *testing.T) {
+	p, err := NewPublisher(PublisherNone, "")
+	require.NoError(t, err)
+
+	m := model.OutboxMessage{ID: 1, Type: model.OutboxOrderUploaded, Payload: `{}`, CreatedAt: time.Now()}
+	require.NoError(t, p.Publish(context.Background(), NewEvent(&m)))
+	require.NoError(t, p.Close())
+}
@@ -0,0 +1,113 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arefev/gophermart/internal/application"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultInterval = 1
+	batchSize       = 100
+	sweepInterval   = time.Hour
+)
+
+type relay struct {
+	app       *application.App
+	publisher Publisher
+}
+
+func NewRelay(app *application.App, p Publisher) *relay {
+	return &relay{
+		app:       app,
+		publisher: p,
+	}
+}
+
+func (r *relay) Run(ctx context.Context) error {
+	r.app.Log.Info("Outbox relay started")
+
+	ticker := time.NewTicker(r.interval())
+	defer ticker.Stop()
+
+	sweep := time.NewTicker(sweepInterval)
+	defer sweep.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.app.Log.Info("Outbox relay stopped")
+			if err := r.publisher.Close(); err != nil {
+				r.app.Log.Warn("outbox publisher close fail", zap.Error(err))
+			}
+			return fmt.Errorf("outbox relay stopped: %w", ctx.Err())
+		case <-ticker.C:
+			if _, err := r.Flush(ctx); err != nil {
+				r.app.Log.Error("outbox relay flush fail", zap.Error(err))
+			}
+		case <-sweep.C:
+			if err := r.Sweep(ctx); err != nil {
+				r.app.Log.Error("outbox relay sweep fail", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Flush публикует накопленные события по порядку и возвращает их количество.
+// Публикация останавливается на первой ошибке, остальные события уйдут в следующий раз,
+// поэтому потребитель получает события хотя бы один раз и в порядке записи.
+func (r *relay) Flush(ctx context.Context) (int, error) {
+	var published []int64
+	var publishErr error
+
+	// Публикатор не участвует в транзакции, поэтому получает внешний контекст
+	err := r.app.TrManager.Do(ctx, func(txCtx context.Context) error {
+		list := r.app.Rep.Outbox.Pending(txCtx, batchSize)
+		for i := range list {
+			if publishErr = r.publisher.Publish(ctx, NewEvent(&list[i])); publishErr != nil {
+				break
+			}
+			published = append(published, list[i].ID)
+		}
+
+		return r.app.Rep.Outbox.MarkPublished(txCtx, published)
+	})
+
+	if err != nil {
+		return 0, fmt.Errorf("outbox flush transaction fail: %w", err)
+	}
+
+	if publishErr != nil {
+		return len(published), fmt.Errorf("outbox publish fail: %w", publishErr)
+	}
+
+	return len(published), nil
+}
+
+// Sweep удаляет опубликованные события старше OutboxRetention часов
+// и события пользователей старше EventsRetention часов.
+// Неопубликованные события не удаляются, сколько бы они ни ждали.
+func (r *relay) Sweep(ctx context.Context) error {
+	retention := time.Duration(r.app.Conf.OutboxRetention) * time.Hour
+	if err := r.app.Rep.Outbox.Purge(ctx, retention); err != nil {
+		return fmt.Errorf("outbox sweep fail: %w", err)
+	}
+
+	retention = time.Duration(r.app.Conf.EventsRetention) * time.Hour
+	if err := r.app.Rep.Event.Purge(ctx, retention); err != nil {
+		return fmt.Errorf("events sweep fail: %w", err)
+	}
+
+	return nil
+}
+
+func (r *relay) interval() time.Duration {
+	if r.app.Conf.OutboxInterval > 0 {
+		return time.Duration(r.app.Conf.OutboxInterval) * time.Second
+	}
+
+	return defaultInterval * time.Second
+}
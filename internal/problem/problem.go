@@ -0,0 +1,89 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/response"
+	chi_middleware "github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+)
+
+const (
+	ContentType = "application/problem+json"
+	typePrefix  = "urn:gophermart:problem:"
+)
+
+// Problem тело ответа с ошибкой в формате RFC 7807.
+type Problem struct {
+	Type      string                `json:"type"`
+	Title     string                `json:"title"`
+	Detail    string                `json:"detail,omitempty"`
+	Instance  string                `json:"instance,omitempty"`
+	RequestID string                `json:"request_id,omitempty"`
+	Errors    []response.FieldError `json:"errors,omitempty"`
+	Status    int                   `json:"status"`
+}
+
+type kind struct {
+	slug  string
+	title string
+	errs  []error
+}
+
+// New собирает описание ошибки. Статус выбирает обработчик, тип и заголовок
+// определяются по первой подходящей sentinel ошибке из registry.
+// В detail попадает только текст sentinel ошибки: цепочка обернутых ошибок может
+// раскрыть внутренние подробности, поэтому она остается только в логе.
+// Для 5xx подробности не раскрываются.
+func New(r *http.Request, status int, err error) *Problem {
+	p := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Instance:  r.URL.Path,
+		RequestID: chi_middleware.GetReqID(r.Context()),
+	}
+
+	if status >= http.StatusInternalServerError || err == nil {
+		return &p
+	}
+
+	if k, detail, ok := lookup(err); ok {
+		p.Type = typePrefix + k.slug
+		p.Title = k.title
+		p.Detail = detail
+	}
+
+	if fields := response.NewValidationErrors(err).Errors; len(fields) > 0 {
+		p.Errors = fields
+	}
+
+	return &p
+}
+
+// Write отправляет ошибку клиенту в формате application/problem+json.
+func Write(w http.ResponseWriter, r *http.Request, status int, err error) {
+	p := New(r, status, err)
+	if err != nil && status < http.StatusInternalServerError {
+		logger.FromContext(r.Context(), zap.NewNop()).Debug("problem response", zap.Int("status", status), zap.Error(err))
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+func lookup(err error) (*kind, string, bool) {
+	for i := range registry {
+		for _, e := range registry[i].errs {
+			if errors.Is(err, e) {
+				return &registry[i], e.Error(), true
+			}
+		}
+	}
+
+	return nil, "", false
+}
@@ -0,0 +1,103 @@
+package problem
+
+import (
+	m_action "github.com/arefev/gophermart/internal/action/merchant"
+	o_action "github.com/arefev/gophermart/internal/action/order"
+	w_action "github.com/arefev/gophermart/internal/action/withdrawal"
+	"github.com/arefev/gophermart/internal/openapi"
+	"github.com/arefev/gophermart/internal/service"
+	"github.com/arefev/gophermart/internal/service/alg"
+	"github.com/arefev/gophermart/internal/service/oidc"
+)
+
+// registry порядок важен: более конкретные ошибки должны идти раньше общих,
+// например ошибка алгоритма Луна раньше общей ошибки валидации номера заказа.
+var registry = []kind{
+	{slug: "luhn-check-failed", title: "Number failed the Luhn check", errs: []error{alg.ErrLuhnInvalid}},
+	{slug: "invalid-order-number", title: "Invalid order number", errs: []error{o_action.ErrOrderCreateValidateFail}},
+	{
+		slug:  "order-uploaded-by-other-user",
+		title: "Order already uploaded by another user",
+		errs:  []error{o_action.ErrOrderCreateUploadedByOtherUser},
+	},
+	{slug: "invalid-batch", title: "Invalid order batch", errs: []error{o_action.ErrOrderBatchDecodeFail}},
+	{slug: "empty-batch", title: "Order batch is empty", errs: []error{o_action.ErrOrderBatchEmpty}},
+	{slug: "batch-too-large", title: "Order batch is too large", errs: []error{o_action.ErrOrderBatchTooLarge}},
+	{slug: "not-enough-balance", title: "Not enough points on balance", errs: []error{w_action.ErrNotEnoughBalance}},
+	{slug: "invalid-withdrawal", title: "Invalid withdrawal request", errs: []error{w_action.ErrValidationWithdrawal}},
+	{
+		slug:  "order-not-found",
+		title: "Order not found",
+		errs:  []error{w_action.ErrOrderNotFound, o_action.ErrOrderNotFound, service.ErrSupportOrderNotFound},
+	},
+	{slug: "user-exists", title: "User already exists", errs: []error{service.ErrRegisterUserExists}},
+	{slug: "invalid-credentials", title: "Invalid login or password", errs: []error{service.ErrAuthUserNotFound}},
+	{slug: "wrong-current-password", title: "Current password is wrong", errs: []error{service.ErrPasswordWrongCurrent}},
+	{slug: "unauthorized", title: "Authorization required", errs: []error{service.ErrUserNotAuthorized}},
+	{slug: "user-blocked", title: "User is blocked", errs: []error{service.ErrUserBlocked}},
+	{slug: "access-denied", title: "Access denied", errs: []error{service.ErrAccessDenied}},
+	{slug: "two-factor-enabled", title: "Two factor already enabled", errs: []error{service.ErrTwoFactorAlreadyEnabled}},
+	{slug: "two-factor-not-enrolled", title: "Two factor not enrolled", errs: []error{service.ErrTwoFactorNotEnrolled}},
+	{slug: "two-factor-invalid-code", title: "Invalid two factor code", errs: []error{service.ErrTwoFactorInvalidCode}},
+	{slug: "two-factor-locked", title: "Too many invalid two factor codes", errs: []error{service.ErrTwoFactorLocked}},
+	{slug: "unknown-role", title: "Unknown role", errs: []error{service.ErrRoleUnknown}},
+	{
+		slug:  "user-not-found",
+		title: "User not found",
+		errs: []error{
+			service.ErrRoleUserNotFound,
+			m_action.ErrOrderUserNotFound,
+			service.ErrSupportUserNotFound,
+			service.ErrWebhookUserNotFound,
+		},
+	},
+	{slug: "reason-required", title: "Reason is required", errs: []error{service.ErrSupportReasonRequired}},
+	{slug: "invalid-amount", title: "Amount must not be zero", errs: []error{service.ErrSupportAmountInvalid}},
+	{
+		slug:  "negative-balance",
+		title: "Balance would become negative",
+		errs:  []error{service.ErrSupportNegativeBalance},
+	},
+	{slug: "order-processed", title: "Order is already processed", errs: []error{service.ErrSupportOrderProcessed}},
+	{slug: "invalid-order-status", title: "Order status can not be set", errs: []error{service.ErrSupportStatusInvalid}},
+	{slug: "invalid-api-key", title: "Invalid API key", errs: []error{service.ErrAPIKeyInvalid}},
+	{slug: "api-key-not-found", title: "API key not found", errs: []error{service.ErrAPIKeyNotFound}},
+	{slug: "unknown-scope", title: "Unknown API key scope", errs: []error{service.ErrAPIKeyUnknownScope}},
+	{slug: "webhook-not-found", title: "Webhook not found", errs: []error{service.ErrWebhookNotFound}},
+	{slug: "unknown-webhook-event", title: "Unknown webhook event", errs: []error{service.ErrWebhookUnknownEvent}},
+	{slug: "oidc-disabled", title: "SSO login is disabled", errs: []error{service.ErrOIDCDisabled}},
+	{slug: "oidc-state-mismatch", title: "SSO login state mismatch", errs: []error{service.ErrOIDCStateMismatch}},
+	{slug: "oidc-denied", title: "SSO login denied", errs: []error{service.ErrOIDCDenied}},
+	{slug: "oidc-login-taken", title: "Login already taken", errs: []error{service.ErrOIDCLoginTaken}},
+	{slug: "oidc-provider-unavailable", title: "SSO provider unavailable", errs: []error{oidc.ErrDiscoveryFail}},
+	{slug: "spec-mismatch", title: "Request does not match API specification", errs: []error{openapi.ErrRequestInvalid}},
+	{slug: "invalid-query", title: "Invalid query parameters", errs: []error{service.ErrListQueryInvalid}},
+	{
+		slug:  "malformed-json",
+		title: "Request body is not valid JSON",
+		errs: []error{
+			service.ErrRegisterJSONDecodeFail,
+			service.ErrAuthJSONDecodeFail,
+			service.ErrPasswordJSONDecodeFail,
+			service.ErrTwoFactorJSONDecodeFail,
+			service.ErrRoleJSONDecodeFail,
+			service.ErrAPIKeyJSONDecodeFail,
+			service.ErrSupportJSONDecodeFail,
+			m_action.ErrOrderJSONDecodeFail,
+		},
+	},
+	{
+		slug:  "validation-failed",
+		title: "Request validation failed",
+		errs: []error{
+			service.ErrRegisterValidateFail,
+			service.ErrAuthValidateFail,
+			service.ErrPasswordValidateFail,
+			service.ErrTwoFactorValidateFail,
+			service.ErrRoleValidateFail,
+			service.ErrAPIKeyValidateFail,
+			service.ErrSupportValidateFail,
+			m_action.ErrOrderValidateFail,
+		},
+	},
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arefev/gophermart/internal/model"
+	"go.uber.org/zap"
+)
+
+type APIKey struct {
+	log *zap.Logger
+	*Base
+}
+
+func NewAPIKey(tr TxGetter, log *zap.Logger) *APIKey {
+	return &APIKey{
+		log:  log,
+		Base: NewBase(tr, log),
+	}
+}
+
+func (a *APIKey) Create(ctx context.Context, name, prefix, hash string, scopes []string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var id int
+	query := `
+		INSERT INTO api_keys(name, prefix, key_hash, scopes) 
+		VALUES(:name, :prefix, :key_hash, :scopes) 
+		RETURNING id
+	`
+	args := map[string]interface{}{
+		"name":     name,
+		"prefix":   prefix,
+		"key_hash": hash,
+		"scopes":   model.StringList(scopes),
+	}
+
+	if _, err := a.findWithArgs(ctx, args, query, &id); err != nil {
+		return 0, fmt.Errorf("api key create fail: %w", err)
+	}
+
+	return id, nil
+}
+
+func (a *APIKey) FindByPrefix(ctx context.Context, prefix string) (*model.APIKey, bool) {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	key := model.APIKey{}
+	query := `
+		SELECT id, name, prefix, key_hash, scopes, last_used_at, revoked_at, created_at 
+		FROM api_keys 
+		WHERE prefix = :prefix
+	`
+	args := map[string]interface{}{"prefix": prefix}
+
+	ok, err := a.findWithArgs(ctx, args, query, &key)
+	if err != nil {
+		a.log.Debug("find api key by prefix: find with args fail", zap.Error(err))
+		return nil, false
+	}
+
+	return &key, ok
+}
+
+func (a *APIKey) List(ctx context.Context) []model.APIKey {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var list []model.APIKey
+	query := `
+		SELECT id, name, prefix, key_hash, scopes, last_used_at, revoked_at, created_at 
+		FROM api_keys 
+		ORDER BY id
+	`
+
+	if err := a.getWithArgs(ctx, map[string]any{}, query, &list); err != nil {
+		a.log.Debug("api key list fail: get with args fail", zap.Error(err))
+		return []model.APIKey{}
+	}
+
+	return list
+}
+
+func (a *APIKey) Revoke(ctx context.Context, id int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var revokedID int
+	query := `
+		UPDATE api_keys 
+		SET revoked_at = CURRENT_TIMESTAMP 
+		WHERE id = :id AND revoked_at IS NULL 
+		RETURNING id
+	`
+	args := map[string]interface{}{"id": id}
+
+	ok, err := a.findWithArgs(ctx, args, query, &revokedID)
+	if err != nil {
+		return false, fmt.Errorf("api key revoke fail: %w", err)
+	}
+
+	return ok, nil
+}
+
+func (a *APIKey) TouchLastUsed(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := "UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP WHERE id = :id"
+	args := map[string]interface{}{"id": id}
+
+	if err := a.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("api key touch last used fail: %w", err)
+	}
+
+	return nil
+}
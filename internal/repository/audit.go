@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arefev/gophermart/internal/model"
+	"go.uber.org/zap"
+)
+
+type Audit struct {
+	log *zap.Logger
+	*Base
+}
+
+func NewAudit(tr TxGetter, log *zap.Logger) *Audit {
+	return &Audit{
+		log:  log,
+		Base: NewBase(tr, log),
+	}
+}
+
+// Create пишет запись в той же транзакции, что и само действие,
+// поэтому откат действия откатывает и запись аудита.
+func (a *Audit) Create(ctx context.Context, record model.AuditRecord) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := `
+		INSERT INTO audit_log(actor, action, user_id, order_number, reason, details) 
+		VALUES(:actor, :action, :user_id, :order_number, :reason, :details)
+	`
+	args := map[string]interface{}{
+		"actor":        record.Actor,
+		"action":       record.Action,
+		"user_id":      record.UserID,
+		"order_number": record.OrderNumber,
+		"reason":       record.Reason,
+		"details":      record.Details,
+	}
+
+	if err := a.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("audit create fail: %w", err)
+	}
+
+	return nil
+}
@@ -37,6 +37,32 @@ func (b *Balance) FindByUserID(ctx context.Context, userID int) (*model.Balance,
 	return &balance, ok
 }
 
+// AddCurrent атомарно изменяет текущий баланс на amount, не читая его заранее: параллельное
+// начисление или списание не затирается. Возвращает false, если баланс стал бы отрицательным.
+func (b *Balance) AddCurrent(ctx context.Context, userID int, amount float64) (*model.Balance, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	balance := model.Balance{}
+	query := `
+		UPDATE users_balance 
+		SET current = current + :amount 
+		WHERE user_id = :user_id AND current + :amount >= 0 
+		RETURNING id, user_id, current, withdrawn, created_at, updated_at
+	`
+	args := map[string]interface{}{
+		"user_id": userID,
+		"amount":  amount,
+	}
+
+	ok, err := b.findWithArgs(ctx, args, query, &balance)
+	if err != nil {
+		return nil, false, fmt.Errorf("add current fail: %w", err)
+	}
+
+	return &balance, ok, nil
+}
+
 func (b *Balance) UpdateByID(ctx context.Context, id int, current, withdrawn float64) error {
 	ctx, cancel := context.WithTimeout(ctx, timeCancel)
 	defer cancel()
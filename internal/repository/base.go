@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/arefev/gophermart/internal/tracing"
 	"github.com/jmoiron/sqlx"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -29,7 +32,10 @@ func NewBase(tr TxGetter, log *zap.Logger) *Base {
 	}
 }
 
-func (b *Base) findWithArgs(ctx context.Context, args map[string]any, q string, entity any) (bool, error) {
+func (b *Base) findWithArgs(ctx context.Context, args map[string]any, q string, entity any) (found bool, err error) {
+	ctx, span := startSpan(ctx, "find", q)
+	defer func() { tracing.End(span, err) }()
+
 	ctx, cancel := context.WithTimeout(ctx, timeCancel)
 	defer cancel()
 
@@ -55,7 +61,10 @@ func (b *Base) findWithArgs(ctx context.Context, args map[string]any, q string,
 	return true, nil
 }
 
-func (b *Base) execWithArgs(ctx context.Context, args map[string]any, q string) error {
+func (b *Base) execWithArgs(ctx context.Context, args map[string]any, q string) (err error) {
+	ctx, span := startSpan(ctx, "exec", q)
+	defer func() { tracing.End(span, err) }()
+
 	ctx, cancel := context.WithTimeout(ctx, timeCancel)
 	defer cancel()
 
@@ -78,7 +87,10 @@ func (b *Base) execWithArgs(ctx context.Context, args map[string]any, q string)
 	return nil
 }
 
-func (b *Base) getWithArgs(ctx context.Context, args map[string]any, q string, list any) error {
+func (b *Base) getWithArgs(ctx context.Context, args map[string]any, q string, list any) (err error) {
+	ctx, span := startSpan(ctx, "select", q)
+	defer func() { tracing.End(span, err) }()
+
 	ctx, cancel := context.WithTimeout(ctx, timeCancel)
 	defer cancel()
 
@@ -115,3 +127,17 @@ func (b *Base) prepare(ctx context.Context, q string) (*sqlx.NamedStmt, error) {
 
 	return stmt, nil
 }
+
+// startSpan спан запроса к БД с текстом запроса без значений аргументов.
+func startSpan(ctx context.Context, op, q string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(
+		ctx,
+		"db."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(op),
+			semconv.DBQueryText(q),
+		),
+	)
+}
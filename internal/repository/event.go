@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arefev/gophermart/internal/model"
+	"go.uber.org/zap"
+)
+
+type Event struct {
+	log *zap.Logger
+	*Base
+}
+
+func NewEvent(tr TxGetter, log *zap.Logger) *Event {
+	return &Event{
+		log:  log,
+		Base: NewBase(tr, log),
+	}
+}
+
+// Create сохраняет событие. После коммита триггер отправляет его в канал users_events.
+func (e *Event) Create(ctx context.Context, userID int, eventType string, payload string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := "INSERT INTO users_events(user_id, type, payload) VALUES(:user_id, :type, :payload)"
+	args := map[string]interface{}{
+		"user_id": userID,
+		"type":    eventType,
+		"payload": payload,
+	}
+
+	if err := e.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("event create fail: %w", err)
+	}
+
+	return nil
+}
+
+// Purge удаляет события старше retention. Переподключение с более старым Last-Event-ID
+// получит только оставшиеся события.
+func (e *Event) Purge(ctx context.Context, retention time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := "DELETE FROM users_events WHERE created_at < CURRENT_TIMESTAMP - make_interval(secs => :retention)"
+	args := map[string]interface{}{"retention": retention.Seconds()}
+
+	if err := e.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("events purge fail: %w", err)
+	}
+
+	return nil
+}
+
+// After возвращает события пользователя с id больше переданного.
+func (e *Event) After(ctx context.Context, userID int, id int64, limit int) []model.Event {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var list []model.Event
+	query := `
+		SELECT id, user_id, type, payload::text AS payload, created_at 
+		FROM users_events 
+		WHERE user_id = :user_id AND id > :id 
+		ORDER BY id 
+		LIMIT :limit
+	`
+	args := map[string]interface{}{
+		"user_id": userID,
+		"id":      id,
+		"limit":   limit,
+	}
+
+	if err := e.getWithArgs(ctx, args, query, &list); err != nil {
+		e.log.Debug("events after fail: get with args fail", zap.Error(err))
+		return []model.Event{}
+	}
+
+	return list
+}
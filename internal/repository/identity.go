@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+type Identity struct {
+	log *zap.Logger
+	*Base
+}
+
+func NewIdentity(tr TxGetter, log *zap.Logger) *Identity {
+	return &Identity{
+		log:  log,
+		Base: NewBase(tr, log),
+	}
+}
+
+// FindLogin возвращает логин пользователя, связанного с внешней учетной записью.
+func (i *Identity) FindLogin(ctx context.Context, issuer, subject string) (string, bool) {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var login string
+	query := `
+		SELECT u.login 
+		FROM users_identities i
+		JOIN users u ON u.id = i.user_id
+		WHERE i.issuer = :issuer AND i.subject = :subject
+	`
+	args := map[string]interface{}{
+		"issuer":  issuer,
+		"subject": subject,
+	}
+
+	ok, err := i.findWithArgs(ctx, args, query, &login)
+	if err != nil {
+		i.log.Debug("identity find login: find with args fail", zap.Error(err))
+		return "", false
+	}
+
+	return login, ok
+}
+
+func (i *Identity) Link(ctx context.Context, userID int, issuer, subject string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := "INSERT INTO users_identities(user_id, issuer, subject) VALUES(:user_id, :issuer, :subject)"
+	args := map[string]interface{}{
+		"user_id": userID,
+		"issuer":  issuer,
+		"subject": subject,
+	}
+
+	if err := i.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("identity link fail: %w", err)
+	}
+
+	return nil
+}
@@ -27,7 +27,7 @@ func (o *Order) FindByNumber(ctx context.Context, number string) (*model.Order,
 	order := model.Order{}
 	args := map[string]any{"number": number}
 	query := `
-		SELECT id, user_id, number, status, accrual, uploaded_at, created_at, updated_at 
+		SELECT id, user_id, number, status, accrual, uploaded_at, checked_at, created_at, updated_at 
 		FROM orders 
 		WHERE number = :number
 	`
@@ -82,6 +82,30 @@ func (o *Order) GetByUserID(ctx context.Context, userID int) []model.Order {
 	return list
 }
 
+// PageByUserID возвращает заказы пользователя с учетом фильтра и курсора.
+func (o *Order) PageByUserID(ctx context.Context, userID int, filter model.ListFilter) []model.Order {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var list []model.Order
+	args := map[string]interface{}{
+		"user_id": userID,
+	}
+	where, order := pageQuery(filter, "uploaded_at", args)
+	query := `
+		SELECT id, user_id, number, status, accrual, uploaded_at, created_at, updated_at 
+		FROM orders 
+		WHERE user_id = :user_id` + where + order
+
+	if err := o.getWithArgs(ctx, args, query, &list); err != nil {
+		o.log.Debug("order page fail: get with args fail", zap.Error(err))
+		return []model.Order{}
+	}
+
+	return list
+}
+
+// WithStatusNew возвращает заказы без окончательного статуса: NEW и PROCESSING.
 func (o *Order) WithStatusNew(ctx context.Context) []model.Order {
 	ctx, cancel := context.WithTimeout(ctx, timeCancel)
 	defer cancel()
@@ -90,11 +114,12 @@ func (o *Order) WithStatusNew(ctx context.Context) []model.Order {
 	query := `
 		SELECT id, user_id, number, status, accrual, uploaded_at, created_at, updated_at 
 		FROM orders 
-		WHERE status = :status 
+		WHERE status IN (:new, :processing) 
 		ORDER BY uploaded_at DESC
 	`
 	args := map[string]interface{}{
-		"status": model.OrderStatusNew,
+		"new":        model.OrderStatusNew,
+		"processing": model.OrderStatusProcessing,
 	}
 
 	if err := o.getWithArgs(ctx, args, query, &list); err != nil {
@@ -105,24 +130,122 @@ func (o *Order) WithStatusNew(ctx context.Context) []model.Order {
 	return list
 }
 
-func (o *Order) AccrualByID(ctx context.Context, sum float64, status model.OrderStatus, id int) error {
+// AccrualByID ставит заказу окончательный статус и начисление, если статус еще
+// не окончательный. Возвращает false, если заказ уже обработан: строка блокируется
+// UPDATE, поэтому из двух параллельных проверок заказ обновит только одна.
+func (o *Order) AccrualByID(ctx context.Context, sum float64, status model.OrderStatus, id int) (bool, error) {
 	ctx, cancel := context.WithTimeout(ctx, timeCancel)
 	defer cancel()
 
-	query := "UPDATE orders SET accrual = :accrual, status = :status, updated_at = CURRENT_TIMESTAMP WHERE id = :id"
+	var updatedID int
+	query := `
+		UPDATE orders 
+		SET accrual = :accrual, status = :status, checked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP 
+		WHERE id = :id AND status NOT IN (:processed, :invalid) 
+		RETURNING id
+	`
 	args := map[string]interface{}{
-		"accrual": sum,
-		"id":      id,
-		"status":  status,
+		"accrual":   sum,
+		"id":        id,
+		"status":    status,
+		"processed": model.OrderStatusProcessed,
+		"invalid":   model.OrderStatusInvalid,
+	}
+
+	ok, err := o.findWithArgs(ctx, args, query, &updatedID)
+	if err != nil {
+		return false, fmt.Errorf("accrual by id fail: %w", err)
+	}
+
+	return ok, nil
+}
+
+// CheckedByID отмечает время последней проверки заказа в системе начислений и сохраняет
+// промежуточный статус, если он продвинулся вперед: смену статуса записывает триггер истории.
+// Возвращает true, если статус изменился.
+func (o *Order) CheckedByID(ctx context.Context, status model.OrderStatus, id int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var updatedID int
+	query := `
+		UPDATE orders 
+		SET status = :status, checked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP 
+		WHERE id = :id AND status < :status AND status IN (:new, :processing) 
+		RETURNING id
+	`
+	args := map[string]interface{}{
+		"id":         id,
+		"status":     status,
+		"new":        model.OrderStatusNew,
+		"processing": model.OrderStatusProcessing,
+	}
+
+	changed, err := o.findWithArgs(ctx, args, query, &updatedID)
+	if err != nil {
+		return false, fmt.Errorf("checked by id fail: %w", err)
+	}
+
+	if changed {
+		return true, nil
+	}
+
+	query = "UPDATE orders SET checked_at = CURRENT_TIMESTAMP WHERE id = :id"
+	if err := o.execWithArgs(ctx, map[string]interface{}{"id": id}, query); err != nil {
+		return false, fmt.Errorf("checked by id fail: %w", err)
+	}
+
+	return false, nil
+}
+
+// StatusByID меняет статус заказа и сбрасывает время проверки, чтобы заказ
+// с новым статусом воркер проверил заново.
+func (o *Order) StatusByID(ctx context.Context, status model.OrderStatus, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := `
+		UPDATE orders 
+		SET status = :status, checked_at = NULL, updated_at = CURRENT_TIMESTAMP 
+		WHERE id = :id
+	`
+	args := map[string]interface{}{
+		"id":     id,
+		"status": status,
 	}
 
 	if err := o.execWithArgs(ctx, args, query); err != nil {
-		return fmt.Errorf("accrual by id fail: %w", err)
+		return fmt.Errorf("status by id fail: %w", err)
 	}
 
 	return nil
 }
 
+// StatusHistory возвращает историю смены статусов заказа в порядке изменения.
+// История пишется триггером orders_status_history_trigger.
+func (o *Order) StatusHistory(ctx context.Context, orderID int) []model.OrderStatusChange {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var list []model.OrderStatusChange
+	query := `
+		SELECT status, created_at 
+		FROM orders_status_history 
+		WHERE order_id = :order_id 
+		ORDER BY id
+	`
+	args := map[string]interface{}{
+		"order_id": orderID,
+	}
+
+	if err := o.getWithArgs(ctx, args, query, &list); err != nil {
+		o.log.Debug("status history fail: get with args fail", zap.Error(err))
+		return []model.OrderStatusChange{}
+	}
+
+	return list
+}
+
 func (o *Order) CreateWithdrawal(ctx context.Context, userID int, number string, sum float64) error {
 	ctx, cancel := context.WithTimeout(ctx, timeCancel)
 	defer cancel()
@@ -169,3 +292,33 @@ func (o *Order) GetWithdrawalsByUserID(ctx context.Context, userID int) []model.
 
 	return list
 }
+
+// PageWithdrawalsByUserID возвращает списания пользователя с учетом фильтра и курсора.
+func (o *Order) PageWithdrawalsByUserID(ctx context.Context, userID int, filter model.ListFilter) []model.Withdrawal {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var list []model.Withdrawal
+	args := map[string]interface{}{
+		"user_id": userID,
+	}
+	where, order := pageQuery(filter, "processed_at", args)
+	query := `
+		SELECT 
+			id,
+			user_id,
+			sum, 
+			processed_at,
+			created_at,
+			updated_at,
+			number
+		FROM withdrawals
+		WHERE user_id = :user_id` + where + order
+
+	if err := o.getWithArgs(ctx, args, query, &list); err != nil {
+		o.log.Debug("withdrawals page fail: get with args fail", zap.Error(err))
+		return []model.Withdrawal{}
+	}
+
+	return list
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arefev/gophermart/internal/model"
+	"go.uber.org/zap"
+)
+
+type Outbox struct {
+	log *zap.Logger
+	*Base
+}
+
+func NewOutbox(tr TxGetter, log *zap.Logger) *Outbox {
+	return &Outbox{
+		log:  log,
+		Base: NewBase(tr, log),
+	}
+}
+
+// Add сохраняет событие, вызывается в транзакции изменения данных.
+func (o *Outbox) Add(ctx context.Context, eventType, payload string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := "INSERT INTO outbox(event_type, payload) VALUES(:event_type, CAST(:payload AS jsonb))"
+	args := map[string]interface{}{
+		"event_type": eventType,
+		"payload":    payload,
+	}
+
+	if err := o.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("outbox add fail: %w", err)
+	}
+
+	return nil
+}
+
+// Pending возвращает неопубликованные события по порядку и блокирует их до конца транзакции.
+func (o *Outbox) Pending(ctx context.Context, limit int) []model.OutboxMessage {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var list []model.OutboxMessage
+	query := `
+		SELECT id, event_type, payload::text AS payload, created_at, published_at 
+		FROM outbox 
+		WHERE published_at IS NULL 
+		ORDER BY id 
+		LIMIT :limit 
+		FOR UPDATE SKIP LOCKED
+	`
+	args := map[string]interface{}{"limit": limit}
+
+	if err := o.getWithArgs(ctx, args, query, &list); err != nil {
+		o.log.Debug("outbox pending fail: get with args fail", zap.Error(err))
+		return []model.OutboxMessage{}
+	}
+
+	return list
+}
+
+// Purge удаляет события, опубликованные раньше retention назад.
+func (o *Outbox) Purge(ctx context.Context, retention time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := "DELETE FROM outbox WHERE published_at < CURRENT_TIMESTAMP - make_interval(secs => :retention)"
+	args := map[string]interface{}{"retention": retention.Seconds()}
+
+	if err := o.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("outbox purge fail: %w", err)
+	}
+
+	return nil
+}
+
+func (o *Outbox) MarkPublished(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := "UPDATE outbox SET published_at = CURRENT_TIMESTAMP WHERE id = ANY(:ids)"
+	args := map[string]interface{}{"ids": ids}
+
+	if err := o.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("outbox mark published fail: %w", err)
+	}
+
+	return nil
+}
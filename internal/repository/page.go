@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/arefev/gophermart/internal/model"
+)
+
+// pageQuery дополняет условия выборки фильтрами и курсором.
+// Сортировка идет по паре (timeColumn, id), чтобы курсор был однозначным.
+// Колонки времени без часового пояса хранят UTC, а драйвер при записи параметра
+// отбрасывает смещение, поэтому время переводится в UTC до передачи.
+func pageQuery(filter model.ListFilter, timeColumn string, args map[string]any) (string, string) {
+	var where strings.Builder
+
+	if !filter.From.IsZero() {
+		where.WriteString(" AND " + timeColumn + " >= :from")
+		args["from"] = filter.From.UTC()
+	}
+
+	if !filter.To.IsZero() {
+		where.WriteString(" AND " + timeColumn + " < :to")
+		args["to"] = filter.To.UTC()
+	}
+
+	if len(filter.Statuses) > 0 {
+		names := make([]string, 0, len(filter.Statuses))
+		for i, s := range filter.Statuses {
+			name := "status_" + strconv.Itoa(i)
+			names = append(names, ":"+name)
+			args[name] = s
+		}
+
+		where.WriteString(" AND status IN (" + strings.Join(names, ", ") + ")")
+	}
+
+	dir, cmp := "DESC", "<"
+	if filter.Asc {
+		dir, cmp = "ASC", ">"
+	}
+
+	if filter.Cursor != nil {
+		where.WriteString(" AND (" + timeColumn + ", id) " + cmp + " (:cursor_at, :cursor_id)")
+		args["cursor_at"] = filter.Cursor.At.UTC()
+		args["cursor_id"] = filter.Cursor.ID
+	}
+
+	order := " ORDER BY " + timeColumn + " " + dir + ", id " + dir
+	if filter.Limit > 0 {
+		order += " LIMIT :limit"
+		args["limit"] = filter.Limit
+	}
+
+	return where.String(), order
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageQueryUTC(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	from := time.Date(2026, 10, 19, 12, 0, 0, 0, msk)
+	to := from.Add(time.Hour)
+
+	args := map[string]any{}
+	filter := model.ListFilter{
+		From:   from,
+		To:     to,
+		Cursor: &model.Cursor{At: to, ID: 7},
+	}
+	pageQuery(filter, "uploaded_at", args)
+
+	for _, name := range []string{"from", "to", "cursor_at"} {
+		value, ok := args[name].(time.Time)
+		require.True(t, ok, name)
+		require.Equal(t, time.UTC, value.Location(), name)
+	}
+
+	require.Equal(t, time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC), args["from"])
+	require.Equal(t, time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC), args["to"])
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+type RecoveryCode struct {
+	log *zap.Logger
+	*Base
+}
+
+func NewRecoveryCode(tr TxGetter, log *zap.Logger) *RecoveryCode {
+	return &RecoveryCode{
+		log:  log,
+		Base: NewBase(tr, log),
+	}
+}
+
+func (rc *RecoveryCode) Replace(ctx context.Context, userID int, hashes []string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := "DELETE FROM users_recovery_codes WHERE user_id = :user_id"
+	args := map[string]interface{}{
+		"user_id": userID,
+	}
+
+	if err := rc.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("recovery codes delete fail: %w", err)
+	}
+
+	query = "INSERT INTO users_recovery_codes(user_id, code_hash) VALUES(:user_id, :code_hash)"
+	for _, hash := range hashes {
+		args := map[string]interface{}{
+			"user_id":   userID,
+			"code_hash": hash,
+		}
+
+		if err := rc.execWithArgs(ctx, args, query); err != nil {
+			return fmt.Errorf("recovery code create fail: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (rc *RecoveryCode) Use(ctx context.Context, userID int, hash string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var id int
+	query := `
+		UPDATE users_recovery_codes 
+		SET used_at = CURRENT_TIMESTAMP 
+		WHERE user_id = :user_id AND code_hash = :code_hash AND used_at IS NULL 
+		RETURNING id
+	`
+	args := map[string]interface{}{
+		"user_id":   userID,
+		"code_hash": hash,
+	}
+
+	ok, err := rc.findWithArgs(ctx, args, query, &id)
+	if err != nil {
+		return false, fmt.Errorf("recovery code use fail: %w", err)
+	}
+
+	return ok, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+type Role struct {
+	log *zap.Logger
+	*Base
+}
+
+func NewRole(tr TxGetter, log *zap.Logger) *Role {
+	return &Role{
+		log:  log,
+		Base: NewBase(tr, log),
+	}
+}
+
+func (r *Role) Grant(ctx context.Context, userID int, role string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := `
+		INSERT INTO users_roles(user_id, role) VALUES(:user_id, :role)
+		ON CONFLICT (user_id, role) DO NOTHING
+	`
+	args := map[string]interface{}{
+		"user_id": userID,
+		"role":    role,
+	}
+
+	if err := r.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("role grant fail: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Role) Revoke(ctx context.Context, userID int, role string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := "DELETE FROM users_roles WHERE user_id = :user_id AND role = :role"
+	args := map[string]interface{}{
+		"user_id": userID,
+		"role":    role,
+	}
+
+	if err := r.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("role revoke fail: %w", err)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/arefev/gophermart/internal/model"
 	"go.uber.org/zap"
@@ -30,7 +31,26 @@ func (u *User) FindByLogin(ctx context.Context, login string) (*model.User, bool
 	defer cancel()
 
 	user := model.User{}
-	query := "SELECT id, login, password, created_at, updated_at FROM users WHERE login = :login"
+	query := `
+		SELECT 
+			u.id,
+			u.login,
+			u.password,
+			u.totp_secret,
+			u.totp_enabled,
+			u.totp_last_step,
+			u.totp_failures,
+			u.totp_locked_at,
+			u.totp_locked_until,
+			u.blocked,
+			u.created_at,
+			u.updated_at,
+			COALESCE(string_agg(r.role, ',' ORDER BY r.role), '') AS roles
+		FROM users u
+		LEFT JOIN users_roles r ON r.user_id = u.id
+		WHERE u.login = :login
+		GROUP BY u.id
+	`
 	arg := map[string]interface{}{"login": login}
 
 	ok, err := u.findWithArgs(ctx, arg, query, &user)
@@ -63,3 +83,191 @@ func (u *User) Create(ctx context.Context, login, password string) error {
 
 	return nil
 }
+
+func (u *User) UpdatePassword(ctx context.Context, id int, password string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := "UPDATE users SET password = :password, updated_at = CURRENT_TIMESTAMP WHERE id = :id"
+	args := map[string]interface{}{
+		"id":       id,
+		"password": password,
+	}
+
+	if err := u.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("user update password fail: %w", err)
+	}
+
+	return nil
+}
+
+func (u *User) SetTOTPSecret(ctx context.Context, id int, secret string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := `
+		UPDATE users 
+		SET totp_secret = :secret, totp_enabled = false, totp_last_step = 0, updated_at = CURRENT_TIMESTAMP 
+		WHERE id = :id
+	`
+	args := map[string]interface{}{
+		"id":     id,
+		"secret": secret,
+	}
+
+	if err := u.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("user set totp secret fail: %w", err)
+	}
+
+	return nil
+}
+
+func (u *User) EnableTOTP(ctx context.Context, id int, step int64) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := `
+		UPDATE users 
+		SET totp_enabled = true, totp_last_step = :step, updated_at = CURRENT_TIMESTAMP 
+		WHERE id = :id
+	`
+	args := map[string]interface{}{
+		"id":   id,
+		"step": step,
+	}
+
+	if err := u.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("user enable totp fail: %w", err)
+	}
+
+	return nil
+}
+
+// UseTOTPStep сохраняет шаг использованного кода, если он больше предыдущего.
+// Возвращает false, если код этого шага уже был использован.
+func (u *User) UseTOTPStep(ctx context.Context, id int, step int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var updatedID int
+	query := `
+		UPDATE users 
+		SET totp_last_step = :step 
+		WHERE id = :id AND totp_last_step < :step 
+		RETURNING id
+	`
+	args := map[string]interface{}{
+		"id":   id,
+		"step": step,
+	}
+
+	ok, err := u.findWithArgs(ctx, args, query, &updatedID)
+	if err != nil {
+		return false, fmt.Errorf("user use totp step fail: %w", err)
+	}
+
+	return ok, nil
+}
+
+// TOTPFailed учитывает неверный код второго шага входа. После max неудач подряд
+// счетчик сбрасывается, выданные до блокировки токены второго шага больше не принимаются,
+// а новый вход блокируется на lockout. Возвращает true, если блокировка наступила.
+func (u *User) TOTPFailed(ctx context.Context, id int, maxFailures int, lockout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var failures int
+	query := `
+		UPDATE users 
+		SET 
+			totp_failures = CASE WHEN totp_failures + 1 >= :max THEN 0 ELSE totp_failures + 1 END,
+			totp_locked_at = CASE WHEN totp_failures + 1 >= :max THEN CURRENT_TIMESTAMP ELSE totp_locked_at END,
+			totp_locked_until = CASE 
+				WHEN totp_failures + 1 >= :max THEN CURRENT_TIMESTAMP + make_interval(secs => :lockout) 
+				ELSE totp_locked_until 
+			END 
+		WHERE id = :id 
+		RETURNING totp_failures
+	`
+	args := map[string]interface{}{
+		"id":      id,
+		"max":     maxFailures,
+		"lockout": lockout.Seconds(),
+	}
+
+	ok, err := u.findWithArgs(ctx, args, query, &failures)
+	if err != nil {
+		return false, fmt.Errorf("user totp failed fail: %w", err)
+	}
+
+	return ok && failures == 0, nil
+}
+
+// ResetTOTPFailures сбрасывает счетчик неверных кодов после успешного входа.
+func (u *User) ResetTOTPFailures(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := "UPDATE users SET totp_failures = 0 WHERE id = :id"
+	args := map[string]interface{}{
+		"id": id,
+	}
+
+	if err := u.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("user reset totp failures fail: %w", err)
+	}
+
+	return nil
+}
+
+// SetBlocked блокирует или разблокирует пользователя.
+func (u *User) SetBlocked(ctx context.Context, id int, blocked bool) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := "UPDATE users SET blocked = :blocked, updated_at = CURRENT_TIMESTAMP WHERE id = :id"
+	args := map[string]interface{}{
+		"id":      id,
+		"blocked": blocked,
+	}
+
+	if err := u.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("user set blocked fail: %w", err)
+	}
+
+	return nil
+}
+
+// Search ищет пользователей, логин которых содержит pattern без учета регистра.
+func (u *User) Search(ctx context.Context, pattern string, limit int) []model.User {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var list []model.User
+	query := `
+		SELECT 
+			u.id,
+			u.login,
+			u.blocked,
+			u.created_at,
+			u.updated_at,
+			COALESCE(string_agg(r.role, ',' ORDER BY r.role), '') AS roles
+		FROM users u
+		LEFT JOIN users_roles r ON r.user_id = u.id
+		WHERE strpos(lower(u.login), lower(:pattern)) > 0
+		GROUP BY u.id
+		ORDER BY u.login
+		LIMIT :limit
+	`
+	args := map[string]interface{}{
+		"pattern": pattern,
+		"limit":   limit,
+	}
+
+	if err := u.getWithArgs(ctx, args, query, &list); err != nil {
+		u.log.Debug("user search fail: get with args fail", zap.Error(err))
+		return []model.User{}
+	}
+
+	return list
+}
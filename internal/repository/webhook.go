@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/arefev/gophermart/internal/model"
+	"go.uber.org/zap"
+)
+
+type Webhook struct {
+	log *zap.Logger
+	*Base
+}
+
+func NewWebhook(tr TxGetter, log *zap.Logger) *Webhook {
+	return &Webhook{
+		log:  log,
+		Base: NewBase(tr, log),
+	}
+}
+
+// Create сохраняет webhook. Нулевой userID означает webhook интеграции,
+// который получает события всех пользователей.
+func (wh *Webhook) Create(ctx context.Context, userID int, url, secret string, events []string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var id int
+	query := `
+		INSERT INTO webhooks(user_id, url, secret, events) 
+		VALUES(:user_id, :url, :secret, :events) 
+		RETURNING id
+	`
+	args := map[string]interface{}{
+		"user_id": sql.NullInt64{Int64: int64(userID), Valid: userID > 0},
+		"url":     url,
+		"secret":  secret,
+		"events":  model.StringList(events),
+	}
+
+	if _, err := wh.findWithArgs(ctx, args, query, &id); err != nil {
+		return 0, fmt.Errorf("webhook create fail: %w", err)
+	}
+
+	return id, nil
+}
+
+func (wh *Webhook) List(ctx context.Context) []model.Webhook {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var list []model.Webhook
+	query := "SELECT id, user_id, url, secret, events, active, created_at FROM webhooks ORDER BY id"
+
+	if err := wh.getWithArgs(ctx, map[string]interface{}{}, query, &list); err != nil {
+		wh.log.Debug("webhook list fail: get with args fail", zap.Error(err))
+		return []model.Webhook{}
+	}
+
+	return list
+}
+
+// Deactivate отключает webhook, журнал доставок сохраняется.
+func (wh *Webhook) Deactivate(ctx context.Context, id int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var updatedID int
+	query := "UPDATE webhooks SET active = false WHERE id = :id AND active RETURNING id"
+	args := map[string]interface{}{"id": id}
+
+	ok, err := wh.findWithArgs(ctx, args, query, &updatedID)
+	if err != nil {
+		return false, fmt.Errorf("webhook deactivate fail: %w", err)
+	}
+
+	return ok, nil
+}
+
+// CancelPending отменяет доставки отключенного webhook, которые еще ждут отправки.
+func (wh *Webhook) CancelPending(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := "UPDATE webhooks_deliveries SET status = :cancelled WHERE webhook_id = :id AND status = :pending"
+	args := map[string]interface{}{
+		"id":        id,
+		"cancelled": model.DeliveryStatusCancelled,
+		"pending":   model.DeliveryStatusPending,
+	}
+
+	if err := wh.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("webhook cancel pending fail: %w", err)
+	}
+
+	return nil
+}
+
+// Enqueue добавляет событие пользователя в outbox для каждого активного webhook,
+// подписанного на него: webhook пользователя или webhook интеграции без владельца.
+// Вызывается в той же транзакции, что и изменение данных.
+func (wh *Webhook) Enqueue(ctx context.Context, userID int, eventType, payload string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := `
+		INSERT INTO webhooks_deliveries(webhook_id, event_type, payload)
+		SELECT id, :event_type, CAST(:payload AS jsonb) 
+		FROM webhooks 
+		WHERE active 
+			AND (user_id IS NULL OR user_id = :user_id) 
+			AND (events = '' OR :event_type = ANY(string_to_array(events, ',')))
+	`
+	args := map[string]interface{}{
+		"user_id":    userID,
+		"event_type": eventType,
+		"payload":    payload,
+	}
+
+	if err := wh.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("webhook enqueue fail: %w", err)
+	}
+
+	return nil
+}
+
+// Claim забирает доставки активных webhook, время которых пришло, и откладывает их на lease,
+// чтобы другие реплики не отправили их повторно.
+func (wh *Webhook) Claim(ctx context.Context, limit int, lease time.Duration) []model.WebhookDelivery {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var list []model.WebhookDelivery
+	query := `
+		WITH claimed AS (
+			SELECT d.id FROM webhooks_deliveries d 
+			JOIN webhooks w ON w.id = d.webhook_id 
+			WHERE d.status = 'pending' AND d.next_attempt_at <= CURRENT_TIMESTAMP AND w.active 
+			ORDER BY d.id 
+			LIMIT :limit 
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhooks_deliveries d 
+		SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => :lease) 
+		FROM claimed c, webhooks w 
+		WHERE d.id = c.id AND w.id = d.webhook_id 
+		RETURNING 
+			d.id, d.webhook_id, d.event_type, d.payload::text AS payload, d.status, d.attempts, 
+			d.last_status_code, d.last_error, d.next_attempt_at, d.delivered_at, d.created_at, 
+			w.url, w.secret
+	`
+	args := map[string]interface{}{
+		"limit": limit,
+		"lease": lease.Seconds(),
+	}
+
+	if err := wh.getWithArgs(ctx, args, query, &list); err != nil {
+		wh.log.Debug("webhook claim fail: get with args fail", zap.Error(err))
+		return []model.WebhookDelivery{}
+	}
+
+	return list
+}
+
+func (wh *Webhook) Delivered(ctx context.Context, id int64, statusCode int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	query := `
+		UPDATE webhooks_deliveries 
+		SET status = 'delivered', attempts = attempts + 1, last_status_code = :code, 
+			last_error = NULL, delivered_at = CURRENT_TIMESTAMP 
+		WHERE id = :id
+	`
+	args := map[string]interface{}{
+		"id":   id,
+		"code": statusCode,
+	}
+
+	if err := wh.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("webhook delivered fail: %w", err)
+	}
+
+	return nil
+}
+
+// Failed сохраняет неудачную попытку. Доставка переводится в dead, если попытки закончились,
+// иначе повторяется через retryIn.
+func (wh *Webhook) Failed(
+	ctx context.Context, id int64, statusCode int, reason string, retryIn time.Duration, dead bool,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	status := model.DeliveryStatusPending
+	if dead {
+		status = model.DeliveryStatusDead
+	}
+
+	query := `
+		UPDATE webhooks_deliveries 
+		SET status = :status, attempts = attempts + 1, last_status_code = :code, last_error = :reason, 
+			next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => :retry) 
+		WHERE id = :id
+	`
+	args := map[string]interface{}{
+		"id":     id,
+		"status": status,
+		"code":   statusCode,
+		"reason": reason,
+		"retry":  retryIn.Seconds(),
+	}
+
+	if err := wh.execWithArgs(ctx, args, query); err != nil {
+		return fmt.Errorf("webhook failed fail: %w", err)
+	}
+
+	return nil
+}
+
+// Deliveries возвращает журнал доставок webhook, последние сначала.
+func (wh *Webhook) Deliveries(ctx context.Context, webhookID int, limit int) []model.WebhookDelivery {
+	ctx, cancel := context.WithTimeout(ctx, timeCancel)
+	defer cancel()
+
+	var list []model.WebhookDelivery
+	query := `
+		SELECT 
+			id, webhook_id, event_type, payload::text AS payload, status, attempts, 
+			last_status_code, last_error, next_attempt_at, delivered_at, created_at 
+		FROM webhooks_deliveries 
+		WHERE webhook_id = :webhook_id 
+		ORDER BY id DESC 
+		LIMIT :limit
+	`
+	args := map[string]interface{}{
+		"webhook_id": webhookID,
+		"limit":      limit,
+	}
+
+	if err := wh.getWithArgs(ctx, args, query, &list); err != nil {
+		wh.log.Debug("webhook deliveries fail: get with args fail", zap.Error(err))
+		return []model.WebhookDelivery{}
+	}
+
+	return list
+}
@@ -0,0 +1,46 @@
+package response
+
+import (
+	"time"
+
+	"github.com/arefev/gophermart/internal/model"
+)
+
+type APIKey struct {
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	Key        string     `json:"key,omitempty"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ID         int        `json:"id"`
+}
+
+func NewAPIKey(k *model.APIKey) APIKey {
+	key := APIKey{
+		ID:        k.ID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Scopes:    k.Scopes,
+		CreatedAt: k.CreatedAt,
+	}
+
+	if k.LastUsedAt.Valid {
+		key.LastUsedAt = &k.LastUsedAt.Time
+	}
+
+	if k.RevokedAt.Valid {
+		key.RevokedAt = &k.RevokedAt.Time
+	}
+
+	return key
+}
+
+func NewAPIKeys(l []model.APIKey) *[]APIKey {
+	keys := make([]APIKey, 0, len(l))
+	for i := range l {
+		keys = append(keys, NewAPIKey(&l[i]))
+	}
+	return &keys
+}
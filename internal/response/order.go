@@ -29,3 +29,45 @@ func NewOrders(l []model.Order) *[]Order {
 	}
 	return &orders
 }
+
+type OrderStatusChange struct {
+	ChangedAt time.Time `json:"changed_at"`
+	Status    string    `json:"status"`
+}
+
+type OrderDetail struct {
+	CheckedAt *time.Time          `json:"checked_at,omitempty"`
+	History   []OrderStatusChange `json:"history"`
+	Order
+}
+
+func NewOrderDetail(o *model.Order, history []model.OrderStatusChange) *OrderDetail {
+	d := OrderDetail{
+		Order:   NewOrder(o),
+		History: make([]OrderStatusChange, 0, len(history)),
+	}
+
+	if o.CheckedAt.Valid {
+		d.CheckedAt = &o.CheckedAt.Time
+	}
+
+	for _, h := range history {
+		d.History = append(d.History, OrderStatusChange{
+			Status:    h.Status.String(),
+			ChangedAt: h.CreatedAt,
+		})
+	}
+
+	return &d
+}
+
+type OrderBatchItem struct {
+	Number string `json:"number"`
+	Result string `json:"result"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type OrderBatch struct {
+	Results  []OrderBatchItem `json:"results"`
+	Accepted int              `json:"accepted"`
+}
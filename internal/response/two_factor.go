@@ -0,0 +1,10 @@
+package response
+
+type TwoFactorEnroll struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
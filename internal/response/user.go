@@ -0,0 +1,36 @@
+package response
+
+import (
+	"time"
+
+	"github.com/arefev/gophermart/internal/model"
+)
+
+// User пользователь для службы поддержки, без хеша пароля и секретов 2FA.
+type User struct {
+	CreatedAt time.Time `json:"created_at"`
+	Login     string    `json:"login"`
+	Roles     []string  `json:"roles"`
+	ID        int       `json:"id"`
+	TwoFactor bool      `json:"two_factor"`
+	Blocked   bool      `json:"blocked"`
+}
+
+func NewUser(u *model.User) User {
+	return User{
+		ID:        u.ID,
+		Login:     u.Login,
+		Roles:     u.Roles,
+		TwoFactor: u.TOTPEnabled,
+		Blocked:   u.Blocked,
+		CreatedAt: u.CreatedAt,
+	}
+}
+
+func NewUsers(l []model.User) *[]User {
+	users := make([]User, 0, len(l))
+	for i := range l {
+		users = append(users, NewUser(&l[i]))
+	}
+	return &users
+}
@@ -0,0 +1,46 @@
+package response
+
+import (
+	"errors"
+
+	"github.com/arefev/gophermart/internal/service/password"
+	"github.com/go-playground/validator/v10"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ValidationErrors struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func NewValidationErrors(err error) *ValidationErrors {
+	list := make([]FieldError, 0)
+
+	var vErr validator.ValidationErrors
+	if errors.As(err, &vErr) {
+		for _, fe := range vErr {
+			list = append(list, FieldError{
+				Field:   fe.Field(),
+				Code:    fe.Tag(),
+				Message: fe.Error(),
+			})
+		}
+	}
+
+	var pErr *password.PolicyError
+	if errors.As(err, &pErr) {
+		for _, v := range pErr.Violations {
+			list = append(list, FieldError{
+				Field:   pErr.Field,
+				Code:    v.Code,
+				Message: v.Message,
+			})
+		}
+	}
+
+	return &ValidationErrors{Errors: list}
+}
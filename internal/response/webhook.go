@@ -0,0 +1,74 @@
+package response
+
+import (
+	"time"
+
+	"github.com/arefev/gophermart/internal/model"
+)
+
+type Webhook struct {
+	CreatedAt time.Time `json:"created_at"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id,omitempty"`
+	Active    bool      `json:"active"`
+}
+
+type WebhookDelivery struct {
+	CreatedAt      time.Time  `json:"created_at"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	LastError      string     `json:"last_error,omitempty"`
+	ID             int64      `json:"id"`
+	Attempts       int        `json:"attempts"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+}
+
+func NewWebhook(h *model.Webhook) Webhook {
+	return Webhook{
+		ID:        h.ID,
+		URL:       h.URL,
+		Events:    h.Events,
+		UserID:    int(h.UserID.Int64),
+		Active:    h.Active,
+		CreatedAt: h.CreatedAt,
+	}
+}
+
+func NewWebhooks(l []model.Webhook) *[]Webhook {
+	hooks := make([]Webhook, 0, len(l))
+	for i := range l {
+		hooks = append(hooks, NewWebhook(&l[i]))
+	}
+	return &hooks
+}
+
+func NewWebhookDeliveries(l []model.WebhookDelivery) *[]WebhookDelivery {
+	list := make([]WebhookDelivery, 0, len(l))
+	for i := range l {
+		d := WebhookDelivery{
+			ID:             l[i].ID,
+			EventType:      l[i].EventType,
+			Status:         l[i].Status,
+			Attempts:       l[i].Attempts,
+			LastStatusCode: l[i].LastStatusCode,
+			LastError:      l[i].LastError.String,
+			CreatedAt:      l[i].CreatedAt,
+		}
+
+		if l[i].Status == model.DeliveryStatusPending {
+			d.NextAttemptAt = &l[i].NextAttemptAt
+		}
+
+		if l[i].DeliveredAt.Valid {
+			d.DeliveredAt = &l[i].DeliveredAt.Time
+		}
+
+		list = append(list, d)
+	}
+	return &list
+}
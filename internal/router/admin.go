@@ -0,0 +1,85 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/handler"
+	"github.com/arefev/gophermart/internal/middleware"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/go-chi/chi/v5"
+)
+
+func admin(app *application.App, mw *middleware.Middleware) http.Handler {
+	r := chi.NewRouter()
+	r.Use(mw.Authorized)
+	r.Use(mw.RequireRole(model.RoleAdmin, model.RoleSupport, model.RoleFinance))
+
+	adminHandler := handler.NewAdmin(app)
+
+	r.Group(func(r chi.Router) {
+		r.Use(mw.RequirePermission(model.PermissionRolesManage))
+
+		// Выдача и отзыв ролей пользователя
+		r.Post("/users/{login}/roles", adminHandler.GrantRole)
+		r.Delete("/users/{login}/roles/{role}", adminHandler.RevokeRole)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(mw.RequirePermission(model.PermissionUsersRead))
+
+		// Поиск и просмотр пользователей
+		r.Get("/users", adminHandler.SearchUsers)
+		r.Get("/users/{login}", adminHandler.User)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(mw.RequirePermission(model.PermissionBalanceRead))
+
+		// Баланс и списания любого пользователя
+		r.Get("/users/{login}/balance", adminHandler.UserBalance)
+		r.Get("/users/{login}/withdrawals", adminHandler.UserWithdrawals)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(mw.RequirePermission(model.PermissionBalanceAdjust))
+
+		// Ручное начисление и списание баллов с указанием причины
+		r.Post("/users/{login}/balance/adjustments", adminHandler.AdjustBalance)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(mw.RequirePermission(model.PermissionOrdersRead))
+
+		r.Get("/users/{login}/orders", adminHandler.UserOrders)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(mw.RequirePermission(model.PermissionOrdersWrite))
+
+		// Смена статуса заказа и внеочередная проверка в системе начислений
+		r.Put("/orders/{number}/status", adminHandler.OrderStatus)
+		r.Post("/orders/{number}/recheck", adminHandler.RecheckOrder)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(mw.RequirePermission(model.PermissionAPIKeysManage))
+
+		// Управление ключами API для магазинов
+		r.Post("/api-keys", adminHandler.CreateAPIKey)
+		r.Get("/api-keys", adminHandler.APIKeys)
+		r.Delete("/api-keys/{id}", adminHandler.RevokeAPIKey)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(mw.RequirePermission(model.PermissionWebhooksManage))
+
+		// Подписки на события и журнал доставок
+		r.Post("/webhooks", adminHandler.CreateWebhook)
+		r.Get("/webhooks", adminHandler.Webhooks)
+		r.Delete("/webhooks/{id}", adminHandler.DeleteWebhook)
+		r.Get("/webhooks/{id}/deliveries", adminHandler.WebhookDeliveries)
+	})
+
+	return r
+}
@@ -1,35 +1,79 @@
 package router
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/arefev/gophermart/internal/application"
 	"github.com/arefev/gophermart/internal/handler"
 	"github.com/arefev/gophermart/internal/middleware"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/openapi"
 	"github.com/go-chi/chi/v5"
 	chi_middleware "github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
 )
 
 func api(app *application.App, mw *middleware.Middleware) http.Handler {
 	r := chi.NewRouter()
-	r.Use(chi_middleware.AllowContentType("application/json", "text/plain"))
+	r.Use(chi_middleware.AllowContentType("application/json", "text/plain", "text/csv"))
 	r.Use(chi_middleware.SetHeader("Content-Type", "application/json"))
 
+	if app.Conf.OpenAPIValidate {
+		if validate, err := openAPIValidator(mw); err != nil {
+			app.Log.Error("OpenAPI validation disabled", zap.Error(err))
+		} else {
+			r.Use(validate)
+		}
+	}
+
 	userHandler := handler.NewUser(app)
 	orderHandler := handler.NewOrder(app)
 	balanceHandler := handler.NewBalance(app)
+	twoFactorHandler := handler.NewTwoFactor(app)
+	merchantHandler := handler.NewMerchant(app)
+	eventsHandler := handler.NewEvents(app)
+	docsHandler := handler.NewDocs(app)
+
+	// Спецификация API
+	r.Get("/openapi.json", docsHandler.OpenAPI)
 
 	r.Route("/user", func(r chi.Router) {
 		r.Post("/register", userHandler.Register)
 		r.Post("/login", userHandler.Login)
 
+		// Вход через внешнего провайдера OpenID Connect
+		r.Get("/oidc/login", userHandler.OIDCLogin)
+		r.Get("/oidc/callback", userHandler.OIDCCallback)
+
+		r.Group(func(r chi.Router) {
+			r.Use(mw.TwoFactorPending)
+
+			// Второй шаг входа с кодом 2FA или кодом восстановления
+			r.Post("/login/2fa", twoFactorHandler.Login)
+		})
+
 		r.Group(func(r chi.Router) {
 			r.Use(mw.Authorized)
 
+			// Смена пароля
+			r.Post("/password", userHandler.ChangePassword)
+
+			// Подключение двухфакторной аутентификации
+			r.Post("/2fa/enroll", twoFactorHandler.Enroll)
+			r.Post("/2fa/confirm", twoFactorHandler.Confirm)
+
 			// Сохранение номера заказа
 			r.Post("/orders", orderHandler.Create)
+			// Пакетная загрузка номеров заказов (JSON массив или CSV)
+			r.Post("/orders/batch", orderHandler.Batch)
 			// Получение списка загруженных заказов
 			r.Get("/orders", orderHandler.List)
+			// Поток изменений статусов заказов и баланса (SSE)
+			r.Get("/orders/events", eventsHandler.Stream)
+			// Получение информации о заказе и истории его статусов
+			r.Get("/orders/{number}", orderHandler.Find)
 
 			// Получение текущего баланса
 			r.Get("/balance", balanceHandler.Find)
@@ -40,5 +84,28 @@ func api(app *application.App, mw *middleware.Middleware) http.Handler {
 		})
 	})
 
+	r.Route("/merchant", func(r chi.Router) {
+		r.Use(mw.APIKey(model.ScopeOrdersCreate))
+
+		// Загрузка номера заказа магазином от имени пользователя
+		r.Post("/orders", merchantHandler.CreateOrder)
+	})
+
+	r.Mount("/admin", admin(app, mw))
+
 	return r
 }
+
+func openAPIValidator(mw *middleware.Middleware) (func(http.Handler) http.Handler, error) {
+	doc, err := openapi.Load(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("openAPIValidator: %w", err)
+	}
+
+	validate, err := mw.OpenAPI(doc)
+	if err != nil {
+		return nil, fmt.Errorf("openAPIValidator: %w", err)
+	}
+
+	return validate, nil
+}
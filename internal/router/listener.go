@@ -0,0 +1,26 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/handler"
+	"github.com/arefev/gophermart/internal/metrics"
+	"github.com/go-chi/chi/v5"
+)
+
+// NewAdminListener маршруты служебного listener, который не публикуется наружу.
+func NewAdminListener(app *application.App) *chi.Mux {
+	r := chi.NewRouter()
+
+	healthHandler := handler.NewHealth(app)
+
+	// Проверки для оркестратора: процесс жив и готов принимать запросы
+	r.Get("/healthz", healthHandler.Live)
+	r.Get("/readyz", healthHandler.Ready)
+
+	// Метрики prometheus
+	r.Method(http.MethodGet, "/metrics", metrics.Handler())
+
+	return r
+}
@@ -12,7 +12,9 @@ func New(app *application.App) *chi.Mux {
 
 	mw := middleware.NewMiddleware(app)
 	r := chi.NewRouter()
-	r.Use(chi_middleware.Logger)
+	r.Use(mw.AccessLog)
+	r.Use(mw.Tracing)
+	r.Use(mw.Metrics)
 	r.Use(chi_middleware.Compress(compressLevel, "application/json", "text/html"))
 
 	app.Log.Info("Server started")
@@ -0,0 +1,91 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/rpc/pb"
+	"github.com/arefev/gophermart/internal/service"
+	"github.com/arefev/gophermart/internal/service/jwt"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods методы, доступные без токена.
+var publicMethods = map[string]bool{
+	pb.Gophermart_Register_FullMethodName: true,
+	pb.Gophermart_Login_FullMethodName:    true,
+}
+
+// methodScopes области токена для методов, которые принимают не обычный токен доступа.
+var methodScopes = map[string]string{
+	pb.Gophermart_LoginTwoFactor_FullMethodName: jwt.ScopeTwoFactor,
+}
+
+type auth struct {
+	app *application.App
+}
+
+func (a *auth) unary(
+	ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (any, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	ctx, err := a.authorize(ctx, methodScopes[info.FullMethod])
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (a *auth) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authorize(ss.Context(), methodScopes[info.FullMethod])
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authorize проверяет токен с областью scope из метаданных authorization: Bearer <token>
+// и кладет пользователя в контекст так же, как HTTP middleware.
+func (a *auth) authorize(ctx context.Context, scope string) (context.Context, error) {
+	unauthenticated := status.Error(codes.Unauthenticated, service.ErrUserNotAuthorized.Error())
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md.Get("authorization")) == 0 {
+		a.app.Log.Debug("metadata authorization not found")
+		return nil, unauthenticated
+	}
+
+	values := strings.Split(md.Get("authorization")[0], " ")
+	if len(values) != 2 || values[0] != "Bearer" {
+		a.app.Log.Debug("get token from metadata fail")
+		return nil, unauthenticated
+	}
+
+	user, err := service.NewUserService(a.app).Authenticate(ctx, values[1], scope)
+	if err != nil {
+		a.app.Log.Debug("authenticate fail", zap.Error(err))
+		return nil, unauthenticated
+	}
+
+	return context.WithValue(ctx, model.UserCtxKey{}, user), nil
+}
+
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
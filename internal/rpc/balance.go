@@ -0,0 +1,74 @@
+package rpc
+
+import (
+	"context"
+
+	w_action "github.com/arefev/gophermart/internal/action/withdrawal"
+	"github.com/arefev/gophermart/internal/rpc/pb"
+	"github.com/arefev/gophermart/internal/service"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *server) GetBalance(ctx context.Context, _ *pb.GetBalanceRequest) (*pb.Balance, error) {
+	user, err := service.NewUserService(s.app).Authorized(ctx)
+	if err != nil {
+		return nil, s.statusError(err, "Get balance rpc")
+	}
+
+	balance, err := service.NewBalanceService(s.app).FindByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, s.statusError(err, "Get balance rpc")
+	}
+
+	return &pb.Balance{
+		Current:   balance.Current,
+		Withdrawn: balance.Withdrawn,
+	}, nil
+}
+
+func (s *server) Withdraw(ctx context.Context, req *pb.WithdrawRequest) (*pb.WithdrawResponse, error) {
+	user, err := service.NewUserService(s.app).Authorized(ctx)
+	if err != nil {
+		return nil, s.statusError(err, "Withdraw rpc")
+	}
+
+	err = w_action.NewCreateAction(s.app).Withdraw(ctx, user, &w_action.CreateRequest{
+		Order: req.GetOrder(),
+		Sum:   req.GetSum(),
+	})
+	if err != nil {
+		return nil, s.statusError(err, "Withdraw rpc")
+	}
+
+	return &pb.WithdrawResponse{}, nil
+}
+
+func (s *server) ListWithdrawals(
+	ctx context.Context, req *pb.ListWithdrawalsRequest,
+) (*pb.ListWithdrawalsResponse, error) {
+	user, err := service.NewUserService(s.app).Authorized(ctx)
+	if err != nil {
+		return nil, s.statusError(err, "List withdrawals rpc")
+	}
+
+	q := listQuery(req.GetLimit(), req.GetCursor(), req.GetSort())
+	list, next, err := w_action.NewListAction(s.app).List(ctx, user, q)
+	if err != nil {
+		return nil, s.statusError(err, "List withdrawals rpc")
+	}
+
+	res := &pb.ListWithdrawalsResponse{
+		Withdrawals: make([]*pb.Withdrawal, 0, len(list)),
+		NextCursor:  next,
+	}
+
+	for i := range list {
+		res.Withdrawals = append(res.Withdrawals, &pb.Withdrawal{
+			Order:       list[i].Number,
+			Sum:         list[i].Sum,
+			ProcessedAt: timestamppb.New(list[i].ProcessedAt),
+		})
+	}
+
+	return res, nil
+}
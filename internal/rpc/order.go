@@ -0,0 +1,169 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+
+	action "github.com/arefev/gophermart/internal/action/order"
+	"github.com/arefev/gophermart/internal/events"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/rpc/pb"
+	"github.com/arefev/gophermart/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *server) UploadOrder(ctx context.Context, req *pb.UploadOrderRequest) (*pb.UploadOrderResponse, error) {
+	user, err := service.NewUserService(s.app).Authorized(ctx)
+	if err != nil {
+		return nil, s.statusError(err, "Upload order rpc")
+	}
+
+	err = action.NewCreateAction(s.app).Create(ctx, user, req.GetNumber())
+	if errors.Is(err, action.ErrOrderCreateUploadedByCurrentUser) {
+		return &pb.UploadOrderResponse{AlreadyUploaded: true}, nil
+	}
+
+	if err != nil {
+		return nil, s.statusError(err, "Upload order rpc")
+	}
+
+	return &pb.UploadOrderResponse{}, nil
+}
+
+func (s *server) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	user, err := service.NewUserService(s.app).Authorized(ctx)
+	if err != nil {
+		return nil, s.statusError(err, "List orders rpc")
+	}
+
+	q := listQuery(req.GetLimit(), req.GetCursor(), req.GetSort())
+	if len(req.GetStatuses()) > 0 {
+		q.Set("status", strings.Join(req.GetStatuses(), ","))
+	}
+
+	orders, next, err := action.NewListAction(s.app).List(ctx, user, q)
+	if err != nil {
+		return nil, s.statusError(err, "List orders rpc")
+	}
+
+	res := &pb.ListOrdersResponse{
+		Orders:     make([]*pb.Order, 0, len(orders)),
+		NextCursor: next,
+	}
+
+	for i := range orders {
+		res.Orders = append(res.Orders, &pb.Order{
+			Number:     orders[i].Number,
+			Status:     orders[i].Status.String(),
+			Accrual:    orders[i].Accrual.Float64,
+			UploadedAt: timestamppb.New(orders[i].UploadedAt),
+		})
+	}
+
+	return res, nil
+}
+
+// WatchOrders отправляет изменения статусов заказов пользователя, как SSE поток HTTP API.
+func (s *server) WatchOrders(req *pb.WatchOrdersRequest, stream grpc.ServerStreamingServer[pb.OrderEvent]) error {
+	ctx := stream.Context()
+	user, err := service.NewUserService(s.app).Authorized(ctx)
+	if err != nil {
+		return s.statusError(err, "Watch orders rpc")
+	}
+
+	if s.app.Events == nil {
+		return status.Error(codes.Unavailable, "events unavailable")
+	}
+
+	// Подписка раньше чтения из БД, чтобы не потерять события между ними
+	ch, unsubscribe := s.app.Events.Subscribe(user.ID)
+	defer unsubscribe()
+
+	// lastID граница, заданная клиентом; дальше повторы отсекаются по набору ID
+	lastID := req.GetLastEventId()
+	sent := events.NewRecent()
+	if lastID > 0 {
+		missed, err := service.NewEventService(s.app).After(ctx, user.ID, lastID)
+		if err != nil {
+			return s.statusError(err, "Watch orders rpc")
+		}
+
+		for i := range missed {
+			if err := sendOrderEvent(stream, &missed[i]); err != nil {
+				return err
+			}
+			sent.Add(missed[i].ID)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.shutdown:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case ev, ok := <-ch:
+			if !ok {
+				select {
+				case <-s.shutdown:
+					return status.Error(codes.Unavailable, "server is shutting down")
+				default:
+				}
+
+				return status.Error(codes.ResourceExhausted, "events subscriber is too slow")
+			}
+
+			if ev.ID <= lastID || !sent.Add(ev.ID) {
+				continue
+			}
+
+			if err := sendOrderEvent(stream, &ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func sendOrderEvent(stream grpc.ServerStreamingServer[pb.OrderEvent], ev *model.Event) error {
+	if ev.Type != model.EventOrder {
+		return nil
+	}
+
+	var payload events.OrderPayload
+	if err := json.Unmarshal([]byte(ev.Payload), &payload); err != nil {
+		return status.Error(codes.Internal, "decode order event fail")
+	}
+
+	return stream.Send(&pb.OrderEvent{
+		Id:        ev.ID,
+		Number:    payload.Number,
+		Status:    payload.Status,
+		Accrual:   payload.Accrual,
+		CreatedAt: timestamppb.New(ev.CreatedAt),
+	})
+}
+
+// listQuery собирает параметры списка в том же виде, что и query HTTP API.
+func listQuery(limit int32, cursor, sort string) url.Values {
+	q := url.Values{}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(int(limit)))
+	}
+
+	if cursor != "" {
+		q.Set("cursor", cursor)
+	}
+
+	if sort != "" {
+		q.Set("sort", sort)
+	}
+
+	return q
+}
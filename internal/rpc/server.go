@@ -0,0 +1,89 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/rpc/pb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const defaultShutdownTimeout = 30 * time.Second
+
+type server struct {
+	pb.UnimplementedGophermartServer
+	app *application.App
+	// shutdown закрывается при остановке сервера, потоки завершаются по нему
+	shutdown <-chan struct{}
+}
+
+// New создает gRPC сервер с теми же действиями и сервисами, что и HTTP API.
+func New(app *application.App) *grpc.Server {
+	return newServer(app, nil)
+}
+
+func newServer(app *application.App, shutdown <-chan struct{}, opts ...grpc.ServerOption) *grpc.Server {
+	a := &auth{app: app}
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(a.unary),
+		grpc.ChainStreamInterceptor(a.stream),
+	)
+	s := grpc.NewServer(opts...)
+	pb.RegisterGophermartServer(s, &server{app: app, shutdown: shutdown})
+
+	return s
+}
+
+// Serve обслуживает gRPC запросы на address до отмены ctx. Если tlsConf задан, используется
+// тот же TLS, что и у HTTP API. При остановке открытые потоки WatchOrders завершаются сразу,
+// остальные запросы ждут не дольше ShutdownTimeout.
+func Serve(ctx context.Context, app *application.App, address string, tlsConf *tls.Config) error {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("grpc listen fail: %w", err)
+	}
+
+	var opts []grpc.ServerOption
+	if tlsConf != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConf)))
+	}
+
+	s := newServer(app, ctx.Done(), opts...)
+	go func() {
+		<-ctx.Done()
+		stop(app, s)
+	}()
+
+	if err := s.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return fmt.Errorf("grpc serve fail: %w", err)
+	}
+
+	return nil
+}
+
+func stop(app *application.App, s *grpc.Server) {
+	timeout := time.Duration(app.Conf.ShutdownTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		app.Log.Error("grpc server stopped before requests finished", zap.Duration("timeout", timeout))
+		s.Stop()
+	}
+}
@@ -0,0 +1,62 @@
+package rpc
+
+import (
+	"errors"
+
+	o_action "github.com/arefev/gophermart/internal/action/order"
+	w_action "github.com/arefev/gophermart/internal/action/withdrawal"
+	"github.com/arefev/gophermart/internal/service"
+	"github.com/arefev/gophermart/internal/service/alg"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusCodes коды gRPC для ошибок действий, как статусы HTTP обработчиков.
+var statusCodes = []struct {
+	errs []error
+	code codes.Code
+}{
+	{
+		code: codes.InvalidArgument,
+		errs: []error{
+			service.ErrRegisterValidateFail,
+			service.ErrAuthValidateFail,
+			o_action.ErrOrderCreateValidateFail,
+			w_action.ErrValidationWithdrawal,
+			alg.ErrLuhnInvalid,
+			service.ErrListQueryInvalid,
+			service.ErrTwoFactorValidateFail,
+		},
+	},
+	{code: codes.AlreadyExists, errs: []error{service.ErrRegisterUserExists, o_action.ErrOrderCreateUploadedByOtherUser}},
+	{
+		code: codes.Unauthenticated,
+		errs: []error{
+			service.ErrAuthUserNotFound,
+			service.ErrUserNotAuthorized,
+			service.ErrTwoFactorInvalidCode,
+			service.ErrTwoFactorNotEnrolled,
+		},
+	},
+	{code: codes.PermissionDenied, errs: []error{service.ErrUserBlocked}},
+	{code: codes.ResourceExhausted, errs: []error{service.ErrTwoFactorLocked}},
+	{code: codes.FailedPrecondition, errs: []error{w_action.ErrNotEnoughBalance}},
+	{code: codes.NotFound, errs: []error{w_action.ErrOrderNotFound}},
+}
+
+// statusError переводит ошибки действий в коды gRPC так же, как HTTP обработчики в статусы.
+// Клиенту уходит только текст sentinel ошибки, цепочка остается в логе.
+func (s *server) statusError(err error, msg string) error {
+	for _, sc := range statusCodes {
+		for _, e := range sc.errs {
+			if errors.Is(err, e) {
+				s.app.Log.Debug(msg, zap.Error(err))
+				return status.Error(sc.code, e.Error())
+			}
+		}
+	}
+
+	s.app.Log.Error(msg, zap.Error(err))
+	return status.Error(codes.Internal, "internal error")
+}
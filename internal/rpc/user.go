@@ -0,0 +1,58 @@
+package rpc
+
+import (
+	"context"
+
+	tf_action "github.com/arefev/gophermart/internal/action/twofactor"
+	action "github.com/arefev/gophermart/internal/action/user"
+	"github.com/arefev/gophermart/internal/rpc/pb"
+	"github.com/arefev/gophermart/internal/service"
+	"github.com/arefev/gophermart/internal/service/jwt"
+)
+
+func (s *server) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.AuthResponse, error) {
+	user := action.UserCreateRequest{
+		Login:    req.GetLogin(),
+		Password: req.GetPassword(),
+	}
+
+	if err := action.NewRegisterAction(s.app).Register(ctx, &user); err != nil {
+		return nil, s.statusError(err, "Register user rpc")
+	}
+
+	token, err := service.NewUserService(s.app).Authorize(ctx, user.Login, user.Password)
+	if err != nil {
+		return nil, s.statusError(err, "Register user rpc authorize")
+	}
+
+	return newAuthResponse(token), nil
+}
+
+func (s *server) Login(ctx context.Context, req *pb.LoginRequest) (*pb.AuthResponse, error) {
+	token, err := action.NewAuthAction(s.app).Login(ctx, &action.UserAuthRequest{
+		Login:    req.GetLogin(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		return nil, s.statusError(err, "Login user rpc")
+	}
+
+	return newAuthResponse(token), nil
+}
+
+// LoginTwoFactor второй шаг входа для пользователя с 2FA, как POST /api/user/login/2fa.
+func (s *server) LoginTwoFactor(ctx context.Context, req *pb.LoginTwoFactorRequest) (*pb.AuthResponse, error) {
+	token, err := tf_action.NewLoginAction(s.app).Login(ctx, &tf_action.CodeRequest{Code: req.GetCode()})
+	if err != nil {
+		return nil, s.statusError(err, "Login two factor rpc")
+	}
+
+	return newAuthResponse(token), nil
+}
+
+func newAuthResponse(token *jwt.Token) *pb.AuthResponse {
+	return &pb.AuthResponse{
+		AccessToken:       token.AccessToken,
+		TwoFactorRequired: token.Scope == jwt.ScopeTwoFactor,
+	}
+}
@@ -2,6 +2,8 @@ package alg
 
 import "errors"
 
+var ErrLuhnInvalid = errors.New("number failed luhn check")
+
 func CheckLuhn(number string) error {
 	const numForParity = 2
 	const numSubtract = 9
@@ -24,7 +26,7 @@ func CheckLuhn(number string) error {
 	}
 
 	if sum%10 != 0 {
-		return errors.New("number is not valid")
+		return ErrLuhnInvalid
 	}
 
 	return nil
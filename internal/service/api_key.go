@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/model"
+	"go.uber.org/zap"
+)
+
+const (
+	apiKeyPrefix       = "gm"
+	apiKeyIDLength     = 5
+	apiKeySecretLength = 20
+)
+
+var (
+	ErrAPIKeyInvalid        = errors.New("api key invalid")
+	ErrAPIKeyNotFound       = errors.New("api key not found")
+	ErrAPIKeyUnknownScope   = errors.New("api key unknown scope")
+	ErrAPIKeyJSONDecodeFail = errors.New("json decode fail")
+	ErrAPIKeyValidateFail   = errors.New("validate fail")
+)
+
+var apiKeyEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type apiKeyService struct {
+	app *application.App
+}
+
+func NewAPIKeyService(app *application.App) *apiKeyService {
+	return &apiKeyService{
+		app: app,
+	}
+}
+
+// Create возвращает ключ в открытом виде, в БД сохраняется только его хеш.
+// Ключ имеет вид gm_<prefix>_<secret>, prefix используется для поиска ключа.
+func (aks *apiKeyService) Create(ctx context.Context, name string, scopes []string) (string, *model.APIKey, error) {
+	for _, scope := range scopes {
+		if !model.APIKeyScopeExists(scope) {
+			return "", nil, fmt.Errorf("%w: %s", ErrAPIKeyUnknownScope, scope)
+		}
+	}
+
+	prefix, err := randomString(apiKeyIDLength)
+	if err != nil {
+		return "", nil, fmt.Errorf("api key generate prefix fail: %w", err)
+	}
+
+	secret, err := randomString(apiKeySecretLength)
+	if err != nil {
+		return "", nil, fmt.Errorf("api key generate secret fail: %w", err)
+	}
+
+	plain := apiKeyPrefix + "_" + prefix + "_" + secret
+	key := model.APIKey{
+		Name:    name,
+		Prefix:  prefix,
+		KeyHash: hashAPIKey(plain),
+		Scopes:  scopes,
+	}
+
+	err = aks.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		id, err := aks.app.Rep.APIKey.Create(ctx, key.Name, key.Prefix, key.KeyHash, key.Scopes)
+		key.ID = id
+		return err
+	})
+
+	if err != nil {
+		return "", nil, fmt.Errorf("api key create transaction fail: %w", err)
+	}
+
+	return plain, &key, nil
+}
+
+func (aks *apiKeyService) Authenticate(ctx context.Context, plain string) (*model.APIKey, error) {
+	parts := strings.Split(plain, "_")
+	if len(parts) != 3 || parts[0] != apiKeyPrefix {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	var key *model.APIKey
+	err := aks.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		var ok bool
+		key, ok = aks.app.Rep.APIKey.FindByPrefix(ctx, parts[1])
+		if !ok {
+			return ErrAPIKeyInvalid
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("api key authenticate transaction fail: %w", err)
+	}
+
+	if key.Revoked() || subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashAPIKey(plain))) != 1 {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	err = aks.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		return aks.app.Rep.APIKey.TouchLastUsed(ctx, key.ID)
+	})
+
+	if err != nil {
+		aks.app.Logger(ctx).Warn("api key touch last used fail", zap.Error(err))
+	}
+
+	return key, nil
+}
+
+func (aks *apiKeyService) List(ctx context.Context) ([]model.APIKey, error) {
+	var list []model.APIKey
+	err := aks.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		list = aks.app.Rep.APIKey.List(ctx)
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("api key list transaction fail: %w", err)
+	}
+
+	return list, nil
+}
+
+func (aks *apiKeyService) Revoke(ctx context.Context, id int) error {
+	err := aks.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		ok, err := aks.app.Rep.APIKey.Revoke(ctx, id)
+		if err != nil {
+			return fmt.Errorf("revoke fail: %w", err)
+		}
+
+		if !ok {
+			return ErrAPIKeyNotFound
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("api key revoke transaction fail: %w", err)
+	}
+
+	return nil
+}
+
+func (aks *apiKeyService) Authorized(ctx context.Context) (*model.APIKey, error) {
+	key, ok := ctx.Value(model.APIKeyCtxKey{}).(*model.APIKey)
+	if !ok {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	return key, nil
+}
+
+func randomString(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("read random fail: %w", err)
+	}
+
+	return strings.ToLower(apiKeyEncoding.EncodeToString(b)), nil
+}
+
+func hashAPIKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/model"
+)
+
+const eventReplayLimit = 500
+
+type eventService struct {
+	app *application.App
+}
+
+func NewEventService(app *application.App) *eventService {
+	return &eventService{
+		app: app,
+	}
+}
+
+// After возвращает события пользователя, пропущенные после события с id (для Last-Event-ID).
+func (es *eventService) After(ctx context.Context, userID int, id int64) ([]model.Event, error) {
+	var list []model.Event
+
+	for {
+		var page []model.Event
+		err := es.app.TrManager.Do(ctx, func(ctx context.Context) error {
+			page = es.app.Rep.Event.After(ctx, userID, id, eventReplayLimit)
+			return nil
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("events after transaction fail: %w", err)
+		}
+
+		list = append(list, page...)
+		if len(page) < eventReplayLimit {
+			return list, nil
+		}
+
+		id = page[len(page)-1].ID
+	}
+}
@@ -15,8 +15,11 @@ type JWT struct {
 	secret string
 }
 
+const ScopeTwoFactor = "2fa"
+
 type Token struct {
 	AccessToken string `json:"accessToken"`
+	Scope       string `json:"scope,omitempty"`
 	Exp         int64  `json:"exp"`
 }
 
@@ -27,19 +30,35 @@ func NewToken(secret string) *JWT {
 }
 
 func (j *JWT) GenerateToken(user *model.User, duration int) (*Token, error) {
-	d := time.Minute * time.Duration(duration)
-	exp := time.Now().Add(d).Unix()
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	return j.GenerateScopedToken(user, duration, "")
+}
+
+// GenerateScopedToken создает токен с ограниченной областью действия.
+// Пустой scope означает полный доступ.
+func (j *JWT) GenerateScopedToken(user *model.User, duration int, scope string) (*Token, error) {
+	now := time.Now()
+	exp := now.Add(time.Minute * time.Duration(duration)).Unix()
+	claims := jwt.MapClaims{
 		"login": user.Login,
 		"exp":   exp,
-	})
+		"iat":   now.Unix(),
+	}
+
+	if scope != "" {
+		claims["scope"] = scope
+	}
+
+	if len(user.Roles) > 0 {
+		claims["roles"] = []string(user.Roles)
+	}
 
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	strToken, err := token.SignedString([]byte(j.secret))
 	if err != nil {
 		return nil, fmt.Errorf("generate token fail: %w", err)
 	}
 
-	return &Token{AccessToken: strToken, Exp: exp}, nil
+	return &Token{AccessToken: strToken, Scope: scope, Exp: exp}, nil
 }
 
 func (j *JWT) Parse(tokenStr string) *JWT {
@@ -86,6 +105,70 @@ func (j *JWT) GetLogin() (string, error) {
 	return login, nil
 }
 
+func (j *JWT) GetScope() (string, error) {
+	if err := j.checkErr(); err != nil {
+		return "", fmt.Errorf("get scope fail: %w", err)
+	}
+
+	value, ok := j.claims["scope"]
+	if !ok {
+		return "", nil
+	}
+
+	scope, ok := value.(string)
+	if !ok {
+		return "", errors.New("scope is not a string")
+	}
+
+	return scope, nil
+}
+
+func (j *JWT) GetRoles() ([]string, error) {
+	if err := j.checkErr(); err != nil {
+		return nil, fmt.Errorf("get roles fail: %w", err)
+	}
+
+	value, ok := j.claims["roles"]
+	if !ok {
+		return []string{}, nil
+	}
+
+	list, ok := value.([]any)
+	if !ok {
+		return nil, errors.New("roles is not a list")
+	}
+
+	roles := make([]string, 0, len(list))
+	for _, v := range list {
+		role, ok := v.(string)
+		if !ok {
+			return nil, errors.New("role is not a string")
+		}
+
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
+
+// GetIssuedAt время выдачи токена, нулевое время, если токен выдан без iat.
+func (j *JWT) GetIssuedAt() (time.Time, error) {
+	if err := j.checkErr(); err != nil {
+		return time.Time{}, fmt.Errorf("get issued at fail: %w", err)
+	}
+
+	iat, err := j.claims.GetIssuedAt()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("get issued at fail: %w", err)
+	}
+
+	if iat == nil {
+		return time.Time{}, nil
+	}
+
+	return iat.Time, nil
+}
+
 func (j *JWT) checkErr() error {
 	if j.err != nil {
 		return j.err
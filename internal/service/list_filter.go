@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arefev/gophermart/internal/model"
+)
+
+const (
+	ListDefaultLimit = 20
+	ListMaxLimit     = 100
+)
+
+var ErrListQueryInvalid = errors.New("list query invalid")
+
+var listParams = []string{"limit", "cursor", "status", "from", "to", "sort"}
+
+// ParseListFilter разбирает параметры постраничного списка.
+// Если ни один параметр не передан, возвращается nil: список отдается целиком, как раньше.
+func ParseListFilter(q url.Values, withStatus bool) (*model.ListFilter, error) {
+	present := false
+	for _, p := range listParams {
+		if q.Has(p) {
+			present = true
+			break
+		}
+	}
+
+	if !present {
+		return nil, nil
+	}
+
+	filter := model.ListFilter{Limit: ListDefaultLimit}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > ListMaxLimit {
+			return nil, fmt.Errorf("%w: limit must be in 1..%d", ErrListQueryInvalid, ListMaxLimit)
+		}
+
+		filter.Limit = limit
+	}
+
+	if v := q.Get("cursor"); v != "" {
+		cursor, err := model.DecodeCursor(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrListQueryInvalid, err)
+		}
+
+		filter.Cursor = cursor
+	}
+
+	switch q.Get("sort") {
+	case "", "desc":
+	case "asc":
+		filter.Asc = true
+	default:
+		return nil, fmt.Errorf("%w: sort must be asc or desc", ErrListQueryInvalid)
+	}
+
+	for name, t := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		v := q.Get(name)
+		if v == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be RFC3339", ErrListQueryInvalid, name)
+		}
+
+		*t = parsed
+	}
+
+	if v := q.Get("status"); v != "" {
+		if !withStatus {
+			return nil, fmt.Errorf("%w: status filter is not supported", ErrListQueryInvalid)
+		}
+
+		for _, s := range strings.Split(v, ",") {
+			s = strings.ToUpper(strings.TrimSpace(s))
+			status := model.OrderStatusFromString(s)
+			if status.String() != s {
+				return nil, fmt.Errorf("%w: unknown status %s", ErrListQueryInvalid, s)
+			}
+
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	return &filter, nil
+}
+
+// NextPage обрезает выборку из limit+1 записей до limit и возвращает курсор следующей страницы.
+func NextPage[T any](list []T, limit int, cursor func(*T) model.Cursor) ([]T, string) {
+	if len(list) <= limit {
+		return list, ""
+	}
+
+	list = list[:limit]
+	return list, cursor(&list[limit-1]).Encode()
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/outbox"
+	"github.com/arefev/gophermart/internal/service/jwt"
+	"github.com/arefev/gophermart/internal/service/oidc"
+)
+
+const (
+	// OIDCStateTTL время жизни состояния входа через провайдера
+	OIDCStateTTL = 10 * time.Minute
+
+	// oidcLoginRules правила логина, совпадают с /api/user/register
+	oidcLoginRules = "required,gte=1,lte=20,alphanum"
+	oidcLoginMax   = 20
+)
+
+var (
+	ErrOIDCDisabled      = errors.New("oidc login disabled")
+	ErrOIDCStateMismatch = errors.New("oidc state mismatch")
+	ErrOIDCLoginTaken    = errors.New("oidc login already taken")
+	ErrOIDCDenied        = errors.New("oidc login denied")
+)
+
+type oidcService struct {
+	app *application.App
+}
+
+func NewOIDCService(app *application.App) *oidcService {
+	return &oidcService{
+		app: app,
+	}
+}
+
+// Begin возвращает адрес входа у провайдера и подписанное состояние для cookie.
+func (ocs *oidcService) Begin(ctx context.Context) (string, string, error) {
+	provider, err := ocs.provider(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	st, err := oidc.NewState(OIDCStateTTL)
+	if err != nil {
+		return "", "", fmt.Errorf("oidc begin fail: %w", err)
+	}
+
+	cookie, err := st.Encode(ocs.app.Conf.TokenSecret)
+	if err != nil {
+		return "", "", fmt.Errorf("oidc begin fail: %w", err)
+	}
+
+	return provider.AuthCodeURL(st.State, st.Nonce, st.Verifier), cookie, nil
+}
+
+// Complete проверяет state, обменивает код на ID токен и выдает токен gophermart
+// пользователю, связанному с учетной записью провайдера.
+func (ocs *oidcService) Complete(ctx context.Context, cookie, state, code string) (*jwt.Token, error) {
+	provider, err := ocs.provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := oidc.DecodeState(ocs.app.Conf.TokenSecret, cookie)
+	if err != nil {
+		return nil, fmt.Errorf("oidc complete %w: %w", ErrOIDCStateMismatch, err)
+	}
+
+	if state == "" || st.State != state {
+		return nil, ErrOIDCStateMismatch
+	}
+
+	raw, err := provider.Exchange(ctx, code, st.Verifier)
+	if err != nil {
+		return nil, fmt.Errorf("oidc complete %w: %w", ErrOIDCDenied, err)
+	}
+
+	claims, err := provider.Verify(ctx, raw, st.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("oidc complete %w: %w", ErrOIDCDenied, err)
+	}
+
+	user, err := ocs.linkOrCreate(ctx, provider.Issuer(), claims)
+	if err != nil {
+		return nil, fmt.Errorf("oidc complete fail: %w", err)
+	}
+
+	// Вход через провайдера не заменяет второй фактор
+	if user.TOTPEnabled {
+		return NewUserService(ocs.app).twoFactorToken(user)
+	}
+
+	token, err := jwt.NewToken(ocs.app.Conf.TokenSecret).GenerateToken(user, ocs.app.Conf.TokenDuration)
+	if err != nil {
+		return nil, fmt.Errorf("oidc complete generate token fail: %w", err)
+	}
+
+	return token, nil
+}
+
+// linkOrCreate находит пользователя по связке issuer+subject, иначе создает нового
+// со случайным паролем. Локальные логины не подтверждаются, поэтому существующий
+// пользователь связывается только при включенной OIDCLinkExisting и только если
+// его логин получен из подтвержденного email.
+func (ocs *oidcService) linkOrCreate(ctx context.Context, issuer string, claims *oidc.Claims) (*model.User, error) {
+	var user *model.User
+
+	err := ocs.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		if login, ok := ocs.app.Rep.Identity.FindLogin(ctx, issuer, claims.Subject); ok {
+			if user, ok = ocs.app.Rep.User.FindByLogin(ctx, login); !ok {
+				return ErrAuthUserNotFound
+			}
+
+			if user.Blocked {
+				return ErrUserBlocked
+			}
+
+			return nil
+		}
+
+		login, fromEmail := oidcLogin(claims)
+		if err := NewValidator().Var(login, oidcLoginRules); err != nil {
+			return fmt.Errorf("oidc login %q invalid: %w", login, err)
+		}
+
+		user, _ = ocs.app.Rep.User.FindByLogin(ctx, login)
+
+		switch {
+		case user != nil && (!ocs.app.Conf.OIDCLinkExisting || !fromEmail):
+			return ErrOIDCLoginTaken
+		case user == nil:
+			var err error
+			if user, err = ocs.create(ctx, login); err != nil {
+				return err
+			}
+		}
+
+		if err := ocs.app.Rep.Identity.Link(ctx, user.ID, issuer, claims.Subject); err != nil {
+			return fmt.Errorf("link identity fail: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("oidc link transaction fail: %w", err)
+	}
+
+	return user, nil
+}
+
+func (ocs *oidcService) create(ctx context.Context, login string) (*model.User, error) {
+	hasher, err := NewUserService(ocs.app).hasher()
+	if err != nil {
+		return nil, fmt.Errorf("init hasher fail: %w", err)
+	}
+
+	pwd, err := oidc.Random()
+	if err != nil {
+		return nil, fmt.Errorf("generate password fail: %w", err)
+	}
+
+	pwdHash, err := hasher.Hash(pwd)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt password fail: %w", err)
+	}
+
+	if err := ocs.app.Rep.User.Create(ctx, login, pwdHash); err != nil {
+		return nil, fmt.Errorf("create user fail: %w", err)
+	}
+
+	event := outbox.UserRegistered{Login: login, Source: "oidc"}
+	if err := outbox.Emit(ctx, ocs.app, model.OutboxUserRegistered, event); err != nil {
+		return nil, err
+	}
+
+	user, ok := ocs.app.Rep.User.FindByLogin(ctx, login)
+	if !ok {
+		return nil, ErrAuthUserNotFound
+	}
+
+	return user, nil
+}
+
+func (ocs *oidcService) provider(ctx context.Context) (*oidc.Provider, error) {
+	conf := ocs.app.Conf
+	if conf.OIDCIssuer == "" {
+		return nil, ErrOIDCDisabled
+	}
+
+	provider, err := oidc.Get(ctx, oidc.Config{
+		Issuer:       conf.OIDCIssuer,
+		ClientID:     conf.OIDCClientID,
+		ClientSecret: conf.OIDCSecret,
+		RedirectURL:  conf.OIDCRedirect,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("oidc provider fail: %w", err)
+	}
+
+	return provider, nil
+}
+
+// oidcLogin выводит логин из подтвержденного email, preferred_username или subject.
+// Логин приводится к правилам регистрации: только латинские буквы и цифры, не длиннее 20.
+// Второе значение сообщает, что логин получен из подтвержденного email.
+func oidcLogin(claims *oidc.Claims) (string, bool) {
+	if claims.EmailVerified {
+		if login := sanitizeLogin(claims.Email); login != "" {
+			return login, true
+		}
+	}
+
+	if login := sanitizeLogin(claims.PreferredUsername); login != "" {
+		return login, false
+	}
+
+	return sanitizeLogin("oidc" + strings.ToLower(claims.Subject)), false
+}
+
+func sanitizeLogin(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if b.Len() == oidcLoginMax {
+			break
+		}
+
+		if r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
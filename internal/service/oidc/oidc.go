@@ -0,0 +1,281 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const discoveryPath = "/.well-known/openid-configuration"
+
+var (
+	ErrDiscoveryFail = errors.New("oidc discovery fail")
+	ErrExchangeFail  = errors.New("oidc code exchange fail")
+	ErrTokenInvalid  = errors.New("oidc id token invalid")
+)
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type Claims struct {
+	jwt.RegisteredClaims
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	PreferredUsername string `json:"preferred_username"`
+	EmailVerified     bool   `json:"email_verified"`
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+type tokenResponse struct {
+	IDToken string `json:"id_token"`
+}
+
+type Provider struct {
+	keys   map[string]*rsa.PublicKey
+	client *resty.Client
+	disc   Discovery
+	conf   Config
+	mu     sync.RWMutex
+}
+
+var providers sync.Map
+
+// Get возвращает провайдера для конфигурации, документ discovery
+// запрашивается один раз и кешируется.
+func Get(ctx context.Context, conf Config) (*Provider, error) {
+	key := conf.Issuer + "|" + conf.ClientID
+	if p, ok := providers.Load(key); ok {
+		if p, ok := p.(*Provider); ok {
+			return p, nil
+		}
+	}
+
+	p, err := NewProvider(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	providers.Store(key, p)
+	return p, nil
+}
+
+func NewProvider(ctx context.Context, conf Config) (*Provider, error) {
+	p := &Provider{
+		conf:   conf,
+		client: resty.New(),
+		keys:   map[string]*rsa.PublicKey{},
+	}
+
+	resp, err := p.client.R().
+		SetContext(ctx).
+		SetResult(&p.disc).
+		Get(strings.TrimSuffix(conf.Issuer, "/") + discoveryPath)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDiscoveryFail, err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrDiscoveryFail, resp.StatusCode())
+	}
+
+	if p.disc.Issuer != conf.Issuer {
+		return nil, fmt.Errorf("%w: issuer mismatch %s", ErrDiscoveryFail, p.disc.Issuer)
+	}
+
+	return p, nil
+}
+
+// AuthCodeURL возвращает адрес страницы входа провайдера с PKCE (S256).
+func (p *Provider) AuthCodeURL(state, nonce, verifier string) string {
+	v := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.conf.ClientID},
+		"redirect_uri":          {p.conf.RedirectURL},
+		"scope":                 {"openid profile email"},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {Challenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(p.disc.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return p.disc.AuthorizationEndpoint + sep + v.Encode()
+}
+
+// Exchange обменивает код авторизации на ID токен.
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (string, error) {
+	res := tokenResponse{}
+	resp, err := p.client.R().
+		SetContext(ctx).
+		SetBasicAuth(p.conf.ClientID, p.conf.ClientSecret).
+		SetFormData(map[string]string{
+			"grant_type":    "authorization_code",
+			"code":          code,
+			"redirect_uri":  p.conf.RedirectURL,
+			"code_verifier": verifier,
+		}).
+		SetResult(&res).
+		Post(p.disc.TokenEndpoint)
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrExchangeFail, err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return "", fmt.Errorf("%w: status %d", ErrExchangeFail, resp.StatusCode())
+	}
+
+	if res.IDToken == "" {
+		return "", fmt.Errorf("%w: id_token is empty", ErrExchangeFail)
+	}
+
+	return res.IDToken, nil
+}
+
+// Verify проверяет подпись ID токена по ключам JWKS, издателя, аудиторию, срок действия и nonce.
+func (p *Provider) Verify(ctx context.Context, raw, nonce string) (*Claims, error) {
+	claims := Claims{}
+	_, err := jwt.ParseWithClaims(raw, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(p.disc.Issuer),
+		jwt.WithAudience(p.conf.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenInvalid, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: subject is empty", ErrTokenInvalid)
+	}
+
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrTokenInvalid)
+	}
+
+	return &claims, nil
+}
+
+func (p *Provider) Issuer() string {
+	return p.disc.Issuer
+}
+
+// key ищет ключ по kid, при неизвестном kid ключи перезапрашиваются (ротация у провайдера).
+func (p *Provider) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	p.mu.RLock()
+	key, ok := p.keys[kid]
+	p.mu.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if err := p.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	key, ok = p.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("key %q not found", kid)
+	}
+
+	return key, nil
+}
+
+func (p *Provider) refreshKeys(ctx context.Context) error {
+	set := jwks{}
+	resp, err := p.client.R().
+		SetContext(ctx).
+		SetResult(&set).
+		Get(p.disc.JWKSURI)
+
+	if err != nil {
+		return fmt.Errorf("jwks request fail: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("jwks request fail: status %d", resp.StatusCode())
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+
+		key, err := rsaKey(k)
+		if err != nil {
+			return fmt.Errorf("jwks parse key %q fail: %w", k.Kid, err)
+		}
+
+		keys[k.Kid] = key
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	return nil
+}
+
+func rsaKey(k jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("decode modulus fail: %w", err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("decode exponent fail: %w", err)
+	}
+
+	exp := new(big.Int).SetBytes(e)
+	if !exp.IsInt64() || exp.Int64() < 3 {
+		return nil, errors.New("invalid exponent")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+}
@@ -0,0 +1,71 @@
+package oidc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrStateInvalid = errors.New("oidc state invalid")
+
+// stateKeyLabel отличает ключ подписи состояния от ключа токенов доступа,
+// выведенных из одного секрета.
+const stateKeyLabel = "gophermart oidc state"
+
+// State хранится у клиента в подписанной cookie между началом входа и callback.
+type State struct {
+	jwt.RegisteredClaims
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
+
+func NewState(ttl time.Duration) (*State, error) {
+	st := State{}
+	for _, v := range []*string{&st.State, &st.Nonce, &st.Verifier} {
+		r, err := Random()
+		if err != nil {
+			return nil, fmt.Errorf("new state fail: %w", err)
+		}
+
+		*v = r
+	}
+
+	st.ExpiresAt = jwt.NewNumericDate(time.Now().Add(ttl))
+	return &st, nil
+}
+
+func (st *State) Encode(secret string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, st)
+	str, err := token.SignedString(stateKey(secret))
+	if err != nil {
+		return "", fmt.Errorf("encode state fail: %w", err)
+	}
+
+	return str, nil
+}
+
+func DecodeState(secret, raw string) (*State, error) {
+	st := State{}
+	_, err := jwt.ParseWithClaims(raw, &st, func(*jwt.Token) (interface{}, error) {
+		return stateKey(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrStateInvalid, err)
+	}
+
+	return &st, nil
+}
+
+// stateKey выводит из секрета токенов отдельный ключ, чтобы подпись cookie
+// состояния нельзя было использовать как токен доступа и наоборот.
+func stateKey(secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(stateKeyLabel))
+	return mac.Sum(nil)
+}
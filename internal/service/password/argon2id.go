@@ -0,0 +1,128 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Параметры argon2id по умолчанию (рекомендация OWASP).
+const (
+	Argon2idMemory      uint32 = 19456
+	Argon2idIterations  uint32 = 2
+	Argon2idParallelism uint8  = 1
+)
+
+const (
+	argon2idSaltLength int    = 16
+	argon2idKeyLength  uint32 = 32
+)
+
+type Argon2idParams struct {
+	// Memory в килобайтах
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+type argon2idHasher struct {
+	params Argon2idParams
+}
+
+func NewArgon2id(p Argon2idParams) *argon2idHasher {
+	if p.Memory == 0 {
+		p.Memory = Argon2idMemory
+	}
+
+	if p.Iterations == 0 {
+		p.Iterations = Argon2idIterations
+	}
+
+	if p.Parallelism == 0 {
+		p.Parallelism = Argon2idParallelism
+	}
+
+	return &argon2idHasher{params: p}
+}
+
+// Hash возвращает хеш в формате PHC:
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>.
+func (a *argon2idHasher) Hash(pwd string) (string, error) {
+	salt := make([]byte, argon2idSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("argon2id generate salt fail: %w", err)
+	}
+
+	p := a.params
+	key := argon2.IDKey([]byte(pwd), salt, p.Iterations, p.Memory, p.Parallelism, argon2idKeyLength)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		p.Memory,
+		p.Iterations,
+		p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (a *argon2idHasher) Verify(hash, pwd string) (bool, error) {
+	p, salt, key, err := a.decode(hash)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(pwd), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (a *argon2idHasher) NeedsRehash(hash string) bool {
+	p, _, key, err := a.decode(hash)
+	if err != nil {
+		return true
+	}
+
+	return p != a.params || len(key) != int(argon2idKeyLength)
+}
+
+func (a *argon2idHasher) decode(hash string) (Argon2idParams, []byte, []byte, error) {
+	const partsCount = 6
+
+	p := Argon2idParams{}
+	parts := strings.Split(hash, "$")
+	if len(parts) != partsCount || parts[1] != AlgorithmArgon2id {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return p, nil, nil, fmt.Errorf("%w: parse version fail: %w", ErrInvalidHash, err)
+	}
+
+	if version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidHash, version)
+	}
+
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism)
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("%w: parse params fail: %w", ErrInvalidHash, err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("%w: decode salt fail: %w", ErrInvalidHash, err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("%w: decode key fail: %w", ErrInvalidHash, err)
+	}
+
+	return p, salt, key, nil
+}
@@ -0,0 +1,55 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type BcryptParams struct {
+	Cost int
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+func NewBcrypt(p BcryptParams) *bcryptHasher {
+	cost := p.Cost
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+
+	return &bcryptHasher{cost: cost}
+}
+
+func (b *bcryptHasher) Hash(pwd string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(pwd), b.cost)
+	if err != nil {
+		return "", fmt.Errorf("bcrypt generate fail: %w", err)
+	}
+
+	return string(hash), nil
+}
+
+func (b *bcryptHasher) Verify(hash, pwd string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(pwd))
+	switch {
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("bcrypt compare fail: %w", err)
+	}
+
+	return true, nil
+}
+
+func (b *bcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return true
+	}
+
+	return cost != b.cost
+}
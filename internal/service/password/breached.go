@@ -0,0 +1,143 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const sha1PrefixLength = 5
+
+type BreachedList interface {
+	Contains(pwd string) (bool, error)
+}
+
+type breachedList struct {
+	path   string
+	isDir  bool
+	hashes map[[sha1.Size]byte]struct{}
+}
+
+// NewBreachedList открывает локальный список утекших паролей.
+// Если path является директорией, то она должна содержать файлы
+// в формате k-anonymity: имя файла - первые 5 символов SHA-1 хеша
+// (например, 5BAA6 или 5BAA6.txt), строки файла - SUFFIX:COUNT.
+// Иначе path считается файлом, в каждой строке которого записан
+// полный SHA-1 хеш с необязательным счетчиком: HASH[:COUNT].
+// Файл целиком загружается в память при создании списка.
+func NewBreachedList(path string) (*breachedList, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("breached list stat fail: %w", err)
+	}
+
+	if info.IsDir() {
+		return &breachedList{path: path, isDir: true}, nil
+	}
+
+	hashes, err := load(path)
+	if err != nil {
+		return nil, fmt.Errorf("breached list load %s fail: %w", path, err)
+	}
+
+	return &breachedList{path: path, hashes: hashes}, nil
+}
+
+func (b *breachedList) Contains(pwd string) (bool, error) {
+	sum := sha1.Sum([]byte(pwd))
+
+	if !b.isDir {
+		_, ok := b.hashes[sum]
+		return ok, nil
+	}
+
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:sha1PrefixLength], hash[sha1PrefixLength:]
+	for _, name := range []string{prefix, prefix + ".txt"} {
+		ok, err := b.scan(filepath.Join(b.path, name), suffix)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		return ok, err
+	}
+
+	return false, nil
+}
+
+func (b *breachedList) scan(path, needle string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("breached list open fail: %w", err)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	ok, err := find(f, needle)
+	if err != nil {
+		return false, fmt.Errorf("breached list scan %s fail: %w", path, err)
+	}
+
+	return ok, nil
+}
+
+func load(path string) (map[[sha1.Size]byte]struct{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open fail: %w", err)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	hashes := make(map[[sha1.Size]byte]struct{})
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		value, _, _ := strings.Cut(strings.TrimSpace(s.Text()), ":")
+		if value == "" {
+			continue
+		}
+
+		var hash [sha1.Size]byte
+		if len(value) != hex.EncodedLen(sha1.Size) {
+			return nil, fmt.Errorf("line %d: invalid sha1 hash %q", line, value)
+		}
+
+		if _, err := hex.Decode(hash[:], []byte(value)); err != nil {
+			return nil, fmt.Errorf("line %d: invalid sha1 hash %q", line, value)
+		}
+
+		hashes[hash] = struct{}{}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("scan fail: %w", err)
+	}
+
+	return hashes, nil
+}
+
+func find(r io.Reader, needle string) (bool, error) {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		value, _, _ := strings.Cut(strings.TrimSpace(s.Text()), ":")
+		if strings.EqualFold(value, needle) {
+			return true, nil
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return false, fmt.Errorf("scan fail: %w", err)
+	}
+
+	return false, nil
+}
@@ -1,21 +1,102 @@
 package password
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+)
+
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
 
-	"golang.org/x/crypto/bcrypt"
+var (
+	ErrUnknownAlgorithm = errors.New("unknown password hash algorithm")
+	ErrInvalidHash      = errors.New("invalid password hash")
 )
 
-func Encrypt(pwd string) (string, error) {
-	passHash, err := bcrypt.GenerateFromPassword([]byte(pwd), bcrypt.DefaultCost)
+type Hasher interface {
+	Hash(pwd string) (string, error)
+	Verify(hash, pwd string) (bool, error)
+	NeedsRehash(hash string) bool
+}
+
+type Options struct {
+	Algorithm string
+	Bcrypt    BcryptParams
+	Argon2id  Argon2idParams
+}
+
+type manager struct {
+	current Hasher
+	known   map[string]Hasher
+}
+
+// NewHasher возвращает хешер, который создает хеши выбранным алгоритмом,
+// но умеет проверять хеши всех поддерживаемых алгоритмов.
+func NewHasher(opts Options) (Hasher, error) {
+	bc := NewBcrypt(opts.Bcrypt)
+	a2 := NewArgon2id(opts.Argon2id)
+
+	m := &manager{
+		known: map[string]Hasher{
+			AlgorithmBcrypt:   bc,
+			AlgorithmArgon2id: a2,
+		},
+	}
+
+	switch opts.Algorithm {
+	case "", AlgorithmBcrypt:
+		m.current = bc
+	case AlgorithmArgon2id:
+		m.current = a2
+	default:
+		return nil, fmt.Errorf("new hasher %w: %s", ErrUnknownAlgorithm, opts.Algorithm)
+	}
+
+	return m, nil
+}
+
+func (m *manager) Hash(pwd string) (string, error) {
+	hash, err := m.current.Hash(pwd)
+	if err != nil {
+		return "", fmt.Errorf("hash fail: %w", err)
+	}
+
+	return hash, nil
+}
+
+func (m *manager) Verify(hash, pwd string) (bool, error) {
+	h, err := m.detect(hash)
+	if err != nil {
+		return false, err
+	}
+
+	ok, err := h.Verify(hash, pwd)
 	if err != nil {
-		return "", fmt.Errorf("register encrypt password fail: %w", err)
+		return false, fmt.Errorf("verify fail: %w", err)
 	}
 
-	return string(passHash), nil
+	return ok, nil
 }
 
-func Check(userPwd, pwd string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(userPwd), []byte(pwd))
-	return err == nil
+func (m *manager) NeedsRehash(hash string) bool {
+	h, err := m.detect(hash)
+	if err != nil || h != m.current {
+		return true
+	}
+
+	return m.current.NeedsRehash(hash)
+}
+
+func (m *manager) detect(hash string) (Hasher, error) {
+	switch {
+	case strings.HasPrefix(hash, "$"+AlgorithmArgon2id+"$"):
+		return m.known[AlgorithmArgon2id], nil
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return m.known[AlgorithmBcrypt], nil
+	default:
+		return nil, ErrInvalidHash
+	}
 }
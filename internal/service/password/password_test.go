@@ -0,0 +1,118 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasherVerify(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+	}{
+		{
+			name:      "bcrypt",
+			algorithm: AlgorithmBcrypt,
+		},
+		{
+			name:      "argon2id",
+			algorithm: AlgorithmArgon2id,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := NewHasher(Options{Algorithm: tt.algorithm, Bcrypt: BcryptParams{Cost: 4}})
+			require.NoError(t, err)
+
+			hash, err := h.Hash("secret")
+			require.NoError(t, err)
+
+			ok, err := h.Verify(hash, "secret")
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = h.Verify(hash, "other")
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			require.False(t, h.NeedsRehash(hash))
+		})
+	}
+}
+
+func TestHasherArgon2idFormat(t *testing.T) {
+	t.Run("argon2id phc format", func(t *testing.T) {
+		h := NewArgon2id(Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1})
+
+		hash, err := h.Hash("secret")
+		require.NoError(t, err)
+		require.Regexp(t, `^\$argon2id\$v=19\$m=1024,t=1,p=1\$[A-Za-z0-9+/]+\$[A-Za-z0-9+/]+$`, hash)
+	})
+}
+
+func TestHasherNeedsRehash(t *testing.T) {
+	bcryptHash, err := NewBcrypt(BcryptParams{Cost: 4}).Hash("secret")
+	require.NoError(t, err)
+
+	argonHash, err := NewArgon2id(Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}).Hash("secret")
+	require.NoError(t, err)
+
+	tests := []struct {
+		opts Options
+		name string
+		hash string
+		want bool
+	}{
+		{
+			name: "bcrypt to argon2id",
+			opts: Options{Algorithm: AlgorithmArgon2id},
+			hash: bcryptHash,
+			want: true,
+		},
+		{
+			name: "bcrypt cost changed",
+			opts: Options{Algorithm: AlgorithmBcrypt, Bcrypt: BcryptParams{Cost: 5}},
+			hash: bcryptHash,
+			want: true,
+		},
+		{
+			name: "argon2id params changed",
+			opts: Options{Algorithm: AlgorithmArgon2id, Argon2id: Argon2idParams{Memory: 2048}},
+			hash: argonHash,
+			want: true,
+		},
+		{
+			name: "argon2id params same",
+			opts: Options{Algorithm: AlgorithmArgon2id, Argon2id: Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}},
+			hash: argonHash,
+			want: false,
+		},
+		{
+			name: "argon2id to bcrypt",
+			opts: Options{Algorithm: AlgorithmBcrypt},
+			hash: argonHash,
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := NewHasher(tt.opts)
+			require.NoError(t, err)
+
+			ok, err := h.Verify(tt.hash, "secret")
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, tt.want, h.NeedsRehash(tt.hash))
+		})
+	}
+}
+
+func TestHasherUnknownAlgorithm(t *testing.T) {
+	t.Run("unknown algorithm", func(t *testing.T) {
+		_, err := NewHasher(Options{Algorithm: "md5"})
+		require.ErrorIs(t, err, ErrUnknownAlgorithm)
+	})
+}
@@ -0,0 +1,159 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	policyMinLength   = 8
+	policyMaxLength   = 64
+	bcryptMaxBytes    = 72
+	ViolationMin      = "min_length"
+	ViolationMax      = "max_length"
+	ViolationClasses  = "char_classes"
+	ViolationLogin    = "equal_login"
+	ViolationBreached = "breached"
+)
+
+type Violation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type PolicyError struct {
+	Field      string
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	codes := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		codes = append(codes, v.Code)
+	}
+
+	return "password policy violated: " + strings.Join(codes, ", ")
+}
+
+type PolicyOptions struct {
+	Breached   BreachedList
+	MinLength  int
+	MaxLength  int
+	MaxBytes   int
+	MinClasses int
+}
+
+type Policy struct {
+	opts PolicyOptions
+}
+
+func NewPolicy(opts PolicyOptions) *Policy {
+	if opts.MinLength == 0 {
+		opts.MinLength = policyMinLength
+	}
+
+	if opts.MaxLength == 0 {
+		opts.MaxLength = policyMaxLength
+	}
+
+	return &Policy{opts: opts}
+}
+
+// Validate возвращает *PolicyError со списком всех нарушений политики
+// или ошибку проверки по списку утекших паролей.
+func (p *Policy) Validate(login, pwd string) error {
+	var violations []Violation
+
+	length := utf8.RuneCountInString(pwd)
+	if length < p.opts.MinLength {
+		violations = append(violations, Violation{
+			Code:    ViolationMin,
+			Message: "password must be at least " + strconv.Itoa(p.opts.MinLength) + " characters",
+		})
+	}
+
+	if length > p.opts.MaxLength || (p.opts.MaxBytes > 0 && len(pwd) > p.opts.MaxBytes) {
+		violations = append(violations, Violation{
+			Code:    ViolationMax,
+			Message: "password must be at most " + strconv.Itoa(p.opts.MaxLength) + " characters",
+		})
+	}
+
+	if classes(pwd) < p.opts.MinClasses {
+		violations = append(violations, Violation{
+			Code: ViolationClasses,
+			Message: "password must contain at least " + strconv.Itoa(p.opts.MinClasses) +
+				" of: lowercase, uppercase, digits, special characters",
+		})
+	}
+
+	if login != "" && strings.EqualFold(login, pwd) {
+		violations = append(violations, Violation{
+			Code:    ViolationLogin,
+			Message: "password must not be equal to login",
+		})
+	}
+
+	if p.opts.Breached != nil {
+		breached, err := p.opts.Breached.Contains(pwd)
+		if err != nil {
+			return fmt.Errorf("policy breached check fail: %w", err)
+		}
+
+		if breached {
+			violations = append(violations, Violation{
+				Code:    ViolationBreached,
+				Message: "password has appeared in a data breach",
+			})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+
+	return nil
+}
+
+// MaxBytes возвращает ограничение длины пароля в байтах для алгоритма хеширования.
+func MaxBytes(algorithm string) int {
+	if algorithm == "" || algorithm == AlgorithmBcrypt {
+		return bcryptMaxBytes
+	}
+
+	return 0
+}
+
+func IsPolicyError(err error) bool {
+	var pErr *PolicyError
+	return errors.As(err, &pErr)
+}
+
+func classes(pwd string) int {
+	var lower, upper, digit, special bool
+	for _, r := range pwd {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			special = true
+		}
+	}
+
+	count := 0
+	for _, ok := range []bool{lower, upper, digit, special} {
+		if ok {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,126 @@
+package password
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		login string
+		pwd   string
+		codes []string
+	}{
+		{
+			name:  "valid password",
+			login: "user",
+			pwd:   "Secret-123",
+		},
+		{
+			name:  "too short",
+			login: "user",
+			pwd:   "Ab1",
+			codes: []string{ViolationMin},
+		},
+		{
+			name:  "too long",
+			login: "user",
+			pwd:   "Ab1" + strings.Repeat("x", 70),
+			codes: []string{ViolationMax},
+		},
+		{
+			name:  "not enough classes",
+			login: "user",
+			pwd:   "secretsecret",
+			codes: []string{ViolationClasses},
+		},
+		{
+			name:  "equal to login",
+			login: "Secret-123",
+			pwd:   "secret-123",
+			codes: []string{ViolationLogin},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPolicy(PolicyOptions{MinClasses: 3, MaxBytes: MaxBytes(AlgorithmBcrypt)})
+			err := p.Validate(tt.login, tt.pwd)
+			if len(tt.codes) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var pErr *PolicyError
+			require.ErrorAs(t, err, &pErr)
+
+			codes := make([]string, 0, len(pErr.Violations))
+			for _, v := range pErr.Violations {
+				codes = append(codes, v.Code)
+			}
+			require.Equal(t, tt.codes, codes)
+		})
+	}
+}
+
+func TestPolicyBreached(t *testing.T) {
+	const breached = "Password-1"
+
+	sum := sha1.Sum([]byte(breached))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "list.txt")
+	require.NoError(t, os.WriteFile(file, []byte(hash+":42\n"), 0o600))
+
+	prefixDir := filepath.Join(dir, "range")
+	require.NoError(t, os.Mkdir(prefixDir, 0o700))
+	content := "0000000000000000000000000000000000A:1\r\n" + hash[sha1PrefixLength:] + ":42\r\n"
+	require.NoError(t, os.WriteFile(filepath.Join(prefixDir, hash[:sha1PrefixLength]+".txt"), []byte(content), 0o600))
+
+	for _, path := range []string{file, prefixDir} {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			list, err := NewBreachedList(path)
+			require.NoError(t, err)
+
+			p := NewPolicy(PolicyOptions{Breached: list})
+
+			var pErr *PolicyError
+			require.ErrorAs(t, p.Validate("user", breached), &pErr)
+			require.Equal(t, ViolationBreached, pErr.Violations[0].Code)
+
+			require.NoError(t, p.Validate("user", "Other-Password-2"))
+		})
+	}
+}
+
+func TestBreachedListLoadedOnce(t *testing.T) {
+	sum := sha1.Sum([]byte("Password-1"))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	file := filepath.Join(t.TempDir(), "list.txt")
+	require.NoError(t, os.WriteFile(file, []byte(hash+":42\n\n"), 0o600))
+
+	list, err := NewBreachedList(file)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(file))
+
+	ok, err := list.Contains("Password-1")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestBreachedListInvalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "list.txt")
+	require.NoError(t, os.WriteFile(file, []byte("not-a-hash:1\n"), 0o600))
+
+	_, err := NewBreachedList(file)
+	require.ErrorContains(t, err, "line 1")
+}
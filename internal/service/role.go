@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/model"
+)
+
+var (
+	ErrRoleUnknown        = errors.New("unknown role")
+	ErrRoleUserNotFound   = errors.New("user not found")
+	ErrRoleJSONDecodeFail = errors.New("json decode fail")
+	ErrRoleValidateFail   = errors.New("validate fail")
+)
+
+type roleService struct {
+	app *application.App
+}
+
+func NewRoleService(app *application.App) *roleService {
+	return &roleService{
+		app: app,
+	}
+}
+
+func (rs *roleService) Grant(ctx context.Context, login, role string) error {
+	if !model.RoleExists(role) {
+		return ErrRoleUnknown
+	}
+
+	err := rs.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		user, ok := rs.app.Rep.User.FindByLogin(ctx, login)
+		if !ok {
+			return ErrRoleUserNotFound
+		}
+
+		return rs.app.Rep.Role.Grant(ctx, user.ID, role)
+	})
+
+	if err != nil {
+		return fmt.Errorf("grant role transaction fail: %w", err)
+	}
+
+	return nil
+}
+
+func (rs *roleService) Revoke(ctx context.Context, login, role string) error {
+	if !model.RoleExists(role) {
+		return ErrRoleUnknown
+	}
+
+	err := rs.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		user, ok := rs.app.Rep.User.FindByLogin(ctx, login)
+		if !ok {
+			return ErrRoleUserNotFound
+		}
+
+		return rs.app.Rep.Role.Revoke(ctx, user.ID, role)
+	})
+
+	if err != nil {
+		return fmt.Errorf("revoke role transaction fail: %w", err)
+	}
+
+	return nil
+}
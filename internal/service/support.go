@@ -0,0 +1,390 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/events"
+	"github.com/arefev/gophermart/internal/model"
+)
+
+var (
+	ErrSupportUserNotFound    = errors.New("user not found")
+	ErrSupportOrderNotFound   = errors.New("order not found")
+	ErrSupportReasonRequired  = errors.New("reason is required")
+	ErrSupportAmountInvalid   = errors.New("amount must not be zero")
+	ErrSupportNegativeBalance = errors.New("balance would become negative")
+	ErrSupportOrderProcessed  = errors.New("order is already processed")
+	ErrSupportStatusInvalid   = errors.New("order status can not be set manually")
+	ErrSupportAccrualFail     = errors.New("accrual system unavailable")
+	ErrSupportJSONDecodeFail  = errors.New("json decode fail")
+	ErrSupportValidateFail    = errors.New("validate fail")
+)
+
+// supportSearchLimit сколько пользователей максимум возвращает поиск.
+const supportSearchLimit = 50
+
+// supportService операции службы поддержки над чужими пользователями и заказами.
+// Каждое изменение пишет запись аудита в своей транзакции.
+type supportService struct {
+	app   *application.App
+	actor string
+}
+
+// NewSupportService actor - кто выполняет действия, например user:admin или cli:root.
+func NewSupportService(app *application.App, actor string) *supportService {
+	return &supportService{
+		app:   app,
+		actor: actor,
+	}
+}
+
+func (ss *supportService) FindUser(ctx context.Context, login string) (*model.User, error) {
+	var user *model.User
+	err := ss.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = ss.user(ctx, login)
+		return err
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("support find user transaction fail: %w", err)
+	}
+
+	return user, nil
+}
+
+// SearchUsers ищет пользователей по части логина.
+func (ss *supportService) SearchUsers(ctx context.Context, pattern string) ([]model.User, error) {
+	var list []model.User
+	err := ss.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		list = ss.app.Rep.User.Search(ctx, pattern, supportSearchLimit)
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("support search users transaction fail: %w", err)
+	}
+
+	return list, nil
+}
+
+func (ss *supportService) Balance(ctx context.Context, login string) (*model.Balance, error) {
+	var balance *model.Balance
+	err := ss.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		user, err := ss.user(ctx, login)
+		if err != nil {
+			return err
+		}
+
+		var ok bool
+		if balance, ok = ss.app.Rep.Balance.FindByUserID(ctx, user.ID); !ok {
+			return errors.New("user balance not found")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("support balance transaction fail: %w", err)
+	}
+
+	return balance, nil
+}
+
+func (ss *supportService) Orders(ctx context.Context, login string) ([]model.Order, error) {
+	var list []model.Order
+	err := ss.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		user, err := ss.user(ctx, login)
+		if err != nil {
+			return err
+		}
+
+		list = ss.app.Rep.Order.GetByUserID(ctx, user.ID)
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("support orders transaction fail: %w", err)
+	}
+
+	return list, nil
+}
+
+func (ss *supportService) Withdrawals(ctx context.Context, login string) ([]model.Withdrawal, error) {
+	var list []model.Withdrawal
+	err := ss.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		user, err := ss.user(ctx, login)
+		if err != nil {
+			return err
+		}
+
+		list = ss.app.Rep.Order.GetWithdrawalsByUserID(ctx, user.ID)
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("support withdrawals transaction fail: %w", err)
+	}
+
+	return list, nil
+}
+
+// AdjustBalance начисляет (amount > 0) или списывает (amount < 0) баллы без заказа.
+func (ss *supportService) AdjustBalance(
+	ctx context.Context,
+	login string,
+	amount float64,
+	reason string,
+) (*model.Balance, error) {
+	if err := checkReason(reason); err != nil {
+		return nil, err
+	}
+
+	if amount == 0 {
+		return nil, ErrSupportAmountInvalid
+	}
+
+	var balance *model.Balance
+	err := ss.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		user, err := ss.user(ctx, login)
+		if err != nil {
+			return err
+		}
+
+		// Баланс меняется приращением: начисление воркера или списание пользователя,
+		// закоммиченные параллельно, не затираются
+		var ok bool
+		balance, ok, err = ss.app.Rep.Balance.AddCurrent(ctx, user.ID, amount)
+		if err != nil {
+			return fmt.Errorf("update user balance fail: %w", err)
+		}
+
+		if !ok {
+			return ErrSupportNegativeBalance
+		}
+
+		before := balance.Current - amount
+
+		payload, err := events.NewBalancePayload(balance.Current, balance.Withdrawn)
+		if err != nil {
+			return err
+		}
+
+		if err := ss.app.Rep.Event.Create(ctx, user.ID, model.EventBalance, payload); err != nil {
+			return fmt.Errorf("create balance event fail: %w", err)
+		}
+
+		details := map[string]float64{"amount": amount, "before": before, "after": balance.Current}
+		return ss.audit(ctx, model.AuditBalanceAdjust, user.ID, "", reason, details)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("support adjust balance transaction fail: %w", err)
+	}
+
+	return balance, nil
+}
+
+// RequeueOrder возвращает заказ в статус NEW, чтобы воркер снова запросил начисление.
+// Обработанный заказ не переотправляется: начисление по нему уже зачислено.
+func (ss *supportService) RequeueOrder(ctx context.Context, number, reason string) error {
+	return ss.orderStatus(ctx, number, reason, model.OrderStatusNew, model.AuditOrderRequeue)
+}
+
+// InvalidateOrder помечает заказ как INVALID без запроса в систему начислений.
+func (ss *supportService) InvalidateOrder(ctx context.Context, number, reason string) error {
+	return ss.orderStatus(ctx, number, reason, model.OrderStatusInvalid, model.AuditOrderInvalid)
+}
+
+// OverrideOrderStatus ставит заказу произвольный статус, кроме PROCESSED:
+// начисление баллов проходит только через систему начислений.
+func (ss *supportService) OverrideOrderStatus(
+	ctx context.Context,
+	number string,
+	status model.OrderStatus,
+	reason string,
+) error {
+	if status == model.OrderStatusProcessed {
+		return ErrSupportStatusInvalid
+	}
+
+	return ss.orderStatus(ctx, number, reason, status, model.AuditOrderStatusOverride)
+}
+
+// Recheck сразу запрашивает статус заказа в системе начислений, не дожидаясь воркера.
+// Запрос идет вне транзакции, запись аудита делается до него, чтобы попытка
+// осталась в журнале даже при недоступной системе начислений.
+func (ss *supportService) Recheck(ctx context.Context, number, reason string) (*model.Order, error) {
+	if err := checkReason(reason); err != nil {
+		return nil, err
+	}
+
+	if ss.app.Accrual == nil {
+		return nil, ErrSupportAccrualFail
+	}
+
+	var order *model.Order
+	err := ss.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		var ok bool
+		if order, ok = ss.app.Rep.Order.FindByNumber(ctx, number); !ok {
+			return ErrSupportOrderNotFound
+		}
+
+		if order.Status == model.OrderStatusProcessed {
+			return ErrSupportOrderProcessed
+		}
+
+		details := map[string]string{"status": order.Status.String()}
+		return ss.audit(ctx, model.AuditOrderRecheck, order.UserID, order.Number, reason, details)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("support recheck transaction fail: %w", err)
+	}
+
+	if err := ss.app.Accrual.Recheck(ctx, order); err != nil {
+		return nil, fmt.Errorf("support recheck %w: %w", ErrSupportAccrualFail, err)
+	}
+
+	err = ss.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		var ok bool
+		if order, ok = ss.app.Rep.Order.FindByNumber(ctx, number); !ok {
+			return ErrSupportOrderNotFound
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("support recheck find order transaction fail: %w", err)
+	}
+
+	return order, nil
+}
+
+func (ss *supportService) orderStatus(
+	ctx context.Context,
+	number, reason string,
+	status model.OrderStatus,
+	action string,
+) error {
+	if err := checkReason(reason); err != nil {
+		return err
+	}
+
+	err := ss.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		order, ok := ss.app.Rep.Order.FindByNumber(ctx, number)
+		if !ok {
+			return ErrSupportOrderNotFound
+		}
+
+		if order.Status == model.OrderStatusProcessed {
+			return ErrSupportOrderProcessed
+		}
+
+		if err := ss.app.Rep.Order.StatusByID(ctx, status, order.ID); err != nil {
+			return fmt.Errorf("update order status fail: %w", err)
+		}
+
+		payload, err := events.NewOrderPayload(order.Number, status, order.Accrual.Float64)
+		if err != nil {
+			return err
+		}
+
+		if err := ss.app.Rep.Event.Create(ctx, order.UserID, model.EventOrder, payload); err != nil {
+			return fmt.Errorf("create order event fail: %w", err)
+		}
+
+		details := map[string]string{"from": order.Status.String(), "to": status.String()}
+		return ss.audit(ctx, action, order.UserID, order.Number, reason, details)
+	})
+
+	if err != nil {
+		return fmt.Errorf("support order status transaction fail: %w", err)
+	}
+
+	return nil
+}
+
+// SetBlocked блокирует или разблокирует пользователя. Заблокированный пользователь
+// не может войти, а его выданные токены перестают приниматься.
+func (ss *supportService) SetBlocked(ctx context.Context, login string, blocked bool, reason string) error {
+	if err := checkReason(reason); err != nil {
+		return err
+	}
+
+	action := model.AuditUserUnblock
+	if blocked {
+		action = model.AuditUserBlock
+	}
+
+	err := ss.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		user, err := ss.user(ctx, login)
+		if err != nil {
+			return err
+		}
+
+		if err := ss.app.Rep.User.SetBlocked(ctx, user.ID, blocked); err != nil {
+			return fmt.Errorf("set user blocked fail: %w", err)
+		}
+
+		return ss.audit(ctx, action, user.ID, "", reason, map[string]bool{"blocked": blocked})
+	})
+
+	if err != nil {
+		return fmt.Errorf("support set blocked transaction fail: %w", err)
+	}
+
+	return nil
+}
+
+func (ss *supportService) user(ctx context.Context, login string) (*model.User, error) {
+	user, ok := ss.app.Rep.User.FindByLogin(ctx, login)
+	if !ok {
+		return nil, ErrSupportUserNotFound
+	}
+
+	return user, nil
+}
+
+func (ss *supportService) audit(
+	ctx context.Context,
+	action string,
+	userID int,
+	number, reason string,
+	details any,
+) error {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("audit details marshal fail: %w", err)
+	}
+
+	record := model.AuditRecord{
+		Actor:       ss.actor,
+		Action:      action,
+		UserID:      sql.NullInt64{Int64: int64(userID), Valid: true},
+		OrderNumber: sql.NullString{String: number, Valid: number != ""},
+		Reason:      reason,
+		Details:     string(data),
+	}
+
+	if err := ss.app.Rep.Audit.Create(ctx, record); err != nil {
+		return fmt.Errorf("create audit record fail: %w", err)
+	}
+
+	return nil
+}
+
+func checkReason(reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return ErrSupportReasonRequired
+	}
+
+	return nil
+}
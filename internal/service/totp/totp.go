@@ -0,0 +1,101 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Period     int64 = 30
+	Digits     int   = 6
+	secretSize int   = 20
+	skew       int64 = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate secret fail: %w", err)
+	}
+
+	return encoding.EncodeToString(b), nil
+}
+
+// URI возвращает ссылку otpauth:// для добавления секрета в приложение-аутентификатор.
+func URI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	values := url.Values{}
+	values.Set("secret", secret)
+	values.Set("issuer", issuer)
+	values.Set("algorithm", "SHA1")
+	values.Set("digits", strconv.Itoa(Digits))
+	values.Set("period", strconv.FormatInt(Period, 10))
+
+	return "otpauth://totp/" + label + "?" + values.Encode()
+}
+
+func Code(secret string, t time.Time) (string, error) {
+	return code(secret, Step(t))
+}
+
+func Step(t time.Time) int64 {
+	return t.Unix() / Period
+}
+
+// Validate проверяет код с учетом допустимого расхождения часов
+// и возвращает номер шага, которому соответствует код.
+func Validate(secret, passcode string, t time.Time) (int64, bool) {
+	passcode = strings.TrimSpace(passcode)
+	if len(passcode) != Digits {
+		return 0, false
+	}
+
+	current := Step(t)
+	for step := current - skew; step <= current+skew; step++ {
+		expected, err := code(secret, step)
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(passcode)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+func code(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("decode secret fail: %w", err)
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range Digits {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", Digits, value%mod), nil
+}
@@ -0,0 +1,60 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeRFC6238(t *testing.T) {
+	// Тестовые векторы RFC 6238 для SHA1, последние 6 цифр.
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		want string
+		unix int64
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			code, err := Code(secret, time.Unix(tt.unix, 0))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, code)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("validate with skew", func(t *testing.T) {
+		secret, err := GenerateSecret()
+		require.NoError(t, err)
+
+		now := time.Now()
+		code, err := Code(secret, now.Add(-30*time.Second))
+		require.NoError(t, err)
+
+		step, ok := Validate(secret, code, now)
+		require.True(t, ok)
+		require.Equal(t, Step(now)-1, step)
+
+		_, ok = Validate(secret, code, now.Add(2*time.Minute))
+		require.False(t, ok)
+	})
+}
+
+func TestURI(t *testing.T) {
+	t.Run("otpauth uri", func(t *testing.T) {
+		uri := URI("Gophermart", "user", "SECRET")
+		require.True(t, strings.HasPrefix(uri, "otpauth://totp/Gophermart:user?"))
+		require.Contains(t, uri, "secret=SECRET")
+		require.Contains(t, uri, "issuer=Gophermart")
+	})
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/service/jwt"
+	"github.com/arefev/gophermart/internal/service/totp"
+)
+
+const (
+	recoveryCodesCount   = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two factor already enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("two factor not enrolled")
+	ErrTwoFactorInvalidCode    = errors.New("two factor invalid code")
+	ErrTwoFactorLocked         = errors.New("too many invalid two factor codes, try again later")
+	ErrTwoFactorJSONDecodeFail = errors.New("json decode fail")
+	ErrTwoFactorValidateFail   = errors.New("validate fail")
+)
+
+type twoFactorService struct {
+	app *application.App
+	now func() time.Time
+}
+
+func NewTwoFactorService(app *application.App) *twoFactorService {
+	return &twoFactorService{
+		app: app,
+		now: time.Now,
+	}
+}
+
+// Enroll создает новый секрет пользователя и возвращает его вместе с otpauth ссылкой.
+// Двухфакторная аутентификация включается только после подтверждения кодом.
+func (tfs *twoFactorService) Enroll(ctx context.Context, user *model.User) (string, string, error) {
+	if user.TOTPEnabled {
+		return "", "", ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", fmt.Errorf("enroll generate secret fail: %w", err)
+	}
+
+	err = tfs.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		return tfs.app.Rep.User.SetTOTPSecret(ctx, user.ID, secret)
+	})
+
+	if err != nil {
+		return "", "", fmt.Errorf("enroll transaction fail: %w", err)
+	}
+
+	return secret, totp.URI(tfs.app.Conf.TOTPIssuer, user.Login, secret), nil
+}
+
+// Confirm включает двухфакторную аутентификацию и возвращает одноразовые коды восстановления.
+func (tfs *twoFactorService) Confirm(ctx context.Context, user *model.User, code string) ([]string, error) {
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	if !user.TOTPSecret.Valid {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	step, ok := totp.Validate(user.TOTPSecret.String, code, tfs.now())
+	if !ok {
+		return nil, ErrTwoFactorInvalidCode
+	}
+
+	codes, hashes, err := tfs.recoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("confirm generate recovery codes fail: %w", err)
+	}
+
+	err = tfs.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		if err := tfs.app.Rep.User.EnableTOTP(ctx, user.ID, step); err != nil {
+			return fmt.Errorf("enable totp fail: %w", err)
+		}
+
+		if err := tfs.app.Rep.RecoveryCode.Replace(ctx, user.ID, hashes); err != nil {
+			return fmt.Errorf("save recovery codes fail: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("confirm transaction fail: %w", err)
+	}
+
+	return codes, nil
+}
+
+// Login проверяет TOTP код или код восстановления и выдает токен с полным доступом.
+// После TwoFactorAttempts неверных кодов подряд токен второго шага отзывается,
+// а вход блокируется на TwoFactorLockout минут.
+func (tfs *twoFactorService) Login(ctx context.Context, user *model.User, code string) (*jwt.Token, error) {
+	if !user.TOTPEnabled {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	if user.TOTPLocked(tfs.now()) {
+		return nil, ErrTwoFactorLocked
+	}
+
+	ok, err := tfs.verify(ctx, user, code)
+	if err != nil {
+		return nil, fmt.Errorf("two factor login verify fail: %w", err)
+	}
+
+	if !ok {
+		return nil, tfs.failed(ctx, user)
+	}
+
+	if user.TOTPFailures > 0 {
+		err := tfs.app.TrManager.Do(ctx, func(ctx context.Context) error {
+			return tfs.app.Rep.User.ResetTOTPFailures(ctx, user.ID)
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("two factor login reset failures fail: %w", err)
+		}
+	}
+
+	token, err := jwt.NewToken(tfs.app.Conf.TokenSecret).GenerateToken(user, tfs.app.Conf.TokenDuration)
+	if err != nil {
+		return nil, fmt.Errorf("two factor login generate token fail: %w", err)
+	}
+
+	return token, nil
+}
+
+// failed учитывает неверный код и возвращает ошибку для ответа клиенту.
+func (tfs *twoFactorService) failed(ctx context.Context, user *model.User) error {
+	var locked bool
+	err := tfs.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+		lockout := time.Duration(tfs.app.Conf.TwoFactorLockout) * time.Minute
+		locked, err = tfs.app.Rep.User.TOTPFailed(ctx, user.ID, tfs.app.Conf.TwoFactorAttempts, lockout)
+		return err
+	})
+
+	if err != nil {
+		return fmt.Errorf("two factor login count failure fail: %w", err)
+	}
+
+	if locked {
+		return ErrTwoFactorLocked
+	}
+
+	return ErrTwoFactorInvalidCode
+}
+
+func (tfs *twoFactorService) verify(ctx context.Context, user *model.User, code string) (bool, error) {
+	var ok bool
+
+	step, isTOTP := totp.Validate(user.TOTPSecret.String, code, tfs.now())
+	err := tfs.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+		if isTOTP {
+			ok, err = tfs.app.Rep.User.UseTOTPStep(ctx, user.ID, step)
+		} else {
+			ok, err = tfs.app.Rep.RecoveryCode.Use(ctx, user.ID, hashRecoveryCode(code))
+		}
+
+		return err
+	})
+
+	if err != nil {
+		return false, fmt.Errorf("verify transaction fail: %w", err)
+	}
+
+	return ok, nil
+}
+
+func (tfs *twoFactorService) recoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([]string, 0, recoveryCodesCount)
+
+	for range recoveryCodesCount {
+		b := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("read random fail: %w", err)
+		}
+
+		for i := range b {
+			b[i] = recoveryCodeAlphabet[int(b[i])%len(recoveryCodeAlphabet)]
+		}
+
+		code := string(b[:recoveryCodeLength/2]) + "-" + string(b[recoveryCodeLength/2:])
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+
+	return hex.EncodeToString(sum[:])
+}
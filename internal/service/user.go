@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/arefev/gophermart/internal/application"
 	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/outbox"
 	"github.com/arefev/gophermart/internal/service/jwt"
 	"github.com/arefev/gophermart/internal/service/password"
+	"go.uber.org/zap"
 )
 
 var (
@@ -19,6 +22,11 @@ var (
 	ErrAuthJSONDecodeFail     = errors.New("json decode fail")
 	ErrAuthValidateFail       = errors.New("validate fail")
 	ErrUserNotAuthorized      = errors.New("user not authorized")
+	ErrAccessDenied           = errors.New("access denied")
+	ErrUserBlocked            = errors.New("user blocked")
+	ErrPasswordJSONDecodeFail = errors.New("json decode fail")
+	ErrPasswordValidateFail   = errors.New("validate fail")
+	ErrPasswordWrongCurrent   = errors.New("wrong current password")
 )
 
 type userService struct {
@@ -37,7 +45,12 @@ func (us *userService) Create(ctx context.Context, login string, pwd string) err
 			return ErrRegisterUserExists
 		}
 
-		pwdHash, err := password.Encrypt(pwd)
+		hasher, err := us.hasher()
+		if err != nil {
+			return fmt.Errorf("init hasher fail: %w", err)
+		}
+
+		pwdHash, err := hasher.Hash(pwd)
 		if err != nil {
 			return fmt.Errorf("encrypt password fail: %w", err)
 		}
@@ -46,7 +59,8 @@ func (us *userService) Create(ctx context.Context, login string, pwd string) err
 			return fmt.Errorf("create user fail: %w", err)
 		}
 
-		return nil
+		event := outbox.UserRegistered{Login: login, Source: "password"}
+		return outbox.Emit(ctx, us.app, model.OutboxUserRegistered, event)
 	})
 
 	if err != nil {
@@ -57,15 +71,36 @@ func (us *userService) Create(ctx context.Context, login string, pwd string) err
 }
 
 func (us *userService) Authorize(ctx context.Context, login, pwd string) (*jwt.Token, error) {
-	user, err := us.GetUser(ctx, login)
+	user, err := us.findUser(ctx, login)
 	if err != nil {
 		return nil, fmt.Errorf("authorize get user fail: %w", err)
 	}
 
-	if !password.Check(user.Password, pwd) {
+	hasher, err := us.hasher()
+	if err != nil {
+		return nil, fmt.Errorf("authorize init hasher fail: %w", err)
+	}
+
+	ok, err := hasher.Verify(user.Password, pwd)
+	if err != nil {
+		return nil, fmt.Errorf("authorize %w: %w", ErrAuthUserNotFound, err)
+	}
+
+	if !ok {
 		return nil, ErrAuthUserNotFound
 	}
 
+	// Блокировка проверяется после пароля, иначе ответ раскрывал бы, что учетная запись существует
+	if user.Blocked {
+		return nil, ErrUserBlocked
+	}
+
+	us.rehash(ctx, hasher, user, pwd)
+
+	if user.TOTPEnabled {
+		return us.twoFactorToken(user)
+	}
+
 	token, err := jwt.NewToken(us.app.Conf.TokenSecret).GenerateToken(user, us.app.Conf.TokenDuration)
 	if err != nil {
 		return nil, fmt.Errorf("auth from request generate token fail: %w", err)
@@ -74,7 +109,142 @@ func (us *userService) Authorize(ctx context.Context, login, pwd string) (*jwt.T
 	return token, nil
 }
 
+// twoFactorToken выдает токен второго шага. Пока действует блокировка после серии
+// неверных кодов, новый токен не выдается, иначе каждый вход давал бы новые попытки.
+func (us *userService) twoFactorToken(user *model.User) (*jwt.Token, error) {
+	if user.TOTPLocked(time.Now()) {
+		return nil, ErrTwoFactorLocked
+	}
+
+	token, err := jwt.NewToken(us.app.Conf.TokenSecret).
+		GenerateScopedToken(user, us.app.Conf.TwoFactorTTL, jwt.ScopeTwoFactor)
+	if err != nil {
+		return nil, fmt.Errorf("two factor generate token fail: %w", err)
+	}
+
+	return token, nil
+}
+
+// rehash обновляет хеш пароля пользователя, если изменились алгоритм или его параметры.
+// Ошибка обновления не должна мешать авторизации, поэтому она только логируется.
+func (us *userService) rehash(ctx context.Context, hasher password.Hasher, user *model.User, pwd string) {
+	if !hasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	pwdHash, err := hasher.Hash(pwd)
+	if err != nil {
+		us.app.Logger(ctx).Warn("rehash password fail", zap.Error(err))
+		return
+	}
+
+	err = us.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		return us.app.Rep.User.UpdatePassword(ctx, user.ID, pwdHash)
+	})
+
+	if err != nil {
+		us.app.Logger(ctx).Warn("rehash password update fail", zap.Error(err))
+		return
+	}
+
+	user.Password = pwdHash
+}
+
+func (us *userService) ValidatePassword(field, login, pwd string) error {
+	conf := us.app.Conf
+	opts := password.PolicyOptions{
+		MinLength:  conf.PwdMinLength,
+		MaxLength:  conf.PwdMaxLength,
+		MaxBytes:   password.MaxBytes(conf.PwdAlgorithm),
+		MinClasses: conf.PwdMinClasses,
+	}
+
+	if us.app.Breached != nil {
+		opts.Breached = us.app.Breached
+	}
+
+	err := password.NewPolicy(opts).Validate(login, pwd)
+
+	var pErr *password.PolicyError
+	if errors.As(err, &pErr) {
+		pErr.Field = field
+	}
+
+	if err != nil {
+		return fmt.Errorf("validate password fail: %w", err)
+	}
+
+	return nil
+}
+
+func (us *userService) ChangePassword(ctx context.Context, user *model.User, current, pwd string) error {
+	hasher, err := us.hasher()
+	if err != nil {
+		return fmt.Errorf("change password init hasher fail: %w", err)
+	}
+
+	ok, err := hasher.Verify(user.Password, current)
+	if err != nil {
+		return fmt.Errorf("change password verify fail: %w", err)
+	}
+
+	if !ok {
+		return ErrPasswordWrongCurrent
+	}
+
+	pwdHash, err := hasher.Hash(pwd)
+	if err != nil {
+		return fmt.Errorf("change password hash fail: %w", err)
+	}
+
+	err = us.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		return us.app.Rep.User.UpdatePassword(ctx, user.ID, pwdHash)
+	})
+
+	if err != nil {
+		return fmt.Errorf("change password transaction fail: %w", err)
+	}
+
+	return nil
+}
+
+func (us *userService) hasher() (password.Hasher, error) {
+	conf := us.app.Conf
+	hasher, err := password.NewHasher(password.Options{
+		Algorithm: conf.PwdAlgorithm,
+		Bcrypt: password.BcryptParams{
+			Cost: conf.BcryptCost,
+		},
+		// Диапазоны параметров проверяются в config.Validate
+		Argon2id: password.Argon2idParams{
+			Memory:      uint32(conf.Argon2Memory),
+			Iterations:  uint32(conf.Argon2Time),
+			Parallelism: uint8(conf.Argon2Threads),
+		},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("new hasher fail: %w", err)
+	}
+
+	return hasher, nil
+}
+
+// GetUser возвращает пользователя для проверки токена, блокировка действует сразу.
 func (us *userService) GetUser(ctx context.Context, login string) (*model.User, error) {
+	user, err := us.findUser(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Blocked {
+		return nil, ErrUserBlocked
+	}
+
+	return user, nil
+}
+
+func (us *userService) findUser(ctx context.Context, login string) (*model.User, error) {
 	var user *model.User
 	var ok bool
 
@@ -95,11 +265,59 @@ func (us *userService) GetUser(ctx context.Context, login string) (*model.User,
 }
 
 func (us *userService) Authorized(ctx context.Context) (*model.User, error) {
-	user, ok := ctx.Value(model.User{}).(*model.User)
+	user, ok := ctx.Value(model.UserCtxKey{}).(*model.User)
 
 	if !ok {
-		return nil, errors.New("user not authorized")
+		return nil, ErrUserNotAuthorized
 	}
 
 	return user, nil
 }
+
+// Authenticate проверяет токен доступа и возвращает пользователя с ролями из токена.
+// scope должен совпадать с областью токена, для обычного входа он пустой.
+func (us *userService) Authenticate(ctx context.Context, token, scope string) (*model.User, error) {
+	parsed := jwt.NewToken(us.app.Conf.TokenSecret).Parse(token)
+
+	tokenScope, err := parsed.GetScope()
+	if err != nil {
+		return nil, fmt.Errorf("authenticate get scope fail: %w", err)
+	}
+
+	if tokenScope != scope {
+		return nil, fmt.Errorf("authenticate %w: token scope mismatch", ErrUserNotAuthorized)
+	}
+
+	login, err := parsed.GetLogin()
+	if err != nil {
+		return nil, fmt.Errorf("authenticate get login fail: %w", err)
+	}
+
+	roles, err := parsed.GetRoles()
+	if err != nil {
+		return nil, fmt.Errorf("authenticate get roles fail: %w", err)
+	}
+
+	user, err := us.GetUser(ctx, login)
+	if err != nil {
+		return nil, fmt.Errorf("authenticate get user fail: %w", err)
+	}
+
+	if scope == jwt.ScopeTwoFactor {
+		issuedAt, err := parsed.GetIssuedAt()
+		if err != nil {
+			return nil, fmt.Errorf("authenticate get issued at fail: %w", err)
+		}
+
+		// После серии неверных кодов токены второго шага, выданные до блокировки, отзываются
+		if user.TOTPLockedAt.Valid && !issuedAt.After(user.TOTPLockedAt.Time.Truncate(time.Second)) {
+			return nil, fmt.Errorf("authenticate %w: %w", ErrUserNotAuthorized, ErrTwoFactorLocked)
+		}
+	}
+
+	// Роли берутся из токена, но отозванные после выдачи токена роли не учитываются
+	authorized := *user
+	authorized.Roles = user.Roles.Intersect(roles)
+
+	return &authorized, nil
+}
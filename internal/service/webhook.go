@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/model"
+)
+
+const (
+	webhookSecretPrefix  = "whsec_"
+	webhookSecretLength  = 24
+	webhookDeliveriesMax = 100
+)
+
+var (
+	ErrWebhookNotFound       = errors.New("webhook not found")
+	ErrWebhookUserNotFound   = errors.New("user not found")
+	ErrWebhookUnknownEvent   = errors.New("webhook unknown event")
+	ErrWebhookJSONDecodeFail = errors.New("json decode fail")
+	ErrWebhookValidateFail   = errors.New("validate fail")
+)
+
+type webhookService struct {
+	app *application.App
+}
+
+func NewWebhookService(app *application.App) *webhookService {
+	return &webhookService{
+		app: app,
+	}
+}
+
+// Create возвращает секрет для проверки подписи, показывается только при создании.
+// Пустой список событий означает подписку на все события. Webhook с login получает
+// только события этого пользователя, без login - события всех пользователей.
+func (whs *webhookService) Create(ctx context.Context, login, url string, events []string) (*model.Webhook, error) {
+	for _, event := range events {
+		if !model.WebhookEventExists(event) {
+			return nil, fmt.Errorf("%w: %s", ErrWebhookUnknownEvent, event)
+		}
+	}
+
+	secret, err := randomString(webhookSecretLength)
+	if err != nil {
+		return nil, fmt.Errorf("webhook generate secret fail: %w", err)
+	}
+
+	hook := model.Webhook{
+		URL:    url,
+		Secret: webhookSecretPrefix + secret,
+		Events: events,
+		Active: true,
+	}
+
+	err = whs.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		if login != "" {
+			user, ok := whs.app.Rep.User.FindByLogin(ctx, login)
+			if !ok {
+				return ErrWebhookUserNotFound
+			}
+			hook.UserID = sql.NullInt64{Int64: int64(user.ID), Valid: true}
+		}
+
+		id, err := whs.app.Rep.Webhook.Create(ctx, int(hook.UserID.Int64), hook.URL, hook.Secret, hook.Events)
+		hook.ID = id
+		return err
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("webhook create transaction fail: %w", err)
+	}
+
+	return &hook, nil
+}
+
+func (whs *webhookService) List(ctx context.Context) ([]model.Webhook, error) {
+	var list []model.Webhook
+	err := whs.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		list = whs.app.Rep.Webhook.List(ctx)
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("webhook list transaction fail: %w", err)
+	}
+
+	return list, nil
+}
+
+func (whs *webhookService) Deactivate(ctx context.Context, id int) error {
+	err := whs.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		ok, err := whs.app.Rep.Webhook.Deactivate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("deactivate fail: %w", err)
+		}
+
+		if !ok {
+			return ErrWebhookNotFound
+		}
+
+		// Отключенный адрес больше не должен получать события, даже уже поставленные в очередь
+		if err := whs.app.Rep.Webhook.CancelPending(ctx, id); err != nil {
+			return fmt.Errorf("deactivate cancel deliveries fail: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("webhook deactivate transaction fail: %w", err)
+	}
+
+	return nil
+}
+
+func (whs *webhookService) Deliveries(ctx context.Context, id int) ([]model.WebhookDelivery, error) {
+	var list []model.WebhookDelivery
+	err := whs.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		list = whs.app.Rep.Webhook.Deliveries(ctx, id, webhookDeliveriesMax)
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("webhook deliveries transaction fail: %w", err)
+	}
+
+	return list, nil
+}
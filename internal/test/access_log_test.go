@@ -0,0 +1,157 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/middleware"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/problem"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/service/jwt"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog(t *testing.T) {
+	type want struct {
+		status    int
+		requestID string
+		userID    int64
+	}
+
+	tests := []struct {
+		name      string
+		requestID string
+		auth      bool
+		want      want
+	}{
+		{
+			name:      "request id propagated",
+			requestID: "req-123",
+			want: want{
+				status:    http.StatusUnauthorized,
+				requestID: "req-123",
+			},
+		},
+		{
+			name:      "invalid request id replaced",
+			requestID: "bad id with spaces",
+			want: want{
+				status: http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "authenticated user logged",
+			auth: true,
+			want: want{
+				status: http.StatusOK,
+				userID: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret:   gofakeit.DigitN(10),
+				LogLevel:      "debug",
+				TokenDuration: 5,
+			}
+
+			core, logs := observer.New(zap.DebugLevel)
+			zLog := zap.New(core)
+
+			user := model.User{
+				ID:    1,
+				Login: gofakeit.Username(),
+			}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).AnyTimes()
+
+			balanceRepo := mock_application.NewMockBalanceRepo(ctrl)
+			balanceRepo.EXPECT().FindByUserID(gomock.Any(), user.ID).Return(&model.Balance{UserID: user.ID}, true).AnyTimes()
+
+			app := application.App{
+				Rep: application.Repository{
+					User:    userRepo,
+					Balance: balanceRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			srv := httptest.NewServer(router.New(&app))
+			defer srv.Close()
+
+			req := resty.New().R().SetError(&problem.Problem{})
+			if tt.requestID != "" {
+				req.SetHeader(middleware.RequestIDHeader, tt.requestID)
+			}
+			if tt.auth {
+				token, err := jwt.NewToken(conf.TokenSecret).GenerateToken(&user, conf.TokenDuration)
+				require.NoError(t, err)
+				req.SetHeader("Authorization", "Bearer "+token.AccessToken)
+			}
+
+			resp, err := req.Get(srv.URL + "/api/user/balance")
+			require.NoError(t, err)
+			require.Equal(t, tt.want.status, resp.StatusCode())
+
+			requestID := resp.Header().Get(middleware.RequestIDHeader)
+			require.NotEmpty(t, requestID)
+			if tt.want.requestID != "" {
+				require.Equal(t, tt.want.requestID, requestID)
+			} else {
+				require.NotEqual(t, tt.requestID, requestID)
+			}
+
+			if p, ok := resp.Error().(*problem.Problem); ok && resp.IsError() {
+				require.Equal(t, requestID, p.RequestID)
+			}
+
+			entries := logs.FilterMessage("http request").All()
+			require.Len(t, entries, 1)
+
+			fields := entries[0].ContextMap()
+			require.Equal(t, requestID, fields["request_id"])
+			require.Equal(t, int64(tt.want.status), fields["status"])
+			require.Equal(t, "/api/user/balance", fields["route"])
+			require.Positive(t, fields["bytes"])
+			require.Contains(t, fields, "latency")
+
+			if tt.want.userID != 0 {
+				require.Equal(t, tt.want.userID, fields["user_id"])
+			} else {
+				require.NotContains(t, fields, "user_id")
+
+				// Строки лога внутри обработки запроса несут тот же request_id
+				authLogs := logs.FilterMessage("header Authorization not found").All()
+				require.Len(t, authLogs, 1)
+				require.Equal(t, requestID, authLogs[0].ContextMap()["request_id"])
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/service/jwt"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminGrantRole(t *testing.T) {
+	type want struct {
+		tokenRoles model.Roles
+		dbRoles    model.Roles
+		grants     int
+		status     int
+	}
+
+	tests := []struct {
+		name string
+		want want
+	}{
+		{
+			name: "admin grant role success",
+			want: want{
+				tokenRoles: model.Roles{model.RoleAdmin},
+				dbRoles:    model.Roles{model.RoleAdmin},
+				grants:     1,
+				status:     http.StatusOK,
+			},
+		},
+		{
+			name: "admin grant role without role",
+			want: want{
+				tokenRoles: model.Roles{},
+				dbRoles:    model.Roles{},
+				status:     http.StatusForbidden,
+			},
+		},
+		{
+			name: "admin grant role without permission",
+			want: want{
+				tokenRoles: model.Roles{model.RoleSupport},
+				dbRoles:    model.Roles{model.RoleSupport},
+				status:     http.StatusForbidden,
+			},
+		},
+		{
+			name: "admin grant role revoked after token issued",
+			want: want{
+				tokenRoles: model.Roles{model.RoleAdmin},
+				dbRoles:    model.Roles{},
+				status:     http.StatusForbidden,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret:   gofakeit.DigitN(10),
+				LogLevel:      "debug",
+				TokenDuration: 5,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			staff := model.User{
+				ID:    1,
+				Login: gofakeit.Username(),
+				Roles: tt.want.dbRoles,
+			}
+
+			customer := model.User{
+				ID:    2,
+				Login: gofakeit.Username(),
+			}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), staff.Login).Return(&staff, true).AnyTimes()
+			userRepo.EXPECT().FindByLogin(gomock.Any(), customer.Login).Return(&customer, true).AnyTimes()
+
+			roleRepo := mock_application.NewMockRoleRepo(ctrl)
+			roleRepo.EXPECT().Grant(gomock.Any(), customer.ID, model.RoleSupport).Return(nil).Times(tt.want.grants)
+
+			app := application.App{
+				Rep: application.Repository{
+					User: userRepo,
+					Role: roleRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			r := router.New(&app)
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			tokenUser := staff
+			tokenUser.Roles = tt.want.tokenRoles
+			token, err := jwt.NewToken(conf.TokenSecret).GenerateToken(&tokenUser, conf.TokenDuration)
+			require.NoError(t, err)
+
+			resp, err := resty.New().
+				R().
+				SetHeader("Content-type", "application/json").
+				SetHeader("Authorization", "Bearer "+token.AccessToken).
+				SetBody(`{"role": "` + model.RoleSupport + `"}`).
+				Post(srv.URL + "/api/admin/users/" + customer.Login + "/roles")
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want.status, resp.StatusCode())
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/problem"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/service/jwt"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminAdjustBalance(t *testing.T) {
+	type want struct {
+		problem string
+		current float64
+		updates int
+		status  int
+	}
+
+	tests := []struct {
+		name  string
+		roles model.Roles
+		body  string
+		want  want
+	}{
+		{
+			name:  "finance credits points",
+			roles: model.Roles{model.RoleFinance},
+			body:  `{"amount": 25.5, "reason": "compensation"}`,
+			want: want{
+				current: 125.5,
+				updates: 1,
+				status:  http.StatusOK,
+			},
+		},
+		{
+			name:  "support has no balance adjust permission",
+			roles: model.Roles{model.RoleSupport},
+			body:  `{"amount": 25.5, "reason": "compensation"}`,
+			want: want{
+				status:  http.StatusForbidden,
+				problem: "access-denied",
+			},
+		},
+		{
+			name:  "debit more than balance",
+			roles: model.Roles{model.RoleAdmin},
+			body:  `{"amount": -500, "reason": "fraud"}`,
+			want: want{
+				status:  http.StatusConflict,
+				problem: "negative-balance",
+			},
+		},
+		{
+			name:  "reason required",
+			roles: model.Roles{model.RoleAdmin},
+			body:  `{"amount": 10}`,
+			want: want{
+				status:  http.StatusBadRequest,
+				problem: "validation-failed",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret:   gofakeit.DigitN(10),
+				LogLevel:      "debug",
+				TokenDuration: 5,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			staff := model.User{ID: 1, Login: gofakeit.Username(), Roles: tt.roles}
+			customer := model.User{ID: 2, Login: gofakeit.Username()}
+			balance := model.Balance{ID: 3, UserID: customer.ID, Current: 100}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), staff.Login).Return(&staff, true).AnyTimes()
+			userRepo.EXPECT().FindByLogin(gomock.Any(), customer.Login).Return(&customer, true).AnyTimes()
+
+			balanceRepo := mock_application.NewMockBalanceRepo(ctrl)
+			balanceRepo.EXPECT().AddCurrent(gomock.Any(), customer.ID, gomock.Any()).
+				DoAndReturn(addCurrent(balance)).
+				MaxTimes(1)
+
+			eventRepo := mock_application.NewMockEventRepo(ctrl)
+			eventRepo.EXPECT().Create(gomock.Any(), customer.ID, model.EventBalance, gomock.Any()).
+				Return(nil).
+				Times(tt.want.updates)
+
+			auditRepo := mock_application.NewMockAuditRepo(ctrl)
+			auditRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, record model.AuditRecord) error {
+					require.Equal(t, "user:"+staff.Login, record.Actor)
+					require.Equal(t, model.AuditBalanceAdjust, record.Action)
+					require.Equal(t, "compensation", record.Reason)
+					return nil
+				}).
+				Times(tt.want.updates)
+
+			app := application.App{
+				Rep: application.Repository{
+					User:    userRepo,
+					Balance: balanceRepo,
+					Event:   eventRepo,
+					Audit:   auditRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			srv := httptest.NewServer(router.New(&app))
+			defer srv.Close()
+
+			token, err := jwt.NewToken(conf.TokenSecret).GenerateToken(&staff, conf.TokenDuration)
+			require.NoError(t, err)
+
+			result := model.Balance{}
+			prob := problem.Problem{}
+			resp, err := resty.New().
+				R().
+				SetHeader("Content-type", "application/json").
+				SetHeader("Authorization", "Bearer "+token.AccessToken).
+				SetBody(tt.body).
+				SetResult(&result).
+				SetError(&prob).
+				Post(srv.URL + "/api/admin/users/" + customer.Login + "/balance/adjustments")
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want.status, resp.StatusCode())
+
+			if tt.want.problem != "" {
+				require.Equal(t, "urn:gophermart:problem:"+tt.want.problem, prob.Type)
+				return
+			}
+
+			require.Equal(t, tt.want.current, result.Current)
+		})
+	}
+}
+
+func TestAdminRecheckOrder(t *testing.T) {
+	type want struct {
+		problem string
+		status  int
+		checks  int
+		audits  int
+	}
+
+	tests := []struct {
+		name       string
+		status     model.OrderStatus
+		accrualErr error
+		want       want
+	}{
+		{
+			name:   "recheck new order",
+			status: model.OrderStatusNew,
+			want: want{
+				status: http.StatusOK,
+				checks: 1,
+				audits: 1,
+			},
+		},
+		{
+			name:       "accrual system unavailable",
+			status:     model.OrderStatusProcessing,
+			accrualErr: errors.New("connection refused"),
+			want: want{
+				status:  http.StatusBadGateway,
+				problem: "about:blank",
+				checks:  1,
+				audits:  1,
+			},
+		},
+		{
+			name:   "recheck processed order",
+			status: model.OrderStatusProcessed,
+			want: want{
+				status:  http.StatusConflict,
+				problem: "urn:gophermart:problem:order-processed",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret:   gofakeit.DigitN(10),
+				LogLevel:      "debug",
+				TokenDuration: 5,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			staff := model.User{ID: 1, Login: gofakeit.Username(), Roles: model.Roles{model.RoleSupport}}
+			order := model.Order{ID: 5, UserID: 2, Number: "45031620082273", Status: tt.status}
+			checked := order
+			checked.Status = model.OrderStatusProcessing
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), staff.Login).Return(&staff, true).AnyTimes()
+
+			orderRepo := mock_application.NewMockOrderRepo(ctrl)
+			gomock.InOrder(
+				orderRepo.EXPECT().FindByNumber(gomock.Any(), order.Number).Return(&order, true),
+				orderRepo.EXPECT().FindByNumber(gomock.Any(), order.Number).Return(&checked, true).AnyTimes(),
+			)
+
+			auditRepo := mock_application.NewMockAuditRepo(ctrl)
+			auditRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, record model.AuditRecord) error {
+					require.Equal(t, "user:"+staff.Login, record.Actor)
+					require.Equal(t, model.AuditOrderRecheck, record.Action)
+					return nil
+				}).
+				Times(tt.want.audits)
+
+			accrual := mock_application.NewMockAccrualChecker(ctrl)
+			accrual.EXPECT().Recheck(gomock.Any(), gomock.Any()).Return(tt.accrualErr).Times(tt.want.checks)
+
+			app := application.App{
+				Rep: application.Repository{
+					User:  userRepo,
+					Order: orderRepo,
+					Audit: auditRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+				Accrual:   accrual,
+			}
+
+			srv := httptest.NewServer(router.New(&app))
+			defer srv.Close()
+
+			token, err := jwt.NewToken(conf.TokenSecret).GenerateToken(&staff, conf.TokenDuration)
+			require.NoError(t, err)
+
+			result := map[string]any{}
+			prob := problem.Problem{}
+			resp, err := resty.New().
+				R().
+				SetHeader("Content-type", "application/json").
+				SetHeader("Authorization", "Bearer "+token.AccessToken).
+				SetBody(`{"reason": "customer ticket 17"}`).
+				SetResult(&result).
+				SetError(&prob).
+				Post(srv.URL + "/api/admin/orders/" + order.Number + "/recheck")
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want.status, resp.StatusCode())
+
+			if tt.want.problem != "" {
+				require.Equal(t, tt.want.problem, prob.Type)
+				return
+			}
+
+			require.Equal(t, model.OrderStatusProcessing.String(), result["status"])
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/arefev/gophermart/internal/application"
@@ -35,8 +37,7 @@ func TestUserAuthSuccess(t *testing.T) {
 		require.NoError(t, err)
 
 		pwd := gofakeit.Password(true, true, true, true, false, 10)
-		pwdHash, err := password.Encrypt(pwd)
-		require.NoError(t, err)
+		pwdHash := hashPassword(t, pwd)
 
 		user := model.User{
 			Login:    gofakeit.Username(),
@@ -99,8 +100,7 @@ func TestUserAuthStatusUnauth(t *testing.T) {
 
 		pwd := gofakeit.Password(true, true, true, true, false, 10)
 		otherPwd := gofakeit.Password(true, true, true, true, false, 10)
-		pwdHash, err := password.Encrypt(pwd)
-		require.NoError(t, err)
+		pwdHash := hashPassword(t, pwd)
 
 		user := model.User{
 			Login:    gofakeit.Username(),
@@ -162,8 +162,7 @@ func TestUserAuthStatusBadRequest(t *testing.T) {
 		require.NoError(t, err)
 
 		pwd := gofakeit.Password(true, true, true, true, false, 10)
-		pwdHash, err := password.Encrypt(pwd)
-		require.NoError(t, err)
+		pwdHash := hashPassword(t, pwd)
 
 		user := model.User{
 			Login:    gofakeit.Username(),
@@ -209,3 +208,154 @@ func TestUserAuthStatusBadRequest(t *testing.T) {
 		require.NotContains(t, hAuth, "Bearer ")
 	})
 }
+
+func TestUserAuthRehashPassword(t *testing.T) {
+	t.Run("authorize rehash password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{
+			TokenSecret:  gofakeit.DigitN(10),
+			LogLevel:     "debug",
+			PwdAlgorithm: password.AlgorithmArgon2id,
+			Argon2Memory: 1024,
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		pwd := gofakeit.Password(true, true, true, true, false, 10)
+		pwdHash := hashPassword(t, pwd)
+
+		user := model.User{
+			ID:       1,
+			Login:    gofakeit.Username(),
+			Password: pwdHash,
+		}
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		userRepo := mock_application.NewMockUserRepo(ctrl)
+		userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).MaxTimes(1)
+		userRepo.EXPECT().UpdatePassword(gomock.Any(), user.ID, gomock.Any()).
+			Do(func(_ context.Context, _ int, hash string) {
+				require.True(t, strings.HasPrefix(hash, "$argon2id$"))
+			}).
+			Return(nil).
+			Times(1)
+
+		app := application.App{
+			Rep: application.Repository{
+				User: userRepo,
+			},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+		}
+
+		r := router.New(&app)
+		srv := httptest.NewServer(r)
+		defer srv.Close()
+
+		body := `{
+			"login": "` + user.Login + `",
+			"password": "` + pwd + `"
+		}`
+
+		resp, err := resty.New().
+			R().
+			SetHeader("Content-type", "application/json").
+			SetBody(body).
+			Post(srv.URL + "/api/user/login")
+
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+		require.Contains(t, resp.Header().Get("Authorization"), "Bearer ")
+	})
+}
+
+func TestUserAuthBlocked(t *testing.T) {
+	type want struct {
+		status int
+	}
+
+	tests := []struct {
+		name      string
+		wrongPass bool
+		want      want
+	}{
+		{
+			name: "authorize blocked user",
+			want: want{status: http.StatusForbidden},
+		},
+		{
+			// Без верного пароля ответ не отличается от несуществующего пользователя
+			name:      "authorize blocked user wrong password",
+			wrongPass: true,
+			want:      want{status: http.StatusUnauthorized},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret: gofakeit.DigitN(10),
+				LogLevel:    "debug",
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			pwd := gofakeit.Password(true, true, true, true, false, 10)
+			pwdHash := hashPassword(t, pwd)
+
+			user := model.User{
+				Login:    gofakeit.Username(),
+				Password: pwdHash,
+				Blocked:  true,
+			}
+
+			if tt.wrongPass {
+				pwd = gofakeit.Password(true, true, true, true, false, 10)
+			}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).MaxTimes(1)
+
+			app := application.App{
+				Rep: application.Repository{
+					User: userRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			srv := httptest.NewServer(router.New(&app))
+			defer srv.Close()
+
+			resp, err := resty.New().
+				R().
+				SetHeader("Content-type", "application/json").
+				SetBody(`{"login": "` + user.Login + `", "password": "` + pwd + `"}`).
+				Post(srv.URL + "/api/user/login")
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want.status, resp.StatusCode())
+			require.Empty(t, resp.Header().Get("Authorization"))
+		})
+	}
+}
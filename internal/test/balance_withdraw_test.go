@@ -12,7 +12,6 @@ import (
 	"github.com/arefev/gophermart/internal/logger"
 	"github.com/arefev/gophermart/internal/model"
 	"github.com/arefev/gophermart/internal/router"
-	"github.com/arefev/gophermart/internal/service/password"
 	"github.com/arefev/gophermart/internal/trm"
 	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
 	"github.com/go-resty/resty/v2"
@@ -37,8 +36,7 @@ func TestBalanceWithdrawSuccess(t *testing.T) {
 		require.NoError(t, err)
 
 		pwd := gofakeit.Password(true, true, true, true, false, 10)
-		pwdHash, err := password.Encrypt(pwd)
-		require.NoError(t, err)
+		pwdHash := hashPassword(t, pwd)
 
 		user := model.User{
 			ID:       1,
@@ -74,11 +72,29 @@ func TestBalanceWithdrawSuccess(t *testing.T) {
 		balanceRepo.EXPECT().FindByUserID(gomock.Any(), user.ID).Return(&balance, true).MaxTimes(1)
 		balanceRepo.EXPECT().UpdateByID(gomock.Any(), balance.ID, newCurrent, newWithdrawn).Return(nil).MaxTimes(1)
 
+		webhookRepo := mock_application.NewMockWebhookRepo(ctrl)
+		webhookRepo.EXPECT().Enqueue(gomock.Any(), user.ID, model.WebhookEventPointsWithdrawn, gomock.Any()).
+			Return(nil).
+			MaxTimes(1)
+
+		// Списание меняет баланс, поток событий получает новое значение
+		eventRepo := mock_application.NewMockEventRepo(ctrl)
+		eventRepo.EXPECT().
+			Create(gomock.Any(), user.ID, model.EventBalance, `{"current":400,"withdrawn":300}`).
+			Return(nil).
+			Times(1)
+
+		outboxRepo := mock_application.NewMockOutboxRepo(ctrl)
+		outboxRepo.EXPECT().Add(gomock.Any(), model.OutboxPointsWithdrawn, gomock.Any()).Return(nil).MaxTimes(1)
+
 		app := application.App{
 			Rep: application.Repository{
 				User:    userRepo,
 				Order:   orderRepo,
 				Balance: balanceRepo,
+				Event:   eventRepo,
+				Webhook: webhookRepo,
+				Outbox:  outboxRepo,
 			},
 			TrManager: trManager,
 			Log:       zLog,
@@ -139,8 +155,7 @@ func TestBalanceWithdrawStatusPayment(t *testing.T) {
 		require.NoError(t, err)
 
 		pwd := gofakeit.Password(true, true, true, true, false, 10)
-		pwdHash, err := password.Encrypt(pwd)
-		require.NoError(t, err)
+		pwdHash := hashPassword(t, pwd)
 
 		user := model.User{
 			ID:       1,
@@ -233,8 +248,7 @@ func TestBalanceWithdrawStatusUnprocessable(t *testing.T) {
 		require.NoError(t, err)
 
 		pwd := gofakeit.Password(true, true, true, true, false, 10)
-		pwdHash, err := password.Encrypt(pwd)
-		require.NoError(t, err)
+		pwdHash := hashPassword(t, pwd)
 
 		user := model.User{
 			ID:       1,
@@ -11,7 +11,6 @@ import (
 	"github.com/arefev/gophermart/internal/logger"
 	"github.com/arefev/gophermart/internal/model"
 	"github.com/arefev/gophermart/internal/router"
-	"github.com/arefev/gophermart/internal/service/password"
 	"github.com/arefev/gophermart/internal/trm"
 	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
 	"github.com/go-resty/resty/v2"
@@ -36,8 +35,7 @@ func TestBalanceWithdrawalsSuccess(t *testing.T) {
 		require.NoError(t, err)
 
 		pwd := gofakeit.Password(true, true, true, true, false, 10)
-		pwdHash, err := password.Encrypt(pwd)
-		require.NoError(t, err)
+		pwdHash := hashPassword(t, pwd)
 
 		user := model.User{
 			ID:       1,
@@ -124,8 +122,7 @@ func TestBalanceWithdrawalsStatusNoContent(t *testing.T) {
 		require.NoError(t, err)
 
 		pwd := gofakeit.Password(true, true, true, true, false, 10)
-		pwdHash, err := password.Encrypt(pwd)
-		require.NoError(t, err)
+		pwdHash := hashPassword(t, pwd)
 
 		user := model.User{
 			ID:       1,
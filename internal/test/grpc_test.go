@@ -0,0 +1,686 @@
+package test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/events"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/rpc"
+	"github.com/arefev/gophermart/internal/rpc/pb"
+	"github.com/arefev/gophermart/internal/service/jwt"
+	"github.com/arefev/gophermart/internal/service/totp"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/golang/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+// newGRPCClient поднимает gRPC сервер в памяти через bufconn.
+func newGRPCClient(t *testing.T, app *application.App) pb.GophermartClient {
+	t.Helper()
+
+	const bufSize = 1024 * 1024
+	lis := bufconn.Listen(bufSize)
+	srv := rpc.New(app)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return pb.NewGophermartClient(conn)
+}
+
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+func TestGRPCLoginAndBalance(t *testing.T) {
+	type want struct {
+		token string
+		code  codes.Code
+	}
+
+	tests := []struct {
+		name string
+		want want
+	}{
+		{
+			name: "grpc balance with login token",
+			want: want{code: codes.OK},
+		},
+		{
+			name: "grpc balance without token",
+			want: want{token: "-", code: codes.Unauthenticated},
+		},
+		{
+			name: "grpc balance with bad token",
+			want: want{token: "bad", code: codes.Unauthenticated},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret:   gofakeit.DigitN(10),
+				LogLevel:      "debug",
+				TokenDuration: 5,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			pwd := gofakeit.Password(true, true, true, true, false, 10)
+			pwdHash := hashPassword(t, pwd)
+
+			user := model.User{
+				ID:       1,
+				Login:    gofakeit.Username(),
+				Password: pwdHash,
+			}
+
+			balance := model.Balance{ID: 1, UserID: user.ID, Current: 500, Withdrawn: 42}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).AnyTimes()
+
+			balanceRepo := mock_application.NewMockBalanceRepo(ctrl)
+			balanceRepo.EXPECT().FindByUserID(gomock.Any(), user.ID).Return(&balance, true).MaxTimes(1)
+
+			app := application.App{
+				Rep: application.Repository{
+					User:    userRepo,
+					Balance: balanceRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			client := newGRPCClient(t, &app)
+			ctx := context.Background()
+
+			auth, err := client.Login(ctx, &pb.LoginRequest{Login: user.Login, Password: pwd})
+			require.NoError(t, err)
+			require.False(t, auth.GetTwoFactorRequired())
+
+			switch tt.want.token {
+			case "":
+				ctx = withToken(ctx, auth.GetAccessToken())
+			case "-":
+			default:
+				ctx = withToken(ctx, tt.want.token)
+			}
+
+			res, err := client.GetBalance(ctx, &pb.GetBalanceRequest{})
+			require.Equal(t, tt.want.code, status.Code(err))
+			if tt.want.code == codes.OK {
+				require.InDelta(t, balance.Current, res.GetCurrent(), 0.001)
+				require.InDelta(t, balance.Withdrawn, res.GetWithdrawn(), 0.001)
+			}
+		})
+	}
+}
+
+func TestGRPCLoginTwoFactor(t *testing.T) {
+	t.Run("grpc login with second factor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{
+			TokenSecret:       gofakeit.DigitN(10),
+			LogLevel:          "debug",
+			TokenDuration:     5,
+			TwoFactorTTL:      5,
+			TwoFactorAttempts: 3,
+			TwoFactorLockout:  15,
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		pwd := gofakeit.Password(true, true, true, true, false, 10)
+		pwdHash := hashPassword(t, pwd)
+
+		secret, err := totp.GenerateSecret()
+		require.NoError(t, err)
+
+		user := model.User{
+			ID:          1,
+			Login:       gofakeit.Username(),
+			Password:    pwdHash,
+			TOTPSecret:  sql.NullString{String: secret, Valid: true},
+			TOTPEnabled: true,
+		}
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		userRepo := mock_application.NewMockUserRepo(ctrl)
+		userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).AnyTimes()
+		userRepo.EXPECT().TOTPFailed(gomock.Any(), user.ID, conf.TwoFactorAttempts, 15*time.Minute).
+			Return(false, nil).
+			Times(1)
+		userRepo.EXPECT().UseTOTPStep(gomock.Any(), user.ID, gomock.Any()).Return(true, nil).Times(1)
+
+		recoveryRepo := mock_application.NewMockRecoveryCodeRepo(ctrl)
+		recoveryRepo.EXPECT().Use(gomock.Any(), user.ID, gomock.Any()).Return(false, nil).Times(1)
+
+		app := application.App{
+			Rep: application.Repository{
+				User:         userRepo,
+				RecoveryCode: recoveryRepo,
+			},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+		}
+
+		client := newGRPCClient(t, &app)
+		ctx := context.Background()
+
+		pending, err := client.Login(ctx, &pb.LoginRequest{Login: user.Login, Password: pwd})
+		require.NoError(t, err)
+		require.True(t, pending.GetTwoFactorRequired())
+
+		// Токен второго шага не дает доступа к остальным методам
+		_, err = client.GetBalance(withToken(ctx, pending.GetAccessToken()), &pb.GetBalanceRequest{})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = client.LoginTwoFactor(ctx, &pb.LoginTwoFactorRequest{Code: "zzzzz-zzzzz"})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		pendingCtx := withToken(ctx, pending.GetAccessToken())
+		_, err = client.LoginTwoFactor(pendingCtx, &pb.LoginTwoFactorRequest{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.LoginTwoFactor(pendingCtx, &pb.LoginTwoFactorRequest{Code: "zzzzz-zzzzz"})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		code, err := totp.Code(secret, time.Now())
+		require.NoError(t, err)
+
+		auth, err := client.LoginTwoFactor(pendingCtx, &pb.LoginTwoFactorRequest{Code: code})
+		require.NoError(t, err)
+		require.False(t, auth.GetTwoFactorRequired())
+
+		// Полный токен не подходит для второго шага
+		_, err = client.LoginTwoFactor(withToken(ctx, auth.GetAccessToken()), &pb.LoginTwoFactorRequest{Code: code})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestGRPCUploadOrder(t *testing.T) {
+	type want struct {
+		owner    int
+		number   string
+		creates  int
+		uploaded bool
+		code     codes.Code
+	}
+
+	tests := []struct {
+		name string
+		want want
+	}{
+		{
+			name: "grpc upload order accepted",
+			want: want{number: "45031620082273", creates: 1, code: codes.OK},
+		},
+		{
+			name: "grpc upload order already uploaded",
+			want: want{number: "45031620082273", owner: 1, uploaded: true, code: codes.OK},
+		},
+		{
+			name: "grpc upload order uploaded by other user",
+			want: want{number: "45031620082273", owner: 2, code: codes.AlreadyExists},
+		},
+		{
+			name: "grpc upload order luhn invalid",
+			want: want{number: "45031620082274", code: codes.InvalidArgument},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret:   gofakeit.DigitN(10),
+				LogLevel:      "debug",
+				TokenDuration: 5,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			user := model.User{
+				ID:    1,
+				Login: gofakeit.Username(),
+			}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).AnyTimes()
+
+			orderRepo := mock_application.NewMockOrderRepo(ctrl)
+			if tt.want.owner > 0 {
+				orderRepo.EXPECT().FindByNumber(gomock.Any(), tt.want.number).
+					Return(&model.Order{UserID: tt.want.owner}, true).
+					Times(1)
+			} else {
+				orderRepo.EXPECT().FindByNumber(gomock.Any(), tt.want.number).Return(nil, false).MaxTimes(1)
+			}
+			orderRepo.EXPECT().Create(gomock.Any(), user.ID, model.OrderStatusNew, tt.want.number).
+				Return(nil).
+				Times(tt.want.creates)
+
+			outboxRepo := mock_application.NewMockOutboxRepo(ctrl)
+			outboxRepo.EXPECT().Add(gomock.Any(), model.OutboxOrderUploaded, gomock.Any()).
+				Return(nil).
+				Times(tt.want.creates)
+
+			app := application.App{
+				Rep: application.Repository{
+					User:   userRepo,
+					Order:  orderRepo,
+					Outbox: outboxRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			token, err := jwt.NewToken(conf.TokenSecret).GenerateToken(&user, conf.TokenDuration)
+			require.NoError(t, err)
+
+			client := newGRPCClient(t, &app)
+			ctx := withToken(context.Background(), token.AccessToken)
+
+			res, err := client.UploadOrder(ctx, &pb.UploadOrderRequest{Number: tt.want.number})
+			require.Equal(t, tt.want.code, status.Code(err))
+			require.Equal(t, tt.want.uploaded, res.GetAlreadyUploaded())
+		})
+	}
+}
+
+func TestGRPCWithdrawNotEnoughBalance(t *testing.T) {
+	t.Run("grpc withdraw not enough balance", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{
+			TokenSecret:   gofakeit.DigitN(10),
+			LogLevel:      "debug",
+			TokenDuration: 5,
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		user := model.User{
+			ID:    1,
+			Login: gofakeit.Username(),
+		}
+
+		balance := model.Balance{ID: 1, UserID: user.ID, Current: 10}
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		userRepo := mock_application.NewMockUserRepo(ctrl)
+		userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).AnyTimes()
+
+		balanceRepo := mock_application.NewMockBalanceRepo(ctrl)
+		balanceRepo.EXPECT().FindByUserID(gomock.Any(), user.ID).Return(&balance, true).Times(1)
+
+		app := application.App{
+			Rep: application.Repository{
+				User:    userRepo,
+				Balance: balanceRepo,
+			},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+		}
+
+		token, err := jwt.NewToken(conf.TokenSecret).GenerateToken(&user, conf.TokenDuration)
+		require.NoError(t, err)
+
+		client := newGRPCClient(t, &app)
+		ctx := withToken(context.Background(), token.AccessToken)
+
+		_, err = client.Withdraw(ctx, &pb.WithdrawRequest{Order: "45031620082273", Sum: 100})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
+func TestGRPCWatchOrders(t *testing.T) {
+	t.Run("grpc watch orders with resume", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{
+			TokenSecret:   gofakeit.DigitN(10),
+			LogLevel:      "debug",
+			TokenDuration: 5,
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		user := model.User{
+			ID:    1,
+			Login: gofakeit.Username(),
+		}
+
+		missed := model.Event{ID: 6, UserID: user.ID, Type: model.EventOrder, Payload: `{"number":"1","status":"PROCESSING"}`}
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		userRepo := mock_application.NewMockUserRepo(ctrl)
+		userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).AnyTimes()
+
+		eventRepo := mock_application.NewMockEventRepo(ctrl)
+		eventRepo.EXPECT().After(gomock.Any(), user.ID, int64(5), gomock.Any()).Return([]model.Event{missed}).Times(1)
+
+		hub := events.NewHub()
+		app := application.App{
+			Rep: application.Repository{
+				User:  userRepo,
+				Event: eventRepo,
+			},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+			Events:    hub,
+		}
+
+		token, err := jwt.NewToken(conf.TokenSecret).GenerateToken(&user, conf.TokenDuration)
+		require.NoError(t, err)
+
+		client := newGRPCClient(t, &app)
+		stream, err := client.WatchOrders(withToken(ctx, token.AccessToken), &pb.WatchOrdersRequest{LastEventId: 5})
+		require.NoError(t, err)
+
+		ev, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, int64(6), ev.GetId())
+		require.Equal(t, "PROCESSING", ev.GetStatus())
+
+		// События баланса, другого пользователя и повтор уже отправленного не доходят до клиента
+		hub.Publish(model.Event{ID: 7, UserID: 2, Type: model.EventOrder, Payload: `{}`})
+		hub.Publish(missed)
+		hub.Publish(model.Event{ID: 8, UserID: user.ID, Type: model.EventBalance, Payload: `{"current":500}`})
+		hub.Publish(model.Event{
+			ID:      9,
+			UserID:  user.ID,
+			Type:    model.EventOrder,
+			Payload: `{"number":"1","status":"PROCESSED","accrual":500}`,
+		})
+
+		ev, err = stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, int64(9), ev.GetId())
+		require.Equal(t, "PROCESSED", ev.GetStatus())
+		require.InDelta(t, 500, ev.GetAccrual(), 0.001)
+	})
+}
+
+func TestGRPCWatchOrdersUnauthenticated(t *testing.T) {
+	t.Run("grpc watch orders without token", func(t *testing.T) {
+		conf := config.Config{
+			TokenSecret: gofakeit.DigitN(10),
+			LogLevel:    "debug",
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		app := application.App{
+			Log:    zLog,
+			Conf:   &conf,
+			Events: events.NewHub(),
+		}
+
+		client := newGRPCClient(t, &app)
+		stream, err := client.WatchOrders(context.Background(), &pb.WatchOrdersRequest{})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestGRPCServeShutdownWithOpenWatch(t *testing.T) {
+	t.Run("grpc serve stops with open watch stream", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{
+			TokenSecret:     gofakeit.DigitN(10),
+			LogLevel:        "debug",
+			TokenDuration:   5,
+			ShutdownTimeout: 30,
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		user := model.User{
+			ID:    1,
+			Login: gofakeit.Username(),
+		}
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		userRepo := mock_application.NewMockUserRepo(ctrl)
+		userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).AnyTimes()
+
+		hub := events.NewHub()
+		app := application.App{
+			Rep:       application.Repository{User: userRepo},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+			Events:    hub,
+		}
+
+		token, err := jwt.NewToken(conf.TokenSecret).GenerateToken(&user, conf.TokenDuration)
+		require.NoError(t, err)
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := lis.Addr().String()
+		require.NoError(t, lis.Close())
+
+		serveCtx, stop := context.WithCancel(context.Background())
+		defer stop()
+
+		served := make(chan error, 1)
+		go func() {
+			served <- rpc.Serve(serveCtx, &app, address, nil)
+		}()
+
+		conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		client := pb.NewGophermartClient(conn)
+		stream, err := client.WatchOrders(
+			withToken(ctx, token.AccessToken),
+			&pb.WatchOrdersRequest{},
+			grpc.WaitForReady(true),
+		)
+		require.NoError(t, err)
+
+		// Первое полученное событие подтверждает, что поток открыт на сервере,
+		// повторы с тем же ID сервер пропускает
+		received := make(chan struct{})
+		go func() {
+			for {
+				select {
+				case <-received:
+					return
+				case <-time.After(10 * time.Millisecond):
+					hub.Publish(model.Event{ID: 1, UserID: user.ID, Type: model.EventOrder, Payload: `{}`})
+				}
+			}
+		}()
+		_, err = stream.Recv()
+		close(received)
+		require.NoError(t, err)
+
+		stop()
+
+		select {
+		case err := <-served:
+			require.NoError(t, err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("grpc server did not stop with open watch stream")
+		}
+
+		_, err = stream.Recv()
+		require.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
+
+func TestGRPCServeTLS(t *testing.T) {
+	t.Run("grpc serve uses http tls config", func(t *testing.T) {
+		conf := config.Config{
+			TokenSecret:     gofakeit.DigitN(10),
+			LogLevel:        "debug",
+			ShutdownTimeout: 1,
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		app := application.App{
+			Log:  zLog,
+			Conf: &conf,
+		}
+
+		// Сертификат берется у тестового HTTPS сервера, он выдан на 127.0.0.1
+		certSrv := httptest.NewTLSServer(http.NotFoundHandler())
+		defer certSrv.Close()
+
+		roots := x509.NewCertPool()
+		roots.AddCert(certSrv.Certificate())
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := lis.Addr().String()
+		require.NoError(t, lis.Close())
+
+		serveCtx, stop := context.WithCancel(context.Background())
+		defer stop()
+
+		served := make(chan error, 1)
+		go func() {
+			served <- rpc.Serve(serveCtx, &app, address, certSrv.TLS.Clone())
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		call := func(creds credentials.TransportCredentials) error {
+			conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(creds))
+			require.NoError(t, err)
+			defer func() {
+				_ = conn.Close()
+			}()
+
+			_, err = pb.NewGophermartClient(conn).GetBalance(ctx, &pb.GetBalanceRequest{}, grpc.WaitForReady(true))
+			return err
+		}
+
+		// Запрос без токена доходит до сервера только после TLS рукопожатия
+		err = call(credentials.NewTLS(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		plainCtx, plainCancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer plainCancel()
+
+		conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		_, err = pb.NewGophermartClient(conn).GetBalance(plainCtx, &pb.GetBalanceRequest{})
+		require.Equal(t, codes.Unavailable, status.Code(err))
+
+		stop()
+		require.NoError(t, <-served)
+	})
+}
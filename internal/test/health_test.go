@@ -0,0 +1,100 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/health"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/go-resty/resty/v2"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthProbes(t *testing.T) {
+	type want struct {
+		status int
+		report string
+		worker string
+	}
+
+	tests := []struct {
+		name     string
+		beat     bool
+		shutdown bool
+		want     want
+	}{
+		{
+			name: "ready",
+			beat: true,
+			want: want{
+				status: http.StatusOK,
+				report: health.StatusOK,
+				worker: health.StatusOK,
+			},
+		},
+		{
+			name: "worker never started",
+			want: want{
+				status: http.StatusServiceUnavailable,
+				report: health.StatusFail,
+				worker: health.StatusFail,
+			},
+		},
+		{
+			name:     "shutting down",
+			beat:     true,
+			shutdown: true,
+			want: want{
+				status: http.StatusServiceUnavailable,
+				report: health.StatusShuttingDown,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := config.Config{
+				LogLevel: "debug",
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			app := application.App{
+				Log:    zLog,
+				Conf:   &conf,
+				Health: health.New(),
+			}
+			app.Health.Register("worker", health.Fresh(app.Health.Worker(), time.Minute))
+
+			if tt.beat {
+				app.Health.Worker().Beat()
+			}
+			if tt.shutdown {
+				app.Health.Shutdown()
+			}
+
+			srv := httptest.NewServer(router.NewAdminListener(&app))
+			defer srv.Close()
+
+			live := health.Report{}
+			resp, err := resty.New().R().SetResult(&live).Get(srv.URL + "/healthz")
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode())
+			require.Equal(t, health.StatusOK, live.Status)
+
+			ready := health.Report{}
+			resp, err = resty.New().R().SetResult(&ready).SetError(&ready).Get(srv.URL + "/readyz")
+			require.NoError(t, err)
+			require.Equal(t, tt.want.status, resp.StatusCode())
+			require.Equal(t, tt.want.report, ready.Status)
+			require.Equal(t, tt.want.worker, ready.Checks["worker"].Status)
+		})
+	}
+}
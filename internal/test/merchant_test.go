@@ -0,0 +1,232 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/response"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/service/jwt"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerchantOrderCreate(t *testing.T) {
+	type want struct {
+		scopes  []string
+		revoked bool
+		blocked bool
+		creates int
+		status  int
+	}
+
+	tests := []struct {
+		name string
+		want want
+	}{
+		{
+			name: "merchant order create success",
+			want: want{
+				scopes:  []string{model.ScopeOrdersCreate},
+				creates: 1,
+				status:  http.StatusAccepted,
+			},
+		},
+		{
+			name: "merchant order create blocked user",
+			want: want{
+				scopes:  []string{model.ScopeOrdersCreate},
+				blocked: true,
+				status:  http.StatusForbidden,
+			},
+		},
+		{
+			name: "merchant order create revoked key",
+			want: want{
+				scopes:  []string{model.ScopeOrdersCreate},
+				revoked: true,
+				status:  http.StatusUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret:   gofakeit.DigitN(10),
+				LogLevel:      "debug",
+				TokenDuration: 5,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			staff := model.User{
+				ID:    1,
+				Login: gofakeit.Username(),
+				Roles: model.Roles{model.RoleAdmin},
+			}
+
+			customer := model.User{
+				ID:      2,
+				Login:   gofakeit.Username(),
+				Blocked: tt.want.blocked,
+			}
+
+			orderNumber := "45031620082273"
+			key := model.APIKey{ID: 1}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), staff.Login).Return(&staff, true).AnyTimes()
+			userRepo.EXPECT().FindByLogin(gomock.Any(), customer.Login).Return(&customer, true).AnyTimes()
+
+			apiKeyRepo := mock_application.NewMockAPIKeyRepo(ctrl)
+			apiKeyRepo.EXPECT().Create(gomock.Any(), "shop", gomock.Any(), gomock.Any(), tt.want.scopes).
+				DoAndReturn(func(_ context.Context, name, prefix, hash string, scopes []string) (int, error) {
+					key.Name = name
+					key.Prefix = prefix
+					key.KeyHash = hash
+					key.Scopes = scopes
+					if tt.want.revoked {
+						key.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+					}
+					return key.ID, nil
+				}).
+				Times(1)
+			apiKeyRepo.EXPECT().FindByPrefix(gomock.Any(), gomock.Any()).Return(&key, true).MaxTimes(1)
+			apiKeyRepo.EXPECT().TouchLastUsed(gomock.Any(), key.ID).Return(nil).MaxTimes(1)
+
+			orderRepo := mock_application.NewMockOrderRepo(ctrl)
+			orderRepo.EXPECT().FindByNumber(gomock.Any(), orderNumber).Return(nil, false).Times(tt.want.creates)
+			orderRepo.EXPECT().Create(gomock.Any(), customer.ID, model.OrderStatusNew, orderNumber).
+				Return(nil).
+				Times(tt.want.creates)
+
+			outboxRepo := mock_application.NewMockOutboxRepo(ctrl)
+			outboxRepo.EXPECT().Add(gomock.Any(), model.OutboxOrderUploaded, gomock.Any()).Return(nil).Times(tt.want.creates)
+
+			app := application.App{
+				Rep: application.Repository{
+					User:   userRepo,
+					Order:  orderRepo,
+					APIKey: apiKeyRepo,
+					Outbox: outboxRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			r := router.New(&app)
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			token, err := jwt.NewToken(conf.TokenSecret).GenerateToken(&staff, conf.TokenDuration)
+			require.NoError(t, err)
+
+			created := response.APIKey{}
+			resp, err := resty.New().
+				R().
+				SetHeader("Content-type", "application/json").
+				SetHeader("Authorization", "Bearer "+token.AccessToken).
+				SetBody(`{"name": "shop", "scopes": ["` + model.ScopeOrdersCreate + `"]}`).
+				SetResult(&created).
+				Post(srv.URL + "/api/admin/api-keys")
+
+			require.NoError(t, err)
+			require.Equal(t, http.StatusCreated, resp.StatusCode())
+			require.Contains(t, created.Key, "gm_"+key.Prefix+"_")
+
+			resp, err = resty.New().
+				R().
+				SetHeader("Content-type", "application/json").
+				SetHeader("X-API-Key", created.Key).
+				SetBody(`{"login": "` + customer.Login + `", "number": "` + orderNumber + `"}`).
+				Post(srv.URL + "/api/merchant/orders")
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want.status, resp.StatusCode())
+		})
+	}
+}
+
+func TestMerchantOrderCreateBadKey(t *testing.T) {
+	t.Run("merchant order create bad key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{
+			TokenSecret: gofakeit.DigitN(10),
+			LogLevel:    "debug",
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		key := model.APIKey{ID: 1, Prefix: "abcdefgh", KeyHash: "hash", Scopes: model.StringList{model.ScopeOrdersCreate}}
+
+		apiKeyRepo := mock_application.NewMockAPIKeyRepo(ctrl)
+		apiKeyRepo.EXPECT().FindByPrefix(gomock.Any(), key.Prefix).Return(&key, true).Times(1)
+
+		app := application.App{
+			Rep: application.Repository{
+				APIKey: apiKeyRepo,
+			},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+		}
+
+		r := router.New(&app)
+		srv := httptest.NewServer(r)
+		defer srv.Close()
+
+		resp, err := resty.New().
+			R().
+			SetHeader("Content-type", "application/json").
+			SetHeader("X-API-Key", "gm_"+key.Prefix+"_wrongsecret").
+			SetBody(`{"login": "user", "number": "45031620082273"}`).
+			Post(srv.URL + "/api/merchant/orders")
+
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+
+		resp, err = resty.New().
+			R().
+			SetHeader("Content-type", "application/json").
+			SetBody(`{"login": "user", "number": "45031620082273"}`).
+			Post(srv.URL + "/api/merchant/orders")
+
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	})
+}
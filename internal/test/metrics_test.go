@@ -0,0 +1,91 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/metrics"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsHTTPRoutePattern(t *testing.T) {
+	t.Run("http metrics by route pattern", func(t *testing.T) {
+		conf := config.Config{
+			TokenSecret: gofakeit.DigitN(10),
+			LogLevel:    "debug",
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		app := application.App{
+			Log:  zLog,
+			Conf: &conf,
+		}
+
+		srv := httptest.NewServer(router.New(&app))
+		defer srv.Close()
+
+		adminSrv := httptest.NewServer(router.NewAdminListener(&app))
+		defer adminSrv.Close()
+
+		counter := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/api/user/orders/{number}", "401")
+		before := testutil.ToFloat64(counter)
+
+		for _, number := range []string{"12345678903", "49927398716"} {
+			resp, err := resty.New().R().Get(srv.URL + "/api/user/orders/" + number)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+		}
+
+		require.InDelta(t, before+2, testutil.ToFloat64(counter), 0)
+
+		resp, err := resty.New().R().Get(adminSrv.URL + "/metrics")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+		require.Contains(t, resp.String(), `gophermart_http_requests_total{method="GET",route="/api/user/orders/{number}"`)
+		require.NotContains(t, resp.String(), "12345678903")
+	})
+}
+
+func TestMetricsTrmRollback(t *testing.T) {
+	t.Run("trm rollback counted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		zLog, err := logger.Build("debug")
+		require.NoError(t, err)
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		tr.EXPECT().Begin(gomock.Any()).Return(context.Background(), nil).Times(2)
+		tr.EXPECT().Commit(gomock.Any()).Return(nil).Times(1)
+		tr.EXPECT().Rollback(gomock.Any()).Return(nil).Times(2)
+		trManager := trm.NewTrm(tr, zLog)
+
+		rollbacks := testutil.ToFloat64(metrics.TrmRollbacks)
+
+		require.NoError(t, trManager.Do(context.Background(), func(context.Context) error {
+			return nil
+		}))
+		require.InDelta(t, rollbacks, testutil.ToFloat64(metrics.TrmRollbacks), 0)
+
+		require.Error(t, trManager.Do(context.Background(), func(context.Context) error {
+			return errors.New("action fail")
+		}))
+		require.InDelta(t, rollbacks+1, testutil.ToFloat64(metrics.TrmRollbacks), 0)
+	})
+}
@@ -0,0 +1,394 @@
+package test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/service/oidc"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+const fakeClientID = "gophermart"
+
+// fakeProvider минимальный OIDC провайдер: discovery, authorize, token и jwks.
+type fakeProvider struct {
+	key     *rsa.PrivateKey
+	srv     *httptest.Server
+	codes   map[string]url.Values
+	subject string
+	email   string
+	nonce   string
+	mu      sync.Mutex
+}
+
+func newFakeProvider(t *testing.T, subject, email string) *fakeProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	fp := &fakeProvider{
+		key:     key,
+		subject: subject,
+		email:   email,
+		codes:   map[string]url.Values{},
+	}
+
+	r := chi.NewRouter()
+	r.Get("/.well-known/openid-configuration", fp.discovery)
+	r.Get("/authorize", fp.authorize)
+	r.Post("/token", fp.token)
+	r.Get("/jwks", fp.jwks)
+
+	fp.srv = httptest.NewServer(r)
+	t.Cleanup(fp.srv.Close)
+
+	return fp
+}
+
+func (fp *fakeProvider) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, map[string]string{
+		"issuer":                 fp.srv.URL,
+		"authorization_endpoint": fp.srv.URL + "/authorize",
+		"token_endpoint":         fp.srv.URL + "/token",
+		"jwks_uri":               fp.srv.URL + "/jwks",
+	})
+}
+
+// authorize сразу "входит" пользователем и возвращает код на redirect_uri.
+func (fp *fakeProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != fakeClientID || q.Get("code_challenge_method") != "S256" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	code := gofakeit.LetterN(16)
+	fp.mu.Lock()
+	fp.codes[code] = q
+	fp.mu.Unlock()
+
+	http.Redirect(w, r, q.Get("redirect_uri")+"?code="+code+"&state="+q.Get("state"), http.StatusFound)
+}
+
+func (fp *fakeProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	fp.mu.Lock()
+	q, ok := fp.codes[r.PostForm.Get("code")]
+	delete(fp.codes, r.PostForm.Get("code"))
+	fp.mu.Unlock()
+
+	if !ok || oidc.Challenge(r.PostForm.Get("code_verifier")) != q.Get("code_challenge") {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	nonce := q.Get("nonce")
+	if fp.nonce != "" {
+		nonce = fp.nonce
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            fp.srv.URL,
+		"aud":            fakeClientID,
+		"sub":            fp.subject,
+		"email":          fp.email,
+		"email_verified": true,
+		"nonce":          nonce,
+		"exp":            time.Now().Add(time.Minute).Unix(),
+	})
+	token.Header["kid"] = "test"
+
+	idToken, err := token.SignedString(fp.key)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]string{"id_token": idToken, "token_type": "Bearer"})
+}
+
+func (fp *fakeProvider) jwks(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(fp.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(fp.key.E)).Bytes()),
+		}},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func TestUserOIDCLogin(t *testing.T) {
+	type want struct {
+		nonce     string
+		creates   int
+		links     int
+		status    int
+		linked    bool
+		twoFactor bool
+	}
+
+	tests := []struct {
+		name         string
+		exists       bool
+		linkExisting bool
+		want         want
+	}{
+		{
+			name: "oidc login creates user",
+			want: want{
+				creates: 1,
+				links:   1,
+				status:  http.StatusOK,
+			},
+		},
+		{
+			name:   "oidc login does not link existing user by default",
+			exists: true,
+			want: want{
+				status: http.StatusConflict,
+			},
+		},
+		{
+			name:         "oidc login links existing user when enabled",
+			exists:       true,
+			linkExisting: true,
+			want: want{
+				links:  1,
+				status: http.StatusOK,
+			},
+		},
+		{
+			name: "oidc login linked user",
+			want: want{
+				linked: true,
+				status: http.StatusOK,
+			},
+		},
+		{
+			name: "oidc login user with two factor",
+			want: want{
+				linked:    true,
+				twoFactor: true,
+				status:    http.StatusAccepted,
+			},
+		},
+		{
+			name: "oidc login nonce mismatch",
+			want: want{
+				nonce:  "wrong",
+				status: http.StatusUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			user := model.User{
+				ID:          1,
+				Login:       "JohnDoeshopexampleco",
+				TOTPEnabled: tt.want.twoFactor,
+			}
+			subject := gofakeit.UUID()
+
+			fp := newFakeProvider(t, subject, "John.Doe+shop@example.com")
+			fp.nonce = tt.want.nonce
+
+			conf := config.Config{
+				TokenSecret:      gofakeit.DigitN(10),
+				LogLevel:         "debug",
+				TokenDuration:    5,
+				OIDCIssuer:       fp.srv.URL,
+				OIDCClientID:     fakeClientID,
+				OIDCSecret:       "secret",
+				OIDCLinkExisting: tt.linkExisting,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			created := !tt.want.linked && !tt.exists
+			identityRepo := mock_application.NewMockIdentityRepo(ctrl)
+			identityRepo.EXPECT().FindLogin(gomock.Any(), fp.srv.URL, subject).Return(user.Login, tt.want.linked).MaxTimes(1)
+			identityRepo.EXPECT().Link(gomock.Any(), user.ID, fp.srv.URL, subject).Return(nil).Times(tt.want.links)
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).
+				DoAndReturn(func(context.Context, string) (*model.User, bool) {
+					if created {
+						return nil, false
+					}
+					return &user, true
+				}).
+				AnyTimes()
+			userRepo.EXPECT().Create(gomock.Any(), user.Login, gomock.Any()).
+				DoAndReturn(func(context.Context, string, string) error {
+					created = false
+					return nil
+				}).
+				Times(tt.want.creates)
+
+			outboxRepo := mock_application.NewMockOutboxRepo(ctrl)
+			outboxRepo.EXPECT().Add(gomock.Any(), model.OutboxUserRegistered, gomock.Any()).Return(nil).Times(tt.want.creates)
+
+			app := application.App{
+				Rep: application.Repository{
+					User:     userRepo,
+					Identity: identityRepo,
+					Outbox:   outboxRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			srv := httptest.NewServer(router.New(&app))
+			defer srv.Close()
+			conf.OIDCRedirect = srv.URL + "/api/user/oidc/callback"
+
+			jar, err := cookiejar.New(nil)
+			require.NoError(t, err)
+			client := &http.Client{Jar: jar}
+
+			req, err := http.NewRequestWithContext(
+				context.Background(),
+				http.MethodGet,
+				srv.URL+"/api/user/oidc/login",
+				http.NoBody,
+			)
+			require.NoError(t, err)
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, resp.Body.Close())
+			}()
+
+			require.Equal(t, tt.want.status, resp.StatusCode)
+			switch tt.want.status {
+			case http.StatusOK:
+				require.Contains(t, resp.Header.Get("Authorization"), "Bearer ")
+			case http.StatusAccepted:
+				require.Empty(t, resp.Header.Get("Authorization"))
+
+				token := map[string]any{}
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&token))
+				require.Equal(t, "2fa", token["scope"])
+			}
+		})
+	}
+}
+
+func TestUserOIDCCallbackStateMismatch(t *testing.T) {
+	t.Run("oidc callback state mismatch", func(t *testing.T) {
+		fp := newFakeProvider(t, gofakeit.UUID(), gofakeit.Email())
+
+		conf := config.Config{
+			TokenSecret:  gofakeit.DigitN(10),
+			LogLevel:     "debug",
+			OIDCIssuer:   fp.srv.URL,
+			OIDCClientID: fakeClientID,
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		app := application.App{
+			Log:  zLog,
+			Conf: &conf,
+		}
+
+		srv := httptest.NewServer(router.New(&app))
+		defer srv.Close()
+
+		st, err := oidc.NewState(time.Minute)
+		require.NoError(t, err)
+		cookie, err := st.Encode(conf.TokenSecret)
+		require.NoError(t, err)
+
+		req, err := http.NewRequestWithContext(
+			context.Background(),
+			http.MethodGet,
+			srv.URL+"/api/user/oidc/callback?code=code&state=other",
+			http.NoBody,
+		)
+		require.NoError(t, err)
+		req.AddCookie(&http.Cookie{Name: "oidc_state", Value: cookie})
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestOIDCStateKey(t *testing.T) {
+	t.Run("state is not signed with token secret", func(t *testing.T) {
+		secret := gofakeit.DigitN(10)
+
+		st, err := oidc.NewState(time.Minute)
+		require.NoError(t, err)
+
+		cookie, err := st.Encode(secret)
+		require.NoError(t, err)
+
+		decoded, err := oidc.DecodeState(secret, cookie)
+		require.NoError(t, err)
+		require.Equal(t, st.State, decoded.State)
+
+		_, err = jwt.Parse(cookie, func(*jwt.Token) (interface{}, error) {
+			return []byte(secret), nil
+		})
+		require.Error(t, err)
+
+		forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, st).SignedString([]byte(secret))
+		require.NoError(t, err)
+
+		_, err = oidc.DecodeState(secret, forged)
+		require.ErrorIs(t, err, oidc.ErrStateInvalid)
+	})
+}
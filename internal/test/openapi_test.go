@@ -0,0 +1,170 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/openapi"
+	"github.com/arefev/gophermart/internal/problem"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIRoutesDocumented(t *testing.T) {
+	t.Run("every route is in openapi spec", func(t *testing.T) {
+		conf := config.Config{
+			TokenSecret: gofakeit.DigitN(10),
+			LogLevel:    "debug",
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		app := application.App{
+			Log:  zLog,
+			Conf: &conf,
+		}
+
+		doc, err := openapi.Load(context.Background())
+		require.NoError(t, err)
+
+		routes := map[string]bool{}
+		walk := func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+			path := strings.TrimPrefix(route, "/api")
+			routes[method+" "+path] = true
+
+			item := doc.Paths.Value(path)
+			require.NotNil(t, item, "route %s %s is missing in openapi spec", method, route)
+			require.NotNil(t, item.GetOperation(method), "route %s %s is missing in openapi spec", method, route)
+			return nil
+		}
+		require.NoError(t, chi.Walk(router.New(&app), walk))
+
+		for path, item := range doc.Paths.Map() {
+			for method := range item.Operations() {
+				require.True(t, routes[method+" "+path], "spec operation %s %s has no route", method, path)
+			}
+		}
+	})
+}
+
+func TestOpenAPISpecServed(t *testing.T) {
+	t.Run("openapi spec served", func(t *testing.T) {
+		conf := config.Config{
+			TokenSecret: gofakeit.DigitN(10),
+			LogLevel:    "debug",
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		app := application.App{
+			Log:  zLog,
+			Conf: &conf,
+		}
+
+		srv := httptest.NewServer(router.New(&app))
+		defer srv.Close()
+
+		resp, err := resty.New().R().Get(srv.URL + "/api/openapi.json")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+		require.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+		require.JSONEq(t, string(openapi.Spec()), resp.String())
+	})
+}
+
+func TestOpenAPIValidation(t *testing.T) {
+	type want struct {
+		finds  int
+		status int
+		slug   string
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want want
+	}{
+		{
+			name: "request does not match spec",
+			body: `{"login": 5, "password": "secret"}`,
+			want: want{
+				status: http.StatusBadRequest,
+				slug:   "spec-mismatch",
+			},
+		},
+		{
+			name: "request matches spec",
+			body: `{"login": "gopher", "password": "secret"}`,
+			want: want{
+				finds:  1,
+				status: http.StatusUnauthorized,
+				slug:   "invalid-credentials",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret:     gofakeit.DigitN(10),
+				LogLevel:        "debug",
+				OpenAPIValidate: true,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), "gopher").Return(nil, false).Times(tt.want.finds)
+
+			app := application.App{
+				Rep: application.Repository{
+					User: userRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			srv := httptest.NewServer(router.New(&app))
+			defer srv.Close()
+
+			result := problem.Problem{}
+			resp, err := resty.New().
+				R().
+				SetHeader("Content-type", "application/json").
+				SetBody(tt.body).
+				SetError(&result).
+				Post(srv.URL + "/api/user/login")
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want.status, resp.StatusCode())
+			require.Equal(t, "urn:gophermart:problem:"+tt.want.slug, result.Type)
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arefev/gophermart/internal/action/order"
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/response"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/service/jwt"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderBatch(t *testing.T) {
+	const (
+		newNumber   = "45031620082273"
+		ownNumber   = "79927398713"
+		otherNumber = "4111111111111111"
+	)
+
+	type want struct {
+		results []string
+		status  int
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		maxSize     int
+		want        want
+	}{
+		{
+			name:        "order batch json",
+			contentType: "application/json",
+			body: `["` + newNumber + `", "` + ownNumber + `", "` + otherNumber + `", ` +
+				`"12345", "` + strings.Repeat("0", 60) + `"]`,
+			want: want{
+				status: http.StatusMultiStatus,
+				results: []string{
+					order.BatchResultAccepted,
+					order.BatchResultUploadedByUser,
+					order.BatchResultUploadedByOther,
+					order.BatchResultInvalid,
+					order.BatchResultInvalid,
+				},
+			},
+		},
+		{
+			name:        "order batch csv",
+			contentType: "text/csv",
+			body:        "number\n" + newNumber + "\n" + ownNumber + "\n",
+			want: want{
+				status:  http.StatusMultiStatus,
+				results: []string{order.BatchResultAccepted, order.BatchResultUploadedByUser},
+			},
+		},
+		{
+			name:        "order batch too large",
+			contentType: "application/json",
+			body:        `["` + newNumber + `", "` + ownNumber + `", "` + otherNumber + `"]`,
+			maxSize:     2,
+			want: want{
+				status: http.StatusRequestEntityTooLarge,
+			},
+		},
+		{
+			name:        "order batch body too large",
+			contentType: "application/json",
+			body:        `["` + strings.Repeat("1", 4096) + `"]`,
+			maxSize:     2,
+			want: want{
+				status: http.StatusRequestEntityTooLarge,
+			},
+		},
+		{
+			name:        "order batch csv too large",
+			contentType: "text/csv",
+			body:        newNumber + "\n" + ownNumber + "\n" + otherNumber + "\n" + strings.Repeat("1,\n", 100),
+			maxSize:     2,
+			want: want{
+				status: http.StatusRequestEntityTooLarge,
+			},
+		},
+		{
+			name:        "order batch unclosed array",
+			contentType: "application/json",
+			body:        `["` + newNumber + `"`,
+			want: want{
+				status: http.StatusBadRequest,
+			},
+		},
+		{
+			name:        "order batch malformed",
+			contentType: "application/json",
+			body:        `{"number": "` + newNumber + `"}`,
+			want: want{
+				status: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret:   gofakeit.DigitN(10),
+				LogLevel:      "debug",
+				TokenDuration: 5,
+				OrderBatchMax: tt.maxSize,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			user := model.User{
+				ID:    1,
+				Login: gofakeit.Username(),
+			}
+
+			creates := 0
+			if tt.want.status == http.StatusMultiStatus {
+				creates = 1
+			}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).AnyTimes()
+
+			orderRepo := mock_application.NewMockOrderRepo(ctrl)
+			orderRepo.EXPECT().FindByNumber(gomock.Any(), newNumber).Return(nil, false).MaxTimes(1)
+			orderRepo.EXPECT().FindByNumber(gomock.Any(), ownNumber).Return(&model.Order{UserID: user.ID}, true).MaxTimes(1)
+			orderRepo.EXPECT().FindByNumber(gomock.Any(), otherNumber).Return(&model.Order{UserID: 2}, true).MaxTimes(1)
+			orderRepo.EXPECT().Create(gomock.Any(), user.ID, model.OrderStatusNew, newNumber).Return(nil).Times(creates)
+
+			outboxRepo := mock_application.NewMockOutboxRepo(ctrl)
+			outboxRepo.EXPECT().Add(gomock.Any(), model.OutboxOrderUploaded, gomock.Any()).Return(nil).Times(creates)
+
+			app := application.App{
+				Rep: application.Repository{
+					User:   userRepo,
+					Order:  orderRepo,
+					Outbox: outboxRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			r := router.New(&app)
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			token, err := jwt.NewToken(conf.TokenSecret).GenerateToken(&user, conf.TokenDuration)
+			require.NoError(t, err)
+
+			result := response.OrderBatch{}
+			resp, err := resty.New().
+				R().
+				SetHeader("Authorization", "Bearer "+token.AccessToken).
+				SetHeader("Content-Type", tt.contentType).
+				SetBody(tt.body).
+				SetResult(&result).
+				Post(srv.URL + "/api/user/orders/batch")
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want.status, resp.StatusCode())
+
+			if tt.want.status != http.StatusMultiStatus {
+				return
+			}
+
+			require.Len(t, result.Results, len(tt.want.results))
+			for i, res := range tt.want.results {
+				require.Equal(t, res, result.Results[i].Result, result.Results[i].Number)
+			}
+			require.Equal(t, 1, result.Accepted)
+
+			if len(result.Results) == 5 {
+				require.Equal(t, "luhn", result.Results[3].Reason)
+				require.Equal(t, "lte", result.Results[4].Reason)
+			}
+		})
+	}
+}
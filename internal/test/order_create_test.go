@@ -11,7 +11,6 @@ import (
 	"github.com/arefev/gophermart/internal/logger"
 	"github.com/arefev/gophermart/internal/model"
 	"github.com/arefev/gophermart/internal/router"
-	"github.com/arefev/gophermart/internal/service/password"
 	"github.com/arefev/gophermart/internal/trm"
 	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
 	"github.com/go-resty/resty/v2"
@@ -64,8 +63,7 @@ func TestOrderCreateSuccess(t *testing.T) {
 			require.NoError(t, err)
 
 			pwd := gofakeit.Password(true, true, true, true, false, 10)
-			pwdHash, err := password.Encrypt(pwd)
-			require.NoError(t, err)
+			pwdHash := hashPassword(t, pwd)
 
 			user := model.User{
 				ID:       1,
@@ -88,10 +86,14 @@ func TestOrderCreateSuccess(t *testing.T) {
 			orderRepo.EXPECT().FindByNumber(gomock.Any(), orderNumber).Return(nil, false).MaxTimes(1)
 			orderRepo.EXPECT().Create(gomock.Any(), user.ID, model.OrderStatusNew, orderNumber).Return(nil).MaxTimes(1)
 
+			outboxRepo := mock_application.NewMockOutboxRepo(ctrl)
+			outboxRepo.EXPECT().Add(gomock.Any(), model.OutboxOrderUploaded, gomock.Any()).Return(nil).MaxTimes(1)
+
 			app := application.App{
 				Rep: application.Repository{
-					User:  userRepo,
-					Order: orderRepo,
+					User:   userRepo,
+					Order:  orderRepo,
+					Outbox: outboxRepo,
 				},
 				TrManager: trManager,
 				Log:       zLog,
@@ -147,8 +149,7 @@ func TestOrderCreateStatusUnprocessable(t *testing.T) {
 		require.NoError(t, err)
 
 		pwd := gofakeit.Password(true, true, true, true, false, 10)
-		pwdHash, err := password.Encrypt(pwd)
-		require.NoError(t, err)
+		pwdHash := hashPassword(t, pwd)
 
 		user := model.User{
 			ID:       1,
@@ -260,8 +261,7 @@ func TestOrderCreateBadAuthHeader(t *testing.T) {
 		require.NoError(t, err)
 
 		pwd := gofakeit.Password(true, true, true, true, false, 10)
-		pwdHash, err := password.Encrypt(pwd)
-		require.NoError(t, err)
+		pwdHash := hashPassword(t, pwd)
 
 		user := model.User{
 			ID:       1,
@@ -284,10 +284,14 @@ func TestOrderCreateBadAuthHeader(t *testing.T) {
 		orderRepo.EXPECT().FindByNumber(gomock.Any(), orderNumber).Return(nil, false).MaxTimes(1)
 		orderRepo.EXPECT().Create(gomock.Any(), user.ID, model.OrderStatusNew, orderNumber).Return(nil).MaxTimes(1)
 
+		outboxRepo := mock_application.NewMockOutboxRepo(ctrl)
+		outboxRepo.EXPECT().Add(gomock.Any(), model.OutboxOrderUploaded, gomock.Any()).Return(nil).MaxTimes(1)
+
 		app := application.App{
 			Rep: application.Repository{
-				User:  userRepo,
-				Order: orderRepo,
+				User:   userRepo,
+				Order:  orderRepo,
+				Outbox: outboxRepo,
 			},
 			TrManager: trManager,
 			Log:       zLog,
@@ -342,8 +346,7 @@ func TestOrderCreateStatusOk(t *testing.T) {
 		require.NoError(t, err)
 
 		pwd := gofakeit.Password(true, true, true, true, false, 10)
-		pwdHash, err := password.Encrypt(pwd)
-		require.NoError(t, err)
+		pwdHash := hashPassword(t, pwd)
 
 		user := model.User{
 			ID:       1,
@@ -370,10 +373,14 @@ func TestOrderCreateStatusOk(t *testing.T) {
 		orderRepo.EXPECT().FindByNumber(gomock.Any(), orderNumber).Return(order, true).MaxTimes(1)
 		orderRepo.EXPECT().Create(gomock.Any(), user.ID, model.OrderStatusNew, orderNumber).Return(nil).MaxTimes(0)
 
+		outboxRepo := mock_application.NewMockOutboxRepo(ctrl)
+		outboxRepo.EXPECT().Add(gomock.Any(), model.OutboxOrderUploaded, gomock.Any()).Return(nil).MaxTimes(0)
+
 		app := application.App{
 			Rep: application.Repository{
-				User:  userRepo,
-				Order: orderRepo,
+				User:   userRepo,
+				Order:  orderRepo,
+				Outbox: outboxRepo,
 			},
 			TrManager: trManager,
 			Log:       zLog,
@@ -428,8 +435,7 @@ func TestOrderCreateStatusConflict(t *testing.T) {
 		require.NoError(t, err)
 
 		pwd := gofakeit.Password(true, true, true, true, false, 10)
-		pwdHash, err := password.Encrypt(pwd)
-		require.NoError(t, err)
+		pwdHash := hashPassword(t, pwd)
 
 		user := model.User{
 			ID:       1,
@@ -456,10 +462,14 @@ func TestOrderCreateStatusConflict(t *testing.T) {
 		orderRepo.EXPECT().FindByNumber(gomock.Any(), orderNumber).Return(order, true).MaxTimes(1)
 		orderRepo.EXPECT().Create(gomock.Any(), user.ID, model.OrderStatusNew, orderNumber).Return(nil).MaxTimes(0)
 
+		outboxRepo := mock_application.NewMockOutboxRepo(ctrl)
+		outboxRepo.EXPECT().Add(gomock.Any(), model.OutboxOrderUploaded, gomock.Any()).Return(nil).MaxTimes(0)
+
 		app := application.App{
 			Rep: application.Repository{
-				User:  userRepo,
-				Order: orderRepo,
+				User:   userRepo,
+				Order:  orderRepo,
+				Outbox: outboxRepo,
 			},
 			TrManager: trManager,
 			Log:       zLog,
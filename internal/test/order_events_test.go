@@ -0,0 +1,129 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/events"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/service/jwt"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/golang/mock/gomock"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderEventsStream(t *testing.T) {
+	t.Run("order events stream with resume", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{
+			TokenSecret:   gofakeit.DigitN(10),
+			LogLevel:      "debug",
+			TokenDuration: 5,
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		user := model.User{
+			ID:    1,
+			Login: gofakeit.Username(),
+		}
+
+		missed := model.Event{ID: 6, UserID: user.ID, Type: model.EventOrder, Payload: `{"number":"1","status":"PROCESSED"}`}
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		userRepo := mock_application.NewMockUserRepo(ctrl)
+		userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).AnyTimes()
+
+		eventRepo := mock_application.NewMockEventRepo(ctrl)
+		eventRepo.EXPECT().After(gomock.Any(), user.ID, int64(5), gomock.Any()).Return([]model.Event{missed}).Times(1)
+
+		hub := events.NewHub()
+		app := application.App{
+			Rep: application.Repository{
+				User:  userRepo,
+				Event: eventRepo,
+			},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+			Events:    hub,
+		}
+
+		srv := httptest.NewServer(router.New(&app))
+		defer srv.Close()
+
+		token, err := jwt.NewToken(conf.TokenSecret).GenerateToken(&user, conf.TokenDuration)
+		require.NoError(t, err)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/user/orders/events", http.NoBody)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+		req.Header.Set("Last-Event-ID", "5")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, resp.Body.Close())
+		}()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		reader := bufio.NewReader(resp.Body)
+		readEvent := func() string {
+			var lines []string
+			for {
+				line, err := reader.ReadString('\n')
+				require.NoError(t, err)
+
+				line = strings.TrimRight(line, "\n")
+				if line == "" {
+					return strings.Join(lines, "\n")
+				}
+				lines = append(lines, line)
+			}
+		}
+
+		require.Equal(t, "id: 6\nevent: order\ndata: "+missed.Payload, readEvent())
+
+		// Событие другого пользователя и повтор уже отправленного не доходят до клиента
+		hub.Publish(model.Event{ID: 7, UserID: 2, Type: model.EventOrder, Payload: `{}`})
+		hub.Publish(missed)
+		hub.Publish(model.Event{ID: 8, UserID: user.ID, Type: model.EventBalance, Payload: `{"current":500}`})
+
+		require.Equal(t, "id: 8\nevent: balance\ndata: {\"current\":500}", readEvent())
+
+		// Событие с меньшим ID, пришедшее позже, доставляется, повтор - нет
+		late := model.Event{ID: 7, UserID: user.ID, Type: model.EventOrder, Payload: `{"number":"2","status":"NEW"}`}
+		hub.Publish(late)
+		hub.Publish(late)
+		hub.Publish(model.Event{ID: 9, UserID: user.ID, Type: model.EventBalance, Payload: `{"current":600}`})
+
+		require.Equal(t, "id: 7\nevent: order\ndata: "+late.Payload, readEvent())
+		require.Equal(t, "id: 9\nevent: balance\ndata: {\"current\":600}", readEvent())
+	})
+}
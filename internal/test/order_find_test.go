@@ -0,0 +1,156 @@
+package test
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/response"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/service/jwt"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderFind(t *testing.T) {
+	type want struct {
+		status  int
+		history int
+	}
+
+	tests := []struct {
+		name    string
+		ownerID int
+		exists  bool
+		want    want
+	}{
+		{
+			name:    "order find success",
+			ownerID: 1,
+			exists:  true,
+			want: want{
+				status:  http.StatusOK,
+				history: 2,
+			},
+		},
+		{
+			name:    "order find other user",
+			ownerID: 2,
+			exists:  true,
+			want: want{
+				status: http.StatusNotFound,
+			},
+		},
+		{
+			name: "order find not exists",
+			want: want{
+				status: http.StatusNotFound,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret:   gofakeit.DigitN(10),
+				LogLevel:      "debug",
+				TokenDuration: 5,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			user := model.User{
+				ID:    1,
+				Login: gofakeit.Username(),
+			}
+
+			now := time.Now().UTC().Truncate(time.Second)
+			order := model.Order{
+				ID:         10,
+				UserID:     tt.ownerID,
+				Number:     "45031620082273",
+				Status:     model.OrderStatusProcessed,
+				Accrual:    sql.NullFloat64{Float64: 500, Valid: true},
+				UploadedAt: now.Add(-time.Minute),
+				CheckedAt:  sql.NullTime{Time: now, Valid: true},
+			}
+
+			history := []model.OrderStatusChange{
+				{Status: model.OrderStatusNew, CreatedAt: order.UploadedAt},
+				{Status: model.OrderStatusProcessed, CreatedAt: now},
+			}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).AnyTimes()
+
+			orderRepo := mock_application.NewMockOrderRepo(ctrl)
+			if tt.exists {
+				orderRepo.EXPECT().FindByNumber(gomock.Any(), order.Number).Return(&order, true).Times(1)
+			} else {
+				orderRepo.EXPECT().FindByNumber(gomock.Any(), order.Number).Return(nil, false).Times(1)
+			}
+			orderRepo.EXPECT().StatusHistory(gomock.Any(), order.ID).Return(history).MaxTimes(1)
+
+			app := application.App{
+				Rep: application.Repository{
+					User:  userRepo,
+					Order: orderRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			r := router.New(&app)
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			token, err := jwt.NewToken(conf.TokenSecret).GenerateToken(&user, conf.TokenDuration)
+			require.NoError(t, err)
+
+			result := response.OrderDetail{}
+			resp, err := resty.New().
+				R().
+				SetHeader("Authorization", "Bearer "+token.AccessToken).
+				SetResult(&result).
+				Get(srv.URL + "/api/user/orders/" + order.Number)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want.status, resp.StatusCode())
+
+			if tt.want.status != http.StatusOK {
+				return
+			}
+
+			require.Equal(t, order.Number, result.Number)
+			require.Equal(t, "PROCESSED", result.Status)
+			require.InDelta(t, order.Accrual.Float64, result.Accrual, 0)
+			require.NotNil(t, result.CheckedAt)
+			require.True(t, now.Equal(*result.CheckedAt))
+			require.Len(t, result.History, tt.want.history)
+			require.Equal(t, "NEW", result.History[0].Status)
+		})
+	}
+}
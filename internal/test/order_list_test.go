@@ -1,17 +1,20 @@
 package test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/arefev/gophermart/internal/application"
 	mock_application "github.com/arefev/gophermart/internal/application/mocks"
 	"github.com/arefev/gophermart/internal/config"
 	"github.com/arefev/gophermart/internal/logger"
 	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/response"
 	"github.com/arefev/gophermart/internal/router"
-	"github.com/arefev/gophermart/internal/service/password"
+	"github.com/arefev/gophermart/internal/service/jwt"
 	"github.com/arefev/gophermart/internal/trm"
 	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
 	"github.com/go-resty/resty/v2"
@@ -36,8 +39,7 @@ func TestOrderListSuccess(t *testing.T) {
 		require.NoError(t, err)
 
 		pwd := gofakeit.Password(true, true, true, true, false, 10)
-		pwdHash, err := password.Encrypt(pwd)
-		require.NoError(t, err)
+		pwdHash := hashPassword(t, pwd)
 
 		user := model.User{
 			ID:       1,
@@ -125,8 +127,7 @@ func TestOrderListStatusNoContent(t *testing.T) {
 		require.NoError(t, err)
 
 		pwd := gofakeit.Password(true, true, true, true, false, 10)
-		pwdHash, err := password.Encrypt(pwd)
-		require.NoError(t, err)
+		pwdHash := hashPassword(t, pwd)
 
 		user := model.User{
 			ID:       1,
@@ -189,3 +190,109 @@ func TestOrderListStatusNoContent(t *testing.T) {
 		require.Equal(t, http.StatusNoContent, resp.StatusCode())
 	})
 }
+
+func TestOrderListPaginated(t *testing.T) {
+	t.Run("order list paginated", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{
+			TokenSecret:   gofakeit.DigitN(10),
+			LogLevel:      "debug",
+			TokenDuration: 5,
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		user := model.User{
+			ID:    1,
+			Login: gofakeit.Username(),
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+		orders := []model.Order{
+			{ID: 1, UserID: user.ID, Number: "1", Status: model.OrderStatusNew, UploadedAt: now},
+			{ID: 2, UserID: user.ID, Number: "2", Status: model.OrderStatusProcessed, UploadedAt: now.Add(time.Second)},
+			{ID: 3, UserID: user.ID, Number: "3", Status: model.OrderStatusNew, UploadedAt: now.Add(2 * time.Second)},
+		}
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		userRepo := mock_application.NewMockUserRepo(ctrl)
+		userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).AnyTimes()
+
+		orderRepo := mock_application.NewMockOrderRepo(ctrl)
+		orderRepo.EXPECT().GetByUserID(gomock.Any(), gomock.Any()).Times(0)
+		orderRepo.EXPECT().PageByUserID(gomock.Any(), user.ID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ int, filter model.ListFilter) []model.Order {
+				require.Equal(t, 3, filter.Limit)
+				require.True(t, filter.Asc)
+				require.Equal(t, []model.OrderStatus{model.OrderStatusNew, model.OrderStatusProcessed}, filter.Statuses)
+
+				if filter.Cursor == nil {
+					return orders
+				}
+
+				require.Equal(t, orders[1].ID, filter.Cursor.ID)
+				require.True(t, orders[1].UploadedAt.Equal(filter.Cursor.At))
+				return orders[2:]
+			}).
+			Times(2)
+
+		app := application.App{
+			Rep: application.Repository{
+				User:  userRepo,
+				Order: orderRepo,
+			},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+		}
+
+		r := router.New(&app)
+		srv := httptest.NewServer(r)
+		defer srv.Close()
+
+		token, err := jwt.NewToken(conf.TokenSecret).GenerateToken(&user, conf.TokenDuration)
+		require.NoError(t, err)
+
+		result := []response.Order{}
+		resp, err := resty.New().
+			R().
+			SetHeader("Authorization", "Bearer "+token.AccessToken).
+			SetResult(&result).
+			Get(srv.URL + "/api/user/orders?limit=2&sort=asc&status=NEW,PROCESSED")
+
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+		require.Len(t, result, 2)
+
+		next := resp.Header().Get("X-Next-Cursor")
+		require.NotEmpty(t, next)
+		require.Contains(t, resp.Header().Get("Link"), `rel="next"`)
+
+		resp, err = resty.New().
+			R().
+			SetHeader("Authorization", "Bearer "+token.AccessToken).
+			SetResult(&result).
+			Get(srv.URL + "/api/user/orders?limit=2&sort=asc&status=NEW,PROCESSED&cursor=" + next)
+
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+		require.Len(t, result, 1)
+		require.Empty(t, resp.Header().Get("X-Next-Cursor"))
+
+		resp, err = resty.New().
+			R().
+			SetHeader("Authorization", "Bearer "+token.AccessToken).
+			Get(srv.URL + "/api/user/orders?limit=1000")
+
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	})
+}
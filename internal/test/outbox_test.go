@@ -0,0 +1,197 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/outbox"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+// failingPublisher публикует count событий, затем возвращает ошибку.
+type failingPublisher struct {
+	count int
+}
+
+func (fp *failingPublisher) Publish(context.Context, *outbox.Event) error {
+	if fp.count == 0 {
+		return errors.New("publisher unavailable")
+	}
+	fp.count--
+	return nil
+}
+
+func (fp *failingPublisher) Close() error {
+	return nil
+}
+
+func TestOutboxRegisterPublished(t *testing.T) {
+	t.Run("outbox user registered published", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{
+			TokenSecret:   gofakeit.DigitN(10),
+			LogLevel:      "debug",
+			TokenDuration: 5,
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		pwd := gofakeit.Password(true, true, true, true, false, 10)
+		pwdHash := hashPassword(t, pwd)
+
+		user := model.User{
+			ID:       1,
+			Login:    gofakeit.Username(),
+			Password: pwdHash,
+		}
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		userRepo := mock_application.NewMockUserRepo(ctrl)
+		userRepo.EXPECT().Exists(gomock.Any(), user.Login).Return(false).Times(1)
+		userRepo.EXPECT().Create(gomock.Any(), user.Login, gomock.Any()).Return(nil).Times(1)
+		userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).AnyTimes()
+
+		var stored []model.OutboxMessage
+		outboxRepo := mock_application.NewMockOutboxRepo(ctrl)
+		outboxRepo.EXPECT().Add(gomock.Any(), model.OutboxUserRegistered, gomock.Any()).
+			DoAndReturn(func(_ context.Context, eventType, payload string) error {
+				stored = append(stored, model.OutboxMessage{
+					ID:        int64(len(stored) + 1),
+					Type:      eventType,
+					Payload:   payload,
+					CreatedAt: time.Now(),
+				})
+				return nil
+			}).
+			Times(1)
+		outboxRepo.EXPECT().Pending(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(context.Context, int) []model.OutboxMessage { return stored }).
+			Times(1)
+		outboxRepo.EXPECT().MarkPublished(gomock.Any(), []int64{1}).Return(nil).Times(1)
+
+		app := application.App{
+			Rep: application.Repository{
+				User:   userRepo,
+				Outbox: outboxRepo,
+			},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+		}
+
+		srv := httptest.NewServer(router.New(&app))
+		defer srv.Close()
+
+		resp, err := resty.New().
+			R().
+			SetHeader("Content-type", "application/json").
+			SetBody(`{"login": "` + user.Login + `", "password": "` + pwd + `"}`).
+			Post(srv.URL + "/api/user/register")
+
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+
+		publisher := outbox.NewMemoryPublisher(10)
+		count, err := outbox.NewRelay(&app, publisher).Flush(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+
+		event := <-publisher.Events()
+		require.Equal(t, model.OutboxUserRegistered, event.Type)
+		require.JSONEq(t, `{"login": "`+user.Login+`", "source": "password"}`, string(event.Payload))
+	})
+}
+
+func TestOutboxRelayPublishFail(t *testing.T) {
+	t.Run("outbox relay stops on publish fail", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{LogLevel: "debug"}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		messages := []model.OutboxMessage{
+			{ID: 1, Type: model.OutboxOrderUploaded, Payload: `{}`},
+			{ID: 2, Type: model.OutboxOrderProcessed, Payload: `{}`},
+			{ID: 3, Type: model.OutboxPointsWithdrawn, Payload: `{}`},
+		}
+
+		outboxRepo := mock_application.NewMockOutboxRepo(ctrl)
+		outboxRepo.EXPECT().Pending(gomock.Any(), gomock.Any()).Return(messages).Times(1)
+		outboxRepo.EXPECT().MarkPublished(gomock.Any(), []int64{1}).Return(nil).Times(1)
+
+		app := application.App{
+			Rep: application.Repository{
+				Outbox: outboxRepo,
+			},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+		}
+
+		count, err := outbox.NewRelay(&app, &failingPublisher{count: 1}).Flush(context.Background())
+		require.Error(t, err)
+		require.Equal(t, 1, count)
+	})
+}
+
+func TestOutboxRelaySweep(t *testing.T) {
+	t.Run("outbox relay purges published and user events", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{LogLevel: "debug", OutboxRetention: 24, EventsRetention: 48}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		outboxRepo := mock_application.NewMockOutboxRepo(ctrl)
+		outboxRepo.EXPECT().Purge(gomock.Any(), 24*time.Hour).Return(nil).Times(1)
+
+		eventRepo := mock_application.NewMockEventRepo(ctrl)
+		eventRepo.EXPECT().Purge(gomock.Any(), 48*time.Hour).Return(nil).Times(1)
+
+		app := application.App{
+			Rep: application.Repository{
+				Outbox: outboxRepo,
+				Event:  eventRepo,
+			},
+			Log:  zLog,
+			Conf: &conf,
+		}
+
+		require.NoError(t, outbox.NewRelay(&app, &failingPublisher{}).Sweep(context.Background()))
+	})
+}
@@ -0,0 +1,159 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/service/password"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserChangePassword(t *testing.T) {
+	type want struct {
+		current string
+		next    string
+		updates int
+		status  int
+	}
+
+	pwd := gofakeit.Password(true, true, true, true, false, 10)
+
+	tests := []struct {
+		name string
+		want want
+	}{
+		{
+			name: "change password success",
+			want: want{
+				current: pwd,
+				next:    "New-Password-1",
+				updates: 1,
+				status:  http.StatusOK,
+			},
+		},
+		{
+			name: "change password wrong current",
+			want: want{
+				current: "Wrong-Password-1",
+				next:    "New-Password-1",
+				updates: 0,
+				status:  http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "change password weak",
+			want: want{
+				current: pwd,
+				next:    "short",
+				updates: 0,
+				status:  http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret:   gofakeit.DigitN(10),
+				LogLevel:      "debug",
+				TokenDuration: 5,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			pwdHash := hashPassword(t, pwd)
+
+			user := model.User{
+				ID:       1,
+				Login:    gofakeit.Username(),
+				Password: pwdHash,
+			}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).MaxTimes(2)
+			userRepo.EXPECT().UpdatePassword(gomock.Any(), user.ID, gomock.Any()).Return(nil).Times(tt.want.updates)
+
+			app := application.App{
+				Rep: application.Repository{
+					User: userRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			r := router.New(&app)
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			body := `{
+				"login": "` + user.Login + `",
+				"password": "` + pwd + `"
+			}`
+
+			resp, err := resty.New().
+				R().
+				SetHeader("Content-type", "application/json").
+				SetBody(body).
+				Post(srv.URL + "/api/user/login")
+
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode())
+
+			hAuth := resp.Header().Get("Authorization")
+			require.Contains(t, hAuth, "Bearer ")
+
+			body = `{
+				"current_password": "` + tt.want.current + `",
+				"new_password": "` + tt.want.next + `"
+			}`
+
+			resp, err = resty.New().
+				R().
+				SetHeader("Content-type", "application/json").
+				SetHeader("Authorization", hAuth).
+				SetBody(body).
+				Post(srv.URL + "/api/user/password")
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want.status, resp.StatusCode())
+		})
+	}
+}
+
+// hashPassword хеширует пароль тем же хешером, что и сервис с настройками по умолчанию,
+// иначе сервис считал бы хеш устаревшим и перехешировал его при входе.
+func hashPassword(t *testing.T, pwd string) string {
+	t.Helper()
+
+	hasher, err := password.NewHasher(password.Options{})
+	require.NoError(t, err)
+
+	hash, err := hasher.Hash(pwd)
+	require.NoError(t, err)
+
+	return hash
+}
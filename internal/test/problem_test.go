@@ -0,0 +1,197 @@
+package test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/problem"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/service"
+	"github.com/arefev/gophermart/internal/service/jwt"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemResponse(t *testing.T) {
+	type want struct {
+		typ    string
+		field  string
+		code   string
+		status int
+	}
+
+	tests := []struct {
+		name   string
+		number string
+		token  bool
+		want   want
+	}{
+		{
+			name:   "problem luhn check failed",
+			number: "12345",
+			token:  true,
+			want: want{
+				typ:    "urn:gophermart:problem:luhn-check-failed",
+				status: http.StatusUnprocessableEntity,
+			},
+		},
+		{
+			name:   "problem number too long",
+			number: strings.Repeat("0", 60),
+			token:  true,
+			want: want{
+				typ:    "urn:gophermart:problem:invalid-order-number",
+				field:  "number",
+				code:   "lte",
+				status: http.StatusUnprocessableEntity,
+			},
+		},
+		{
+			name:   "problem unauthorized",
+			number: "12345",
+			want: want{
+				typ:    "urn:gophermart:problem:unauthorized",
+				status: http.StatusUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret:   gofakeit.DigitN(10),
+				LogLevel:      "debug",
+				TokenDuration: 5,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			user := model.User{
+				ID:    1,
+				Login: gofakeit.Username(),
+			}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).AnyTimes()
+
+			orderRepo := mock_application.NewMockOrderRepo(ctrl)
+			orderRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+			app := application.App{
+				Rep: application.Repository{
+					User:  userRepo,
+					Order: orderRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			r := router.New(&app)
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			req := resty.New().R().SetHeader("Content-type", "text/plain")
+			if tt.token {
+				token, err := jwt.NewToken(conf.TokenSecret).GenerateToken(&user, conf.TokenDuration)
+				require.NoError(t, err)
+				req.SetHeader("Authorization", "Bearer "+token.AccessToken)
+			}
+
+			result := problem.Problem{}
+			resp, err := req.
+				SetBody(tt.number).
+				SetError(&result).
+				Post(srv.URL + "/api/user/orders")
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want.status, resp.StatusCode())
+			require.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+			require.Equal(t, tt.want.typ, result.Type)
+			require.Equal(t, tt.want.status, result.Status)
+			require.Equal(t, "/api/user/orders", result.Instance)
+			require.NotEmpty(t, result.RequestID)
+
+			if tt.want.field != "" {
+				require.Len(t, result.Errors, 1)
+				require.Equal(t, tt.want.field, result.Errors[0].Field)
+				require.Equal(t, tt.want.code, result.Errors[0].Code)
+			}
+		})
+	}
+}
+
+func TestProblemDetail(t *testing.T) {
+	type want struct {
+		typ    string
+		detail string
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		want   want
+	}{
+		{
+			name:   "problem detail without wrapped chain",
+			err:    fmt.Errorf("authorize %w: %w", service.ErrAuthUserNotFound, sql.ErrNoRows),
+			status: http.StatusUnauthorized,
+			want: want{
+				typ:    "urn:gophermart:problem:invalid-credentials",
+				detail: service.ErrAuthUserNotFound.Error(),
+			},
+		},
+		{
+			name:   "problem unknown error without detail",
+			err:    fmt.Errorf("decode fail: %w", errors.New("unexpected token at offset 3")),
+			status: http.StatusBadRequest,
+			want: want{
+				typ: "about:blank",
+			},
+		},
+		{
+			name:   "problem server error without detail",
+			err:    fmt.Errorf("authorize %w: %w", service.ErrAuthUserNotFound, sql.ErrConnDone),
+			status: http.StatusInternalServerError,
+			want: want{
+				typ: "about:blank",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/user/login", http.NoBody)
+
+			p := problem.New(r, tt.status, tt.err)
+			require.Equal(t, tt.want.typ, p.Type)
+			require.Equal(t, tt.want.detail, p.Detail)
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/arefev/gophermart/internal/config"
 	"github.com/arefev/gophermart/internal/logger"
 	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/response"
 	"github.com/arefev/gophermart/internal/router"
 	"github.com/arefev/gophermart/internal/service/password"
 	"github.com/arefev/gophermart/internal/trm"
@@ -64,8 +65,7 @@ func TestUserRegisterSuccess(t *testing.T) {
 			require.NoError(t, err)
 
 			pwd := gofakeit.Password(true, true, true, true, false, 10)
-			pwdHash, err := password.Encrypt(pwd)
-			require.NoError(t, err)
+			pwdHash := hashPassword(t, pwd)
 
 			user := model.User{
 				Login:    gofakeit.Username(),
@@ -83,9 +83,13 @@ func TestUserRegisterSuccess(t *testing.T) {
 			userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).MaxTimes(1)
 			userRepo.EXPECT().Create(gomock.Any(), user.Login, gomock.Any()).Return(nil).MaxTimes(1)
 
+			outboxRepo := mock_application.NewMockOutboxRepo(ctrl)
+			outboxRepo.EXPECT().Add(gomock.Any(), model.OutboxUserRegistered, gomock.Any()).Return(nil).MaxTimes(1)
+
 			app := application.App{
 				Rep: application.Repository{
-					User: userRepo,
+					User:   userRepo,
+					Outbox: outboxRepo,
 				},
 				TrManager: trManager,
 				Log:       zLog,
@@ -130,8 +134,7 @@ func TestUserRegisterStatusBadRequest(t *testing.T) {
 		require.NoError(t, err)
 
 		pwd := gofakeit.Password(true, true, true, true, false, 10)
-		pwdHash, err := password.Encrypt(pwd)
-		require.NoError(t, err)
+		pwdHash := hashPassword(t, pwd)
 
 		user := model.User{
 			Login:    gofakeit.Username(),
@@ -149,9 +152,13 @@ func TestUserRegisterStatusBadRequest(t *testing.T) {
 		userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).MaxTimes(1)
 		userRepo.EXPECT().Create(gomock.Any(), user.Login, gomock.Any()).Return(nil).MaxTimes(1)
 
+		outboxRepo := mock_application.NewMockOutboxRepo(ctrl)
+		outboxRepo.EXPECT().Add(gomock.Any(), model.OutboxUserRegistered, gomock.Any()).Return(nil).MaxTimes(1)
+
 		app := application.App{
 			Rep: application.Repository{
-				User: userRepo,
+				User:   userRepo,
+				Outbox: outboxRepo,
 			},
 			TrManager: trManager,
 			Log:       zLog,
@@ -179,3 +186,54 @@ func TestUserRegisterStatusBadRequest(t *testing.T) {
 		require.NotContains(t, hAuth, "Bearer ")
 	})
 }
+
+func TestUserRegisterWeakPassword(t *testing.T) {
+	t.Run("register weak password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{
+			TokenSecret:   gofakeit.DigitN(10),
+			LogLevel:      "debug",
+			PwdMinClasses: 3,
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		userRepo := mock_application.NewMockUserRepo(ctrl)
+		userRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		app := application.App{
+			Rep: application.Repository{
+				User: userRepo,
+			},
+			Log:  zLog,
+			Conf: &conf,
+		}
+
+		r := router.New(&app)
+		srv := httptest.NewServer(r)
+		defer srv.Close()
+
+		body := `{
+			"login": "` + gofakeit.Username() + `",
+			"password": "1"
+		}`
+
+		result := response.ValidationErrors{}
+		resp, err := resty.New().
+			R().
+			SetHeader("Content-type", "application/json").
+			SetBody(body).
+			SetError(&result).
+			Post(srv.URL + "/api/user/register")
+
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+		require.Len(t, result.Errors, 2)
+		require.Equal(t, "password", result.Errors[0].Field)
+		require.Equal(t, password.ViolationMin, result.Errors[0].Code)
+		require.Equal(t, password.ViolationClasses, result.Errors[1].Code)
+	})
+}
@@ -0,0 +1,339 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/problem"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/service"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupportAdjustBalance(t *testing.T) {
+	type want struct {
+		err     error
+		current float64
+		updates int
+	}
+
+	tests := []struct {
+		name   string
+		amount float64
+		reason string
+		want   want
+	}{
+		{
+			name:   "credit points",
+			amount: 50,
+			reason: "compensation for ticket 42",
+			want: want{
+				current: 150,
+				updates: 1,
+			},
+		},
+		{
+			name:   "debit points",
+			amount: -30,
+			reason: "fraud",
+			want: want{
+				current: 70,
+				updates: 1,
+			},
+		},
+		{
+			name:   "debit more than balance",
+			amount: -300,
+			reason: "fraud",
+			want: want{
+				err: service.ErrSupportNegativeBalance,
+			},
+		},
+		{
+			name:   "reason required",
+			amount: 50,
+			reason: " ",
+			want: want{
+				err: service.ErrSupportReasonRequired,
+			},
+		},
+		{
+			name:   "zero amount",
+			reason: "nothing",
+			want: want{
+				err: service.ErrSupportAmountInvalid,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret: gofakeit.DigitN(10),
+				LogLevel:    "debug",
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			user := model.User{ID: 1, Login: gofakeit.Username()}
+			balance := model.Balance{ID: 3, UserID: user.ID, Current: 100, Withdrawn: 20}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).AnyTimes()
+
+			balanceRepo := mock_application.NewMockBalanceRepo(ctrl)
+			balanceRepo.EXPECT().AddCurrent(gomock.Any(), user.ID, tt.amount).
+				DoAndReturn(addCurrent(balance)).
+				MaxTimes(1)
+
+			eventRepo := mock_application.NewMockEventRepo(ctrl)
+			eventRepo.EXPECT().Create(gomock.Any(), user.ID, model.EventBalance, gomock.Any()).
+				Return(nil).
+				Times(tt.want.updates)
+
+			auditRepo := mock_application.NewMockAuditRepo(ctrl)
+			auditRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, record model.AuditRecord) error {
+					require.Equal(t, "cli:support", record.Actor)
+					require.Equal(t, model.AuditBalanceAdjust, record.Action)
+					require.Equal(t, tt.reason, record.Reason)
+					require.Equal(t, sql.NullInt64{Int64: int64(user.ID), Valid: true}, record.UserID)
+					require.JSONEq(t, `{"amount": `+jsonNumber(tt.amount)+`, "before": 100, "after": `+
+						jsonNumber(tt.want.current)+`}`, record.Details)
+					return nil
+				}).
+				Times(tt.want.updates)
+
+			app := application.App{
+				Rep: application.Repository{
+					User:    userRepo,
+					Balance: balanceRepo,
+					Event:   eventRepo,
+					Audit:   auditRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			result, err := service.NewSupportService(&app, "cli:support").
+				AdjustBalance(context.Background(), user.Login, tt.amount, tt.reason)
+			if tt.want.err != nil {
+				require.ErrorIs(t, err, tt.want.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want.current, result.Current)
+		})
+	}
+}
+
+func TestSupportOrderStatus(t *testing.T) {
+	type want struct {
+		err    error
+		status model.OrderStatus
+		action string
+		writes int
+	}
+
+	tests := []struct {
+		name       string
+		status     model.OrderStatus
+		invalidate bool
+		want       want
+	}{
+		{
+			name:   "requeue invalid order",
+			status: model.OrderStatusInvalid,
+			want: want{
+				status: model.OrderStatusNew,
+				action: model.AuditOrderRequeue,
+				writes: 1,
+			},
+		},
+		{
+			name:       "invalidate processing order",
+			status:     model.OrderStatusProcessing,
+			invalidate: true,
+			want: want{
+				status: model.OrderStatusInvalid,
+				action: model.AuditOrderInvalid,
+				writes: 1,
+			},
+		},
+		{
+			name:   "requeue processed order",
+			status: model.OrderStatusProcessed,
+			want: want{
+				err: service.ErrSupportOrderProcessed,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret: gofakeit.DigitN(10),
+				LogLevel:    "debug",
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			order := model.Order{ID: 5, UserID: 1, Number: "45031620082273", Status: tt.status}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			orderRepo := mock_application.NewMockOrderRepo(ctrl)
+			orderRepo.EXPECT().FindByNumber(gomock.Any(), order.Number).Return(&order, true)
+			orderRepo.EXPECT().StatusByID(gomock.Any(), tt.want.status, order.ID).Return(nil).Times(tt.want.writes)
+
+			eventRepo := mock_application.NewMockEventRepo(ctrl)
+			eventRepo.EXPECT().Create(gomock.Any(), order.UserID, model.EventOrder, gomock.Any()).
+				Return(nil).
+				Times(tt.want.writes)
+
+			auditRepo := mock_application.NewMockAuditRepo(ctrl)
+			auditRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, record model.AuditRecord) error {
+					require.Equal(t, tt.want.action, record.Action)
+					require.Equal(t, sql.NullString{String: order.Number, Valid: true}, record.OrderNumber)
+					return nil
+				}).
+				Times(tt.want.writes)
+
+			app := application.App{
+				Rep: application.Repository{
+					Order: orderRepo,
+					Event: eventRepo,
+					Audit: auditRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			s := service.NewSupportService(&app, "cli:support")
+			if tt.invalidate {
+				err = s.InvalidateOrder(context.Background(), order.Number, "merchant cancelled")
+			} else {
+				err = s.RequeueOrder(context.Background(), order.Number, "accrual outage")
+			}
+
+			if tt.want.err != nil {
+				require.ErrorIs(t, err, tt.want.err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestBlockedUserLogin(t *testing.T) {
+	t.Run("blocked user can not login", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{
+			TokenSecret: gofakeit.DigitN(10),
+			LogLevel:    "debug",
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		pwd := gofakeit.Password(true, true, true, true, false, 10)
+		pwdHash := hashPassword(t, pwd)
+
+		user := model.User{
+			Login:    gofakeit.Username(),
+			Password: pwdHash,
+			Blocked:  true,
+		}
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		userRepo := mock_application.NewMockUserRepo(ctrl)
+		userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).MaxTimes(1)
+
+		app := application.App{
+			Rep: application.Repository{
+				User: userRepo,
+			},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+		}
+
+		srv := httptest.NewServer(router.New(&app))
+		defer srv.Close()
+
+		result := problem.Problem{}
+		resp, err := resty.New().
+			R().
+			SetHeader("Content-type", "application/json").
+			SetBody(`{"login": "` + user.Login + `", "password": "` + pwd + `"}`).
+			SetError(&result).
+			Post(srv.URL + "/api/user/login")
+
+		require.NoError(t, err)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode())
+		require.Equal(t, "urn:gophermart:problem:user-blocked", result.Type)
+		require.Empty(t, resp.Header().Get("Authorization"))
+	})
+}
+
+func jsonNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// addCurrent повторяет условие UPDATE: баланс не уходит в минус.
+func addCurrent(balance model.Balance) func(context.Context, int, float64) (*model.Balance, bool, error) {
+	return func(_ context.Context, _ int, amount float64) (*model.Balance, bool, error) {
+		if balance.Current+amount < 0 {
+			return nil, false, nil
+		}
+
+		updated := balance
+		updated.Current += amount
+		return &updated, true, nil
+	}
+}
@@ -0,0 +1,145 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/tracing"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/arefev/gophermart/internal/worker"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+// newSpanRecorder подменяет глобальный провайдер на провайдер с экспортером в память.
+func newSpanRecorder(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+
+	prevProvider := otel.GetTracerProvider()
+	prevPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+		require.NoError(t, tp.Shutdown(context.Background()))
+	})
+
+	return exp
+}
+
+func findSpan(spans tracetest.SpanStubs, name string) *tracetest.SpanStub {
+	for i := range spans {
+		if spans[i].Name == name {
+			return &spans[i]
+		}
+	}
+
+	return nil
+}
+
+func TestTracingHTTPRequest(t *testing.T) {
+	t.Run("http request span with transaction child", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		exp := newSpanRecorder(t)
+
+		conf := config.Config{
+			TokenSecret: gofakeit.DigitN(10),
+			LogLevel:    "debug",
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).DoAndReturn(func(ctx context.Context) (context.Context, error) {
+			return ctx, nil
+		}).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		userRepo := mock_application.NewMockUserRepo(ctrl)
+		userRepo.EXPECT().FindByLogin(gomock.Any(), "gopher").Return(nil, false).Times(1)
+
+		app := application.App{
+			Rep: application.Repository{
+				User: userRepo,
+			},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+		}
+
+		srv := httptest.NewServer(router.New(&app))
+		defer srv.Close()
+
+		traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+		resp, err := resty.New().
+			R().
+			SetHeader("Content-type", "application/json").
+			SetHeader("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01").
+			SetBody(`{"login": "gopher", "password": "secret"}`).
+			Post(srv.URL + "/api/user/login")
+
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+
+		spans := exp.GetSpans()
+		server := findSpan(spans, "POST /api/user/login")
+		require.NotNil(t, server)
+		require.Equal(t, traceID, server.SpanContext.TraceID().String())
+
+		tx := findSpan(spans, "trm.Do")
+		require.NotNil(t, tx)
+		require.Equal(t, server.SpanContext.SpanID(), tx.Parent.SpanID())
+	})
+}
+
+func TestTracingAccrualPropagation(t *testing.T) {
+	t.Run("trace context propagated to accrual", func(t *testing.T) {
+		exp := newSpanRecorder(t)
+
+		var traceparent string
+		accrual := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			traceparent = r.Header.Get("traceparent")
+			writeJSON(w, map[string]any{"order": "12345678903", "status": "PROCESSED", "accrual": 10})
+		}))
+		defer accrual.Close()
+
+		ctx, parent := tracing.Tracer().Start(context.Background(), "worker.job")
+		res := worker.OrderResponse{}
+		err := worker.NewRequest(accrual.URL).Request(ctx, "12345678903", &res)
+		parent.End()
+
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.HTTPStatus)
+
+		span := findSpan(exp.GetSpans(), "accrual.GetOrder")
+		require.NotNil(t, span)
+		require.Equal(t, parent.SpanContext().TraceID(), span.SpanContext.TraceID())
+		require.Contains(t, traceparent, span.SpanContext.TraceID().String())
+		require.Contains(t, traceparent, span.SpanContext.SpanID().String())
+	})
+}
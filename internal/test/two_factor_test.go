@@ -0,0 +1,407 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/problem"
+	"github.com/arefev/gophermart/internal/response"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/service/jwt"
+	"github.com/arefev/gophermart/internal/service/totp"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwoFactorLogin(t *testing.T) {
+	type want struct {
+		code     func(secret string) string
+		totpUses int
+		recovery int
+		status   int
+	}
+
+	tests := []struct {
+		name string
+		want want
+	}{
+		{
+			name: "two factor login with totp code",
+			want: want{
+				code: func(secret string) string {
+					code, _ := totp.Code(secret, time.Now())
+					return code
+				},
+				totpUses: 1,
+				status:   http.StatusOK,
+			},
+		},
+		{
+			name: "two factor login with recovery code",
+			want: want{
+				code: func(_ string) string {
+					return "abcde-fghjk"
+				},
+				recovery: 1,
+				status:   http.StatusOK,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret:   gofakeit.DigitN(10),
+				LogLevel:      "debug",
+				TokenDuration: 5,
+				TwoFactorTTL:  5,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			pwd := gofakeit.Password(true, true, true, true, false, 10)
+			pwdHash := hashPassword(t, pwd)
+
+			secret, err := totp.GenerateSecret()
+			require.NoError(t, err)
+
+			user := model.User{
+				ID:          1,
+				Login:       gofakeit.Username(),
+				Password:    pwdHash,
+				TOTPSecret:  sql.NullString{String: secret, Valid: true},
+				TOTPEnabled: true,
+			}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).AnyTimes()
+			userRepo.EXPECT().UseTOTPStep(gomock.Any(), user.ID, gomock.Any()).Return(true, nil).Times(tt.want.totpUses)
+
+			recoveryRepo := mock_application.NewMockRecoveryCodeRepo(ctrl)
+			recoveryRepo.EXPECT().Use(gomock.Any(), user.ID, gomock.Any()).Return(true, nil).Times(tt.want.recovery)
+
+			app := application.App{
+				Rep: application.Repository{
+					User:         userRepo,
+					RecoveryCode: recoveryRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			r := router.New(&app)
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			body := `{
+				"login": "` + user.Login + `",
+				"password": "` + pwd + `"
+			}`
+
+			token := jwt.Token{}
+			resp, err := resty.New().
+				R().
+				SetHeader("Content-type", "application/json").
+				SetBody(body).
+				SetResult(&token).
+				Post(srv.URL + "/api/user/login")
+
+			require.NoError(t, err)
+			require.Equal(t, http.StatusAccepted, resp.StatusCode())
+			require.Empty(t, resp.Header().Get("Authorization"))
+			require.Equal(t, jwt.ScopeTwoFactor, token.Scope)
+
+			resp, err = resty.New().
+				R().
+				SetHeader("Authorization", "Bearer "+token.AccessToken).
+				Get(srv.URL + "/api/user/balance")
+
+			require.NoError(t, err)
+			require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+
+			resp, err = resty.New().
+				R().
+				SetHeader("Content-type", "application/json").
+				SetHeader("Authorization", "Bearer "+token.AccessToken).
+				SetBody(`{"code": "` + tt.want.code(secret) + `"}`).
+				Post(srv.URL + "/api/user/login/2fa")
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want.status, resp.StatusCode())
+			require.Contains(t, resp.Header().Get("Authorization"), "Bearer ")
+		})
+	}
+}
+
+func TestTwoFactorEnrollConfirm(t *testing.T) {
+	t.Run("two factor enroll and confirm", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{
+			TokenSecret:   gofakeit.DigitN(10),
+			LogLevel:      "debug",
+			TokenDuration: 5,
+			TOTPIssuer:    "Gophermart",
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		pwd := gofakeit.Password(true, true, true, true, false, 10)
+		pwdHash := hashPassword(t, pwd)
+
+		user := model.User{
+			ID:       1,
+			Login:    gofakeit.Username(),
+			Password: pwdHash,
+		}
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		userRepo := mock_application.NewMockUserRepo(ctrl)
+		userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).Return(&user, true).AnyTimes()
+		userRepo.EXPECT().SetTOTPSecret(gomock.Any(), user.ID, gomock.Any()).
+			Do(func(_ context.Context, _ int, secret string) {
+				user.TOTPSecret = sql.NullString{String: secret, Valid: true}
+			}).
+			Return(nil).
+			Times(1)
+		userRepo.EXPECT().EnableTOTP(gomock.Any(), user.ID, gomock.Any()).Return(nil).Times(1)
+
+		recoveryRepo := mock_application.NewMockRecoveryCodeRepo(ctrl)
+		recoveryRepo.EXPECT().Replace(gomock.Any(), user.ID, gomock.Len(10)).Return(nil).Times(1)
+
+		app := application.App{
+			Rep: application.Repository{
+				User:         userRepo,
+				RecoveryCode: recoveryRepo,
+			},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+		}
+
+		r := router.New(&app)
+		srv := httptest.NewServer(r)
+		defer srv.Close()
+
+		body := `{
+			"login": "` + user.Login + `",
+			"password": "` + pwd + `"
+		}`
+
+		resp, err := resty.New().
+			R().
+			SetHeader("Content-type", "application/json").
+			SetBody(body).
+			Post(srv.URL + "/api/user/login")
+
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+
+		hAuth := resp.Header().Get("Authorization")
+		require.Contains(t, hAuth, "Bearer ")
+
+		enroll := response.TwoFactorEnroll{}
+		resp, err = resty.New().
+			R().
+			SetHeader("Authorization", hAuth).
+			SetResult(&enroll).
+			Post(srv.URL + "/api/user/2fa/enroll")
+
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+		require.Equal(t, user.TOTPSecret.String, enroll.Secret)
+		require.Contains(t, enroll.URI, "otpauth://totp/Gophermart:")
+
+		code, err := totp.Code(enroll.Secret, time.Now())
+		require.NoError(t, err)
+
+		codes := response.RecoveryCodes{}
+		resp, err = resty.New().
+			R().
+			SetHeader("Content-type", "application/json").
+			SetHeader("Authorization", hAuth).
+			SetBody(`{"code": "` + code + `"}`).
+			SetResult(&codes).
+			Post(srv.URL + "/api/user/2fa/confirm")
+
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+		require.Len(t, codes.Codes, 10)
+	})
+}
+
+func TestTwoFactorLockout(t *testing.T) {
+	t.Run("pending token revoked after invalid codes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{
+			TokenSecret:       gofakeit.DigitN(10),
+			LogLevel:          "debug",
+			TokenDuration:     5,
+			TwoFactorTTL:      5,
+			TwoFactorAttempts: 3,
+			TwoFactorLockout:  15,
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		pwd := gofakeit.Password(true, true, true, true, false, 10)
+		pwdHash := hashPassword(t, pwd)
+
+		secret, err := totp.GenerateSecret()
+		require.NoError(t, err)
+
+		user := model.User{
+			ID:          1,
+			Login:       gofakeit.Username(),
+			Password:    pwdHash,
+			TOTPSecret:  sql.NullString{String: secret, Valid: true},
+			TOTPEnabled: true,
+		}
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		userRepo := mock_application.NewMockUserRepo(ctrl)
+		userRepo.EXPECT().FindByLogin(gomock.Any(), user.Login).
+			DoAndReturn(func(context.Context, string) (*model.User, bool) {
+				u := user
+				return &u, true
+			}).
+			AnyTimes()
+		lockout := 15 * time.Minute
+		userRepo.EXPECT().TOTPFailed(gomock.Any(), user.ID, conf.TwoFactorAttempts, lockout).
+			DoAndReturn(func(_ context.Context, _ int, _ int, lockout time.Duration) (bool, error) {
+				user.TOTPFailures++
+				if user.TOTPFailures < conf.TwoFactorAttempts {
+					return false, nil
+				}
+
+				user.TOTPFailures = 0
+				user.TOTPLockedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				user.TOTPLockedUntil = sql.NullTime{Time: time.Now().Add(lockout), Valid: true}
+				return true, nil
+			}).
+			Times(conf.TwoFactorAttempts)
+		userRepo.EXPECT().UseTOTPStep(gomock.Any(), user.ID, gomock.Any()).Return(true, nil).Times(1)
+
+		recoveryRepo := mock_application.NewMockRecoveryCodeRepo(ctrl)
+		recoveryRepo.EXPECT().Use(gomock.Any(), user.ID, gomock.Any()).Return(false, nil).Times(conf.TwoFactorAttempts)
+
+		app := application.App{
+			Rep: application.Repository{
+				User:         userRepo,
+				RecoveryCode: recoveryRepo,
+			},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+		}
+
+		srv := httptest.NewServer(router.New(&app))
+		defer srv.Close()
+
+		login := func() string {
+			token := jwt.Token{}
+			resp, err := resty.New().
+				R().
+				SetHeader("Content-type", "application/json").
+				SetBody(`{"login": "` + user.Login + `", "password": "` + pwd + `"}`).
+				SetResult(&token).
+				Post(srv.URL + "/api/user/login")
+
+			require.NoError(t, err)
+			require.Equal(t, http.StatusAccepted, resp.StatusCode())
+			return token.AccessToken
+		}
+
+		secondStep := func(token, code string) problem.Problem {
+			result := problem.Problem{}
+			resp, err := resty.New().
+				R().
+				SetHeader("Content-type", "application/json").
+				SetHeader("Authorization", "Bearer "+token).
+				SetBody(`{"code": "` + code + `"}`).
+				SetError(&result).
+				Post(srv.URL + "/api/user/login/2fa")
+
+			require.NoError(t, err)
+			require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+			return result
+		}
+
+		pending := login()
+		for range conf.TwoFactorAttempts - 1 {
+			require.Equal(t, "urn:gophermart:problem:two-factor-invalid-code", secondStep(pending, "zzzzz-zzzzz").Type)
+		}
+		require.Equal(t, "urn:gophermart:problem:two-factor-locked", secondStep(pending, "zzzzz-zzzzz").Type)
+
+		// Даже верный код не принимается с отозванным токеном
+		code, err := totp.Code(secret, time.Now())
+		require.NoError(t, err)
+		require.Equal(t, "urn:gophermart:problem:unauthorized", secondStep(pending, code).Type)
+
+		// Пока действует блокировка, вход с паролем не дает новых попыток
+		locked := problem.Problem{}
+		resp, err := resty.New().
+			R().
+			SetHeader("Content-type", "application/json").
+			SetBody(`{"login": "` + user.Login + `", "password": "` + pwd + `"}`).
+			SetError(&locked).
+			Post(srv.URL + "/api/user/login")
+
+		require.NoError(t, err)
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode())
+		require.Equal(t, "urn:gophermart:problem:two-factor-locked", locked.Type)
+
+		// После блокировки новый вход с паролем выдает новый токен второго шага
+		user.TOTPLockedAt.Time = time.Now().Add(-lockout - time.Minute)
+		user.TOTPLockedUntil.Time = time.Now().Add(-time.Minute)
+		resp, err = resty.New().
+			R().
+			SetHeader("Content-type", "application/json").
+			SetHeader("Authorization", "Bearer "+login()).
+			SetBody(`{"code": "` + code + `"}`).
+			Post(srv.URL + "/api/user/login/2fa")
+
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	})
+}
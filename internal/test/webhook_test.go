@@ -0,0 +1,394 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/arefev/gophermart/internal/application"
+	mock_application "github.com/arefev/gophermart/internal/application/mocks"
+	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/logger"
+	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/response"
+	"github.com/arefev/gophermart/internal/router"
+	"github.com/arefev/gophermart/internal/service/jwt"
+	"github.com/arefev/gophermart/internal/trm"
+	mock_trm "github.com/arefev/gophermart/internal/trm/mocks"
+	"github.com/arefev/gophermart/internal/webhook"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDispatch(t *testing.T) {
+	type want struct {
+		respStatus int
+		attempts   int
+		delivered  bool
+		dead       bool
+	}
+
+	tests := []struct {
+		name string
+		want want
+	}{
+		{
+			name: "webhook delivered",
+			want: want{
+				respStatus: http.StatusNoContent,
+				delivered:  true,
+			},
+		},
+		{
+			name: "webhook retry after fail",
+			want: want{
+				respStatus: http.StatusInternalServerError,
+				attempts:   2,
+			},
+		},
+		{
+			name: "webhook dead after last attempt",
+			want: want{
+				respStatus: http.StatusInternalServerError,
+				attempts:   7,
+				dead:       true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				LogLevel:           "debug",
+				WebhookMaxAttempts: 8,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			payload, err := webhook.NewPointsPayload(model.WebhookEventPointsAccrued, 1, "45031620082273", 100)
+			require.NoError(t, err)
+
+			secret := "whsec_" + gofakeit.LetterN(20)
+			received := make(chan error, 1)
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				if err == nil {
+					err = webhook.Verify(secret, r.Header.Get(webhook.HeaderSignature), body, time.Minute)
+				}
+				if err == nil && r.Header.Get(webhook.HeaderEvent) != model.WebhookEventPointsAccrued {
+					err = webhook.ErrSignatureInvalid
+				}
+				received <- err
+				w.WriteHeader(tt.want.respStatus)
+			}))
+			defer receiver.Close()
+
+			delivery := model.WebhookDelivery{
+				ID:        10,
+				WebhookID: 1,
+				EventType: model.WebhookEventPointsAccrued,
+				Payload:   payload,
+				URL:       receiver.URL,
+				Secret:    secret,
+				Attempts:  tt.want.attempts,
+			}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			webhookRepo := mock_application.NewMockWebhookRepo(ctrl)
+			webhookRepo.EXPECT().Claim(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, limit int, lease time.Duration) []model.WebhookDelivery {
+					// Аренда покрывает отправку всей пачки по таймауту запроса 10 секунд
+					require.GreaterOrEqual(t, lease, time.Duration(limit)*10*time.Second)
+					return []model.WebhookDelivery{delivery}
+				}).
+				Times(1)
+
+			if tt.want.delivered {
+				webhookRepo.EXPECT().Delivered(gomock.Any(), delivery.ID, tt.want.respStatus).Return(nil).Times(1)
+			} else {
+				retry := webhook.Backoff(tt.want.attempts + 1)
+				webhookRepo.EXPECT().
+					Failed(gomock.Any(), delivery.ID, tt.want.respStatus, gomock.Any(), retry, tt.want.dead).
+					Return(nil).
+					Times(1)
+			}
+
+			app := application.App{
+				Rep: application.Repository{
+					Webhook: webhookRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			count := webhook.NewDispatcher(&app).Dispatch(context.Background())
+			require.Equal(t, 1, count)
+			require.NoError(t, <-received)
+		})
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	t.Run("webhook signature", func(t *testing.T) {
+		body := []byte(`{"type":"points.accrued"}`)
+		now := time.Now().Unix()
+
+		header := webhook.Sign("secret", now, body)
+		require.Contains(t, header, "t="+strconv.FormatInt(now, 10)+",v1=")
+		require.NoError(t, webhook.Verify("secret", header, body, time.Minute))
+		require.ErrorIs(t, webhook.Verify("other", header, body, time.Minute), webhook.ErrSignatureInvalid)
+		require.ErrorIs(t, webhook.Verify("secret", header, []byte(`{}`), time.Minute), webhook.ErrSignatureInvalid)
+
+		old := webhook.Sign("secret", now-3600, body)
+		require.ErrorIs(t, webhook.Verify("secret", old, body, time.Minute), webhook.ErrSignatureInvalid)
+	})
+}
+
+func TestAdminWebhookCreate(t *testing.T) {
+	type want struct {
+		body    string
+		role    string
+		owner   string
+		ownerID int
+		creates int
+		status  int
+	}
+
+	owner := model.User{ID: 7, Login: "merchant"}
+
+	tests := []struct {
+		name string
+		want want
+	}{
+		{
+			name: "admin webhook create success",
+			want: want{
+				body:    `{"url": "https://shop.example/hooks", "events": ["` + model.WebhookEventPointsAccrued + `"]}`,
+				role:    model.RoleAdmin,
+				creates: 1,
+				status:  http.StatusCreated,
+			},
+		},
+		{
+			name: "admin webhook create for user",
+			want: want{
+				body: `{"url": "https://shop.example/hooks", "login": "` + owner.Login +
+					`", "events": ["` + model.WebhookEventPointsAccrued + `"]}`,
+				role:    model.RoleAdmin,
+				owner:   owner.Login,
+				ownerID: owner.ID,
+				creates: 1,
+				status:  http.StatusCreated,
+			},
+		},
+		{
+			name: "admin webhook create for unknown user",
+			want: want{
+				body:   `{"url": "https://shop.example/hooks", "login": "nobody"}`,
+				role:   model.RoleAdmin,
+				owner:  "nobody",
+				status: http.StatusNotFound,
+			},
+		},
+		{
+			name: "admin webhook create unknown event",
+			want: want{
+				body:   `{"url": "https://shop.example/hooks", "events": ["orders.deleted"]}`,
+				role:   model.RoleAdmin,
+				status: http.StatusUnprocessableEntity,
+			},
+		},
+		{
+			name: "admin webhook create bad url",
+			want: want{
+				body:   `{"url": "not a url"}`,
+				role:   model.RoleAdmin,
+				status: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "admin webhook create forbidden",
+			want: want{
+				body:   `{"url": "https://shop.example/hooks"}`,
+				role:   model.RoleSupport,
+				status: http.StatusForbidden,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret:   gofakeit.DigitN(10),
+				LogLevel:      "debug",
+				TokenDuration: 5,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			staff := model.User{
+				ID:    1,
+				Login: gofakeit.Username(),
+				Roles: model.Roles{tt.want.role},
+			}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), staff.Login).Return(&staff, true).AnyTimes()
+			if tt.want.owner != "" {
+				found := tt.want.ownerID > 0
+				userRepo.EXPECT().FindByLogin(gomock.Any(), tt.want.owner).Return(&owner, found).Times(1)
+			}
+
+			webhookRepo := mock_application.NewMockWebhookRepo(ctrl)
+			webhookRepo.EXPECT().
+				Create(
+					gomock.Any(),
+					tt.want.ownerID,
+					"https://shop.example/hooks",
+					gomock.Any(),
+					[]string{model.WebhookEventPointsAccrued},
+				).
+				Return(3, nil).
+				Times(tt.want.creates)
+
+			app := application.App{
+				Rep: application.Repository{
+					User:    userRepo,
+					Webhook: webhookRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			srv := httptest.NewServer(router.New(&app))
+			defer srv.Close()
+
+			token, err := jwt.NewToken(conf.TokenSecret).GenerateToken(&staff, conf.TokenDuration)
+			require.NoError(t, err)
+
+			created := response.Webhook{}
+			resp, err := resty.New().
+				R().
+				SetHeader("Content-type", "application/json").
+				SetHeader("Authorization", "Bearer "+token.AccessToken).
+				SetBody(tt.want.body).
+				SetResult(&created).
+				Post(srv.URL + "/api/admin/webhooks")
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want.status, resp.StatusCode())
+			if tt.want.status == http.StatusCreated {
+				require.Equal(t, 3, created.ID)
+				require.Equal(t, tt.want.ownerID, created.UserID)
+				require.Contains(t, created.Secret, "whsec_")
+			}
+		})
+	}
+}
+
+func TestAdminWebhookDeactivate(t *testing.T) {
+	tests := []struct {
+		name    string
+		found   bool
+		cancels int
+		status  int
+	}{
+		{
+			name:    "admin webhook deactivate cancels pending deliveries",
+			found:   true,
+			cancels: 1,
+			status:  http.StatusOK,
+		},
+		{
+			name:   "admin webhook deactivate not found",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret:   gofakeit.DigitN(10),
+				LogLevel:      "debug",
+				TokenDuration: 5,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			staff := model.User{
+				ID:    1,
+				Login: gofakeit.Username(),
+				Roles: model.Roles{model.RoleAdmin},
+			}
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+			userRepo := mock_application.NewMockUserRepo(ctrl)
+			userRepo.EXPECT().FindByLogin(gomock.Any(), staff.Login).Return(&staff, true).AnyTimes()
+
+			webhookRepo := mock_application.NewMockWebhookRepo(ctrl)
+			webhookRepo.EXPECT().Deactivate(gomock.Any(), 3).Return(tt.found, nil).Times(1)
+			webhookRepo.EXPECT().CancelPending(gomock.Any(), 3).Return(nil).Times(tt.cancels)
+
+			app := application.App{
+				Rep: application.Repository{
+					User:    userRepo,
+					Webhook: webhookRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			srv := httptest.NewServer(router.New(&app))
+			defer srv.Close()
+
+			token, err := jwt.NewToken(conf.TokenSecret).GenerateToken(&staff, conf.TokenDuration)
+			require.NoError(t, err)
+
+			resp, err := resty.New().
+				R().
+				SetHeader("Authorization", "Bearer "+token.AccessToken).
+				Delete(srv.URL + "/api/admin/webhooks/3")
+
+			require.NoError(t, err)
+			require.Equal(t, tt.status, resp.StatusCode())
+		})
+	}
+}
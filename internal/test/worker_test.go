@@ -3,12 +3,15 @@ package test
 import (
 	"context"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/arefev/gophermart/internal/application"
 	mock_application "github.com/arefev/gophermart/internal/application/mocks"
 	"github.com/arefev/gophermart/internal/config"
+	"github.com/arefev/gophermart/internal/health"
 	"github.com/arefev/gophermart/internal/logger"
 	"github.com/arefev/gophermart/internal/model"
 	"github.com/arefev/gophermart/internal/trm"
@@ -30,10 +33,11 @@ func TestWorkerSuccess(t *testing.T) {
 		defer ctrl.Finish()
 
 		conf := config.Config{
-			TokenSecret:  gofakeit.DigitN(10),
-			PollInterval: 2,
-			LogLevel:     "debug",
-			RateLimit:    10,
+			TokenSecret:        gofakeit.DigitN(10),
+			PollInterval:       2,
+			LogLevel:           "debug",
+			RateLimit:          10,
+			HealthWorkerMaxAge: 2,
 		}
 
 		zLog, err := logger.Build(conf.LogLevel)
@@ -77,7 +81,19 @@ func TestWorkerSuccess(t *testing.T) {
 
 		orderRepo := mock_application.NewMockOrderRepo(ctrl)
 		orderRepo.EXPECT().WithStatusNew(gomock.Any()).Return(newOrders).MinTimes(1)
-		orderRepo.EXPECT().AccrualByID(gomock.Any(), accrual, newStatus, order.ID).Return(nil).MinTimes(1)
+		orderRepo.EXPECT().AccrualByID(gomock.Any(), accrual, newStatus, order.ID).Return(true, nil).MinTimes(1)
+
+		eventRepo := mock_application.NewMockEventRepo(ctrl)
+		eventRepo.EXPECT().Create(gomock.Any(), user.ID, model.EventBalance, gomock.Any()).Return(nil).MinTimes(1)
+		eventRepo.EXPECT().Create(gomock.Any(), user.ID, model.EventOrder, gomock.Any()).Return(nil).MinTimes(1)
+
+		webhookRepo := mock_application.NewMockWebhookRepo(ctrl)
+		webhookRepo.EXPECT().Enqueue(gomock.Any(), user.ID, model.WebhookEventPointsAccrued, gomock.Any()).
+			Return(nil).
+			MinTimes(1)
+
+		outboxRepo := mock_application.NewMockOutboxRepo(ctrl)
+		outboxRepo.EXPECT().Add(gomock.Any(), model.OutboxOrderProcessed, gomock.Any()).Return(nil).MinTimes(1)
 
 		r := mock_worker.NewMockStatusRequest(ctrl)
 		r.EXPECT().Request(gomock.Any(), order.Number, &res).
@@ -91,14 +107,21 @@ func TestWorkerSuccess(t *testing.T) {
 			Rep: application.Repository{
 				Order:   orderRepo,
 				Balance: balanceRepo,
+				Event:   eventRepo,
+				Webhook: webhookRepo,
+				Outbox:  outboxRepo,
 			},
 			TrManager: trManager,
 			Log:       zLog,
 			Conf:      &conf,
+			Health:    health.New(),
 		}
 
 		err = worker.NewWorker(&app, r).Run(ctx)
 		require.Error(t, err)
+
+		// Во время паузы после 429 отметка готовности продолжает обновляться
+		require.Less(t, time.Since(app.Health.Worker().Last()), 1500*time.Millisecond)
 	})
 }
 
@@ -158,7 +181,7 @@ func TestWorkerWait(t *testing.T) {
 
 		orderRepo := mock_application.NewMockOrderRepo(ctrl)
 		orderRepo.EXPECT().WithStatusNew(gomock.Any()).Return(newOrders).AnyTimes()
-		orderRepo.EXPECT().AccrualByID(gomock.Any(), accrual, newStatus, order.ID).Return(nil).MaxTimes(0)
+		orderRepo.EXPECT().AccrualByID(gomock.Any(), accrual, newStatus, order.ID).Return(true, nil).MaxTimes(0)
 
 		r := mock_worker.NewMockStatusRequest(ctrl)
 		r.EXPECT().Request(gomock.Any(), order.Number, &res).
@@ -184,3 +207,422 @@ func TestWorkerWait(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestWorkerGracefulStop(t *testing.T) {
+	type want struct {
+		committed bool
+		err       error
+	}
+
+	tests := []struct {
+		name    string
+		latency time.Duration
+		want    want
+	}{
+		{
+			name:    "in-flight job finished before stop",
+			latency: 500 * time.Millisecond,
+			want: want{
+				committed: true,
+				err:       context.Canceled,
+			},
+		},
+		{
+			name:    "drain timeout",
+			latency: 3 * time.Second,
+			want: want{
+				err: worker.ErrDrainTimeout,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			conf := config.Config{
+				TokenSecret:     gofakeit.DigitN(10),
+				PollInterval:    1,
+				LogLevel:        "debug",
+				RateLimit:       2,
+				ShutdownTimeout: 1,
+			}
+
+			zLog, err := logger.Build(conf.LogLevel)
+			require.NoError(t, err)
+
+			order := model.Order{
+				ID:     1,
+				UserID: 1,
+				Number: "45031620082273",
+				Status: model.OrderStatusNew,
+			}
+
+			var (
+				mu        sync.Mutex
+				began     int
+				committed int
+				rollbacks int
+			)
+
+			// Обе транзакции (выборка заказов и задание) дошли до Rollback из defer trm.Do
+			finished := make(chan struct{})
+
+			tr := mock_trm.NewMockTransaction(ctrl)
+			trManager := trm.NewTrm(tr, zLog)
+			tr.EXPECT().Begin(gomock.Any()).DoAndReturn(func(ctx context.Context) (context.Context, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				began++
+				return ctx, nil
+			}).AnyTimes()
+			tr.EXPECT().Commit(gomock.Any()).DoAndReturn(func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				committed++
+				return nil
+			}).AnyTimes()
+			tr.EXPECT().Rollback(gomock.Any()).DoAndReturn(func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				rollbacks++
+				if rollbacks == 2 {
+					close(finished)
+				}
+				return nil
+			}).Times(2)
+
+			orderRepo := mock_application.NewMockOrderRepo(ctrl)
+			orderRepo.EXPECT().WithStatusNew(gomock.Any()).Return([]model.Order{order}).Times(1)
+
+			// Промежуточный статус сохраняется, клиенты получают событие о нем.
+			// Брошенное по таймауту задание может завершиться уже после выхода из Run
+			checks := 0
+			if tt.want.committed {
+				checks = 1
+			}
+
+			orderRepo.EXPECT().CheckedByID(gomock.Any(), model.OrderStatusProcessing, order.ID).
+				Return(true, nil).
+				MinTimes(checks).
+				MaxTimes(1)
+
+			eventRepo := mock_application.NewMockEventRepo(ctrl)
+			eventRepo.EXPECT().
+				Create(gomock.Any(), order.UserID, model.EventOrder, `{"number":"`+order.Number+`","status":"PROCESSING"}`).
+				Return(nil).
+				MinTimes(checks).
+				MaxTimes(1)
+
+			started := make(chan struct{})
+			r := mock_worker.NewMockStatusRequest(ctrl)
+			r.EXPECT().Request(gomock.Any(), order.Number, gomock.Any()).
+				DoAndReturn(func(ctx context.Context, _ string, res *worker.OrderResponse) error {
+					close(started)
+					time.Sleep(tt.latency)
+
+					// Отмена Run не должна прерывать начатое задание
+					require.NoError(t, ctx.Err())
+					res.Status = model.OrderStatusProcessing.String()
+					res.HTTPStatus = http.StatusOK
+					return nil
+				}).
+				Times(1)
+
+			app := application.App{
+				Rep: application.Repository{
+					Order: orderRepo,
+					Event: eventRepo,
+				},
+				TrManager: trManager,
+				Log:       zLog,
+				Conf:      &conf,
+			}
+
+			done := make(chan error, 1)
+			go func() {
+				done <- worker.NewWorker(&app, r).Run(ctx)
+			}()
+
+			<-started
+			cancel()
+
+			select {
+			case err = <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("worker did not stop")
+			}
+
+			require.ErrorIs(t, err, tt.want.err)
+
+			if tt.want.committed {
+				mu.Lock()
+				// Все открытые транзакции, включая транзакцию задания, завершились до выхода из Run
+				require.Equal(t, began, committed)
+				require.Equal(t, 2, committed)
+				mu.Unlock()
+			}
+
+			// Задание, брошенное по таймауту, дожидаемся до проверки моков
+			<-finished
+		})
+	}
+}
+
+func TestWorkerReload(t *testing.T) {
+	t.Run("poll interval reloaded", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{
+			TokenSecret:  gofakeit.DigitN(10),
+			PollInterval: 60,
+			LogLevel:     "debug",
+			RateLimit:    2,
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).DoAndReturn(func(ctx context.Context) (context.Context, error) {
+			return ctx, nil
+		}).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		polled := make(chan struct{}, 1)
+		orderRepo := mock_application.NewMockOrderRepo(ctrl)
+		orderRepo.EXPECT().WithStatusNew(gomock.Any()).DoAndReturn(func(context.Context) []model.Order {
+			select {
+			case polled <- struct{}{}:
+			default:
+			}
+			return []model.Order{}
+		}).MinTimes(1)
+
+		app := application.App{
+			Rep: application.Repository{
+				Order: orderRepo,
+			},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+		}
+
+		w := worker.NewWorker(&app, mock_worker.NewMockStatusRequest(ctrl))
+		done := make(chan error, 1)
+		go func() {
+			done <- w.Run(ctx)
+		}()
+
+		require.NoError(t, w.Reload(ctx, worker.Settings{PollInterval: 1, RateLimit: 1}))
+
+		select {
+		case <-polled:
+		case <-time.After(3 * time.Second):
+			t.Fatal("worker did not poll with reloaded interval")
+		}
+
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+	})
+
+	t.Run("reload keeps pause", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{
+			TokenSecret:  gofakeit.DigitN(10),
+			PollInterval: 1,
+			LogLevel:     "debug",
+			RateLimit:    2,
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).DoAndReturn(func(ctx context.Context) (context.Context, error) {
+			return ctx, nil
+		}).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		order := model.Order{ID: 1, UserID: 1, Number: "45031620082273", Status: model.OrderStatusNew}
+
+		var polls atomic.Int64
+		orderRepo := mock_application.NewMockOrderRepo(ctrl)
+		orderRepo.EXPECT().WithStatusNew(gomock.Any()).DoAndReturn(func(context.Context) []model.Order {
+			polls.Add(1)
+			return []model.Order{order}
+		}).MinTimes(1)
+
+		limited := make(chan struct{}, 1)
+		r := mock_worker.NewMockStatusRequest(ctrl)
+		r.EXPECT().Request(gomock.Any(), order.Number, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, res *worker.OrderResponse) error {
+				res.Header = http.Header{"Retry-After": []string{"60"}}
+				res.HTTPStatus = http.StatusTooManyRequests
+				select {
+				case limited <- struct{}{}:
+				default:
+				}
+				return nil
+			}).
+			AnyTimes()
+
+		app := application.App{
+			Rep: application.Repository{
+				Order: orderRepo,
+			},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+		}
+
+		w := worker.NewWorker(&app, r)
+		done := make(chan error, 1)
+		go func() {
+			done <- w.Run(ctx)
+		}()
+
+		select {
+		case <-limited:
+		case <-time.After(3 * time.Second):
+			t.Fatal("worker did not request accrual")
+		}
+
+		// Перезагрузка во время паузы не должна возобновлять опрос
+		for range 3 {
+			require.NoError(t, w.Reload(ctx, worker.Settings{PollInterval: 1, RateLimit: 3}))
+		}
+
+		paused := polls.Load()
+		time.Sleep(2500 * time.Millisecond)
+		require.LessOrEqual(t, polls.Load(), paused+1)
+
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+	})
+}
+
+func TestWorkerRecheckRace(t *testing.T) {
+	t.Run("recheck races queued job", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conf := config.Config{
+			TokenSecret:  gofakeit.DigitN(10),
+			PollInterval: 1,
+			LogLevel:     "debug",
+			RateLimit:    2,
+		}
+
+		zLog, err := logger.Build(conf.LogLevel)
+		require.NoError(t, err)
+
+		accrual := 100.0
+		balance := model.Balance{ID: 1, UserID: 1, Current: 500}
+		order := model.Order{
+			ID:     1,
+			UserID: 1,
+			Number: "45031620082273",
+			Status: model.OrderStatusNew,
+		}
+
+		tr := mock_trm.NewMockTransaction(ctrl)
+		trManager := trm.NewTrm(tr, zLog)
+		tr.EXPECT().Begin(gomock.Any()).AnyTimes()
+		tr.EXPECT().Commit(gomock.Any()).AnyTimes()
+		tr.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+		// Повторяет условие UPDATE: окончательный статус ставится только один раз
+		var finalized atomic.Bool
+		orderRepo := mock_application.NewMockOrderRepo(ctrl)
+		orderRepo.EXPECT().WithStatusNew(gomock.Any()).Return([]model.Order{order}).AnyTimes()
+		orderRepo.EXPECT().AccrualByID(gomock.Any(), accrual, model.OrderStatusProcessed, order.ID).
+			DoAndReturn(func(context.Context, float64, model.OrderStatus, int) (bool, error) {
+				return finalized.CompareAndSwap(false, true), nil
+			}).
+			MinTimes(2)
+
+		balanceRepo := mock_application.NewMockBalanceRepo(ctrl)
+		balanceRepo.EXPECT().FindByUserID(gomock.Any(), balance.UserID).Return(&balance, true).Times(1)
+		balanceRepo.EXPECT().UpdateByID(gomock.Any(), balance.ID, balance.Current+accrual, 0.0).Return(nil).Times(1)
+
+		eventRepo := mock_application.NewMockEventRepo(ctrl)
+		eventRepo.EXPECT().Create(gomock.Any(), order.UserID, gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+		webhookRepo := mock_application.NewMockWebhookRepo(ctrl)
+		webhookRepo.EXPECT().Enqueue(gomock.Any(), order.UserID, model.WebhookEventPointsAccrued, gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		outboxRepo := mock_application.NewMockOutboxRepo(ctrl)
+		outboxRepo.EXPECT().Add(gomock.Any(), model.OutboxOrderProcessed, gomock.Any()).Return(nil).Times(1)
+
+		// Первые два запроса (задание воркера и внеочередная проверка) ждут друг друга,
+		// чтобы оба получили ответ системы начислений до записи в БД
+		var (
+			calls   atomic.Int64
+			barrier sync.WaitGroup
+		)
+		barrier.Add(2)
+
+		r := mock_worker.NewMockStatusRequest(ctrl)
+		r.EXPECT().Request(gomock.Any(), order.Number, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, res *worker.OrderResponse) error {
+				if calls.Add(1) <= 2 {
+					barrier.Done()
+					barrier.Wait()
+				}
+
+				res.Status = model.OrderStatusProcessed.String()
+				res.Accrual = accrual
+				res.HTTPStatus = http.StatusOK
+				return nil
+			}).
+			AnyTimes()
+
+		app := application.App{
+			Rep: application.Repository{
+				Order:   orderRepo,
+				Balance: balanceRepo,
+				Event:   eventRepo,
+				Webhook: webhookRepo,
+				Outbox:  outboxRepo,
+			},
+			TrManager: trManager,
+			Log:       zLog,
+			Conf:      &conf,
+		}
+
+		wk := worker.NewWorker(&app, r)
+		done := make(chan error)
+		go func() {
+			done <- wk.Run(ctx)
+		}()
+
+		recheckOrder := order
+		require.NoError(t, wk.Recheck(ctx, &recheckOrder))
+
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+	})
+}
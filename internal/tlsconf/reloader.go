@@ -0,0 +1,119 @@
+package tlsconf
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Reloader отдает текущий сертификат сервера и перечитывает его, когда файлы
+// сертификата или ключа меняются на диске, чтобы ротация не требовала перезапуска.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	log      *zap.Logger
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	modTime  time.Time
+}
+
+func NewReloader(certFile, keyFile string, log *zap.Logger) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		log:      log,
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetCertificate подходит для tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// Watch проверяет файлы раз в interval до отмены ctx. Ошибка перечитывания
+// только логируется: сервер продолжает работать с прежним сертификатом.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			changed, err := r.changed()
+			if err != nil || !changed {
+				continue
+			}
+
+			if err := r.load(); err != nil {
+				r.log.Error("tls certificate reload failed", zap.Error(err))
+				continue
+			}
+
+			r.log.Info("TLS certificate reloaded", zap.String("cert", r.certFile))
+		}
+	}
+}
+
+func (r *Reloader) load() error {
+	modTime, err := r.lastModified()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load key pair fail: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+func (r *Reloader) changed() (bool, error) {
+	modTime, err := r.lastModified()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return !modTime.Equal(r.modTime), nil
+}
+
+// lastModified время последнего изменения из двух файлов.
+func (r *Reloader) lastModified() (time.Time, error) {
+	var last time.Time
+	for _, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("stat %s fail: %w", path, err)
+		}
+
+		if info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+
+	return last, nil
+}
@@ -0,0 +1,110 @@
+package tlsconf
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	ErrVersionUnknown = errors.New("unknown tls version")
+	ErrCipherUnknown  = errors.New("unknown or insecure cipher suite")
+	ErrClientCA       = errors.New("no certificates in client ca file")
+)
+
+var versions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+type Options struct {
+	MinVersion string
+	// Ciphers имена наборов через запятую, применяются только к TLS 1.2:
+	// наборы TLS 1.3 в Go не настраиваются
+	Ciphers string
+	// ClientCA включает mTLS: клиент обязан предъявить сертификат, подписанный этим CA
+	ClientCA string
+}
+
+// New конфигурация TLS сервера с сертификатом из r.
+func New(r *Reloader, opts Options) (*tls.Config, error) {
+	version, err := ParseVersion(opts.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphers, err := ParseCiphers(opts.Ciphers)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := &tls.Config{
+		MinVersion:     version,
+		CipherSuites:   ciphers,
+		GetCertificate: r.GetCertificate,
+	}
+
+	if opts.ClientCA != "" {
+		pool, err := loadPool(opts.ClientCA)
+		if err != nil {
+			return nil, err
+		}
+
+		conf.ClientCAs = pool
+		conf.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return conf, nil
+}
+
+// ParseVersion минимальная версия TLS: 1.2 или 1.3.
+func ParseVersion(v string) (uint16, error) {
+	version, ok := versions[v]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q, use 1.2 or 1.3", ErrVersionUnknown, v)
+	}
+
+	return version, nil
+}
+
+// ParseCiphers разбирает список наборов шифров. Пустой список оставляет выбор Go.
+func ParseCiphers(list string) ([]uint16, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+
+	known := map[string]uint16{}
+	for _, s := range tls.CipherSuites() {
+		known[s.Name] = s.ID
+	}
+
+	var ids []uint16
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrCipherUnknown, name)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func loadPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read client ca fail: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%w: %s", ErrClientCA, path)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,193 @@
+package tlsconf
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	tls  tls.Certificate
+}
+
+// issue выпускает сертификат, подписанный parent, или самоподписанный CA при parent == nil.
+func issue(t *testing.T, name string, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{
+		cert: cert,
+		key:  key,
+		tls:  tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert},
+	}
+}
+
+func (c *testCert) write(t *testing.T, dir string) (string, string) {
+	t.Helper()
+
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+
+	return certFile, keyFile
+}
+
+func TestParse(t *testing.T) {
+	version, err := ParseVersion("1.3")
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS13), version)
+
+	_, err = ParseVersion("1.0")
+	require.ErrorIs(t, err, ErrVersionUnknown)
+
+	ciphers, err := ParseCiphers("TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384")
+	require.NoError(t, err)
+	require.Equal(t, []uint16{
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	}, ciphers)
+
+	ciphers, err = ParseCiphers("")
+	require.NoError(t, err)
+	require.Nil(t, ciphers)
+
+	_, err = ParseCiphers("TLS_RSA_WITH_RC4_128_SHA")
+	require.ErrorIs(t, err, ErrCipherUnknown)
+}
+
+func TestReloaderWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	ca := issue(t, "ca", nil)
+	certFile, keyFile := issue(t, "first", ca).write(t, dir)
+
+	r, err := NewReloader(certFile, keyFile, zap.NewNop())
+	require.NoError(t, err)
+
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "first", cert.Leaf.Subject.CommonName)
+
+	go func() {
+		_ = r.Watch(ctx, 10*time.Millisecond)
+	}()
+
+	issue(t, "second", ca).write(t, dir)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+
+	require.Eventually(t, func() bool {
+		cert, err := r.GetCertificate(nil)
+		return err == nil && cert.Leaf.Subject.CommonName == "second"
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestClientCA(t *testing.T) {
+	dir := t.TempDir()
+	ca := issue(t, "ca", nil)
+	certFile, keyFile := issue(t, "server", ca).write(t, dir)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0o600))
+
+	r, err := NewReloader(certFile, keyFile, zap.NewNop())
+	require.NoError(t, err)
+
+	conf, err := New(r, Options{MinVersion: "1.2", ClientCA: caFile})
+	require.NoError(t, err)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+		TLSConfig:         conf,
+		ReadHeaderTimeout: time.Second,
+		ErrorLog:          log.New(io.Discard, "", 0),
+	}
+	go func() {
+		_ = srv.ServeTLS(ln, "", "")
+	}()
+	defer srv.Close()
+
+	url := "https://" + ln.Addr().String()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	client := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: certs, MinVersion: tls.VersionTLS12},
+			ForceAttemptHTTP2: true,
+		}}
+	}
+
+	_, err = client().Get(url)
+	require.Error(t, err)
+
+	resp, err := client(issue(t, "client", ca).tls).Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, 2, resp.ProtoMajor)
+}
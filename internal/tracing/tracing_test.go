@@ -0,0 +1,21 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("exporter none", func(t *testing.T) {
+		shutdown, err := Setup(context.Background(), ExporterNone, "")
+		require.NoError(t, err)
+		require.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("exporter unknown", func(t *testing.T) {
+		_, err := Setup(context.Background(), "jaeger", "")
+		require.ErrorIs(t, err, ErrExporterUnknown)
+	})
+}
@@ -3,6 +3,12 @@ package trm
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"github.com/arefev/gophermart/internal/metrics"
+	"github.com/arefev/gophermart/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"go.uber.org/zap"
 )
@@ -28,6 +34,18 @@ func NewTrm(tr Transaction, log *zap.Logger) *trm {
 }
 
 func (trm *trm) Do(ctx context.Context, action TrAction) error {
+	start := time.Now()
+	ctx, span := tracing.Tracer().Start(ctx, "trm.Do")
+
+	err := trm.do(ctx, span, action)
+
+	tracing.End(span, err)
+	metrics.TrmDuration.Observe(time.Since(start).Seconds())
+	return err
+}
+
+// do спан передается отдельно: контекст после Begin заменяется контекстом транзакции.
+func (trm *trm) do(ctx context.Context, span trace.Span, action TrAction) error {
 	var err error
 	ctx, err = trm.tr.Begin(ctx)
 	if err != nil {
@@ -41,13 +59,20 @@ func (trm *trm) Do(ctx context.Context, action TrAction) error {
 	}()
 
 	if err := action(ctx); err != nil {
+		trm.rollback(span)
 		return fmt.Errorf("trm action fail: %w", err)
 	}
 
 	err = trm.tr.Commit(ctx)
 	if err != nil {
+		trm.rollback(span)
 		return fmt.Errorf("trm commit fail: %w", err)
 	}
 
 	return nil
 }
+
+func (trm *trm) rollback(span trace.Span) {
+	metrics.TrmRollbacks.Inc()
+	span.SetAttributes(attribute.Bool("trm.rollback", true))
+}
@@ -0,0 +1,173 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/model"
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderEvent     = "X-Gophermart-Event"
+	HeaderDelivery  = "X-Gophermart-Delivery"
+	HeaderTimestamp = "X-Gophermart-Timestamp"
+	HeaderSignature = "X-Gophermart-Signature"
+)
+
+const (
+	defaultInterval    = 5
+	defaultMaxAttempts = 8
+	batchSize          = 50
+	requestTimeout     = 10 * time.Second
+	retryBase          = 10 * time.Second
+	retryMax           = time.Hour
+	errorMaxLength     = 500
+
+	// claimLease доставки пачки отправляются по очереди, поэтому аренда покрывает
+	// все запросы пачки с запасом на один запрос.
+	claimLease = (batchSize + 1) * requestTimeout
+)
+
+type dispatcher struct {
+	app    *application.App
+	client *http.Client
+}
+
+func NewDispatcher(app *application.App) *dispatcher {
+	return &dispatcher{
+		app:    app,
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (d *dispatcher) Run(ctx context.Context) error {
+	d.app.Log.Info("Webhook dispatcher started")
+
+	ticker := time.NewTicker(d.interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.app.Log.Info("Webhook dispatcher stopped")
+			return fmt.Errorf("webhook dispatcher stopped: %w", ctx.Err())
+		case <-ticker.C:
+			d.Dispatch(ctx)
+		}
+	}
+}
+
+// Dispatch отправляет доставки, время которых пришло, и возвращает их количество.
+func (d *dispatcher) Dispatch(ctx context.Context) int {
+	var list []model.WebhookDelivery
+	deadline := time.Now().Add(claimLease)
+	err := d.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		list = d.app.Rep.Webhook.Claim(ctx, batchSize, claimLease)
+		return nil
+	})
+
+	if err != nil {
+		d.app.Log.Error("webhook claim transaction fail", zap.Error(err))
+		return 0
+	}
+
+	for i := range list {
+		// Оставшиеся доставки заберет следующий проход после окончания аренды,
+		// отправка после нее могла бы задвоиться
+		if time.Until(deadline) < requestTimeout {
+			d.app.Log.Warn("webhook claim lease expires", zap.Int("left", len(list)-i))
+			return i
+		}
+
+		d.deliver(ctx, &list[i])
+	}
+
+	return len(list)
+}
+
+func (d *dispatcher) deliver(ctx context.Context, dl *model.WebhookDelivery) {
+	code, err := d.send(ctx, dl)
+
+	err = d.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		if err == nil {
+			return d.app.Rep.Webhook.Delivered(ctx, dl.ID, code)
+		}
+
+		attempts := dl.Attempts + 1
+		dead := attempts >= d.maxAttempts()
+		reason := err.Error()
+		if len(reason) > errorMaxLength {
+			reason = reason[:errorMaxLength]
+		}
+
+		return d.app.Rep.Webhook.Failed(ctx, dl.ID, code, reason, Backoff(attempts), dead)
+	})
+
+	if err != nil {
+		d.app.Log.Error("webhook delivery save fail", zap.Int64("delivery", dl.ID), zap.Error(err))
+	}
+}
+
+func (d *dispatcher) send(ctx context.Context, dl *model.WebhookDelivery) (int, error) {
+	body := []byte(dl.Payload)
+	ts := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dl.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("new request fail: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, dl.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(dl.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(dl.Secret, ts, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("send request fail: %w", err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Backoff экспоненциальная задержка перед повторной попыткой с верхней границей.
+func Backoff(attempts int) time.Duration {
+	d := retryBase
+	for i := 1; i < attempts && d < retryMax; i++ {
+		d *= 2
+	}
+
+	return min(d, retryMax)
+}
+
+func (d *dispatcher) interval() time.Duration {
+	if d.app.Conf.WebhookInterval > 0 {
+		return time.Duration(d.app.Conf.WebhookInterval) * time.Second
+	}
+
+	return defaultInterval * time.Second
+}
+
+func (d *dispatcher) maxAttempts() int {
+	if d.app.Conf.WebhookMaxAttempts > 0 {
+		return d.app.Conf.WebhookMaxAttempts
+	}
+
+	return defaultMaxAttempts
+}
@@ -0,0 +1,33 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type Payload struct {
+	OccurredAt time.Time `json:"occurred_at"`
+	Type       string    `json:"type"`
+	Data       any       `json:"data"`
+}
+
+type PointsPayload struct {
+	Order  string  `json:"order"`
+	Amount float64 `json:"amount"`
+	UserID int     `json:"user_id"`
+}
+
+// NewPointsPayload тело события начисления или списания баллов.
+func NewPointsPayload(eventType string, userID int, order string, amount float64) (string, error) {
+	b, err := json.Marshal(Payload{
+		OccurredAt: time.Now().UTC(),
+		Type:       eventType,
+		Data:       PointsPayload{Order: order, Amount: amount, UserID: userID},
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal webhook payload fail: %w", err)
+	}
+
+	return string(b), nil
+}
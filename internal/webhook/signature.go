@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const signatureVersion = "v1"
+
+var ErrSignatureInvalid = errors.New("webhook signature invalid")
+
+// Sign возвращает значение заголовка подписи вида t=<timestamp>,v1=<hmac>.
+// Подписывается строка "<timestamp>.<body>", timestamp защищает от повтора запроса.
+func Sign(secret string, timestamp int64, body []byte) string {
+	ts := strconv.FormatInt(timestamp, 10)
+	return "t=" + ts + "," + signatureVersion + "=" + digest(secret, ts, body)
+}
+
+// Verify проверяет подпись получателем, tolerance ограничивает возраст запроса.
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			ts = value
+		case signatureVersion:
+			sig = value
+		}
+	}
+
+	timestamp, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return ErrSignatureInvalid
+	}
+
+	if tolerance > 0 && time.Since(time.Unix(timestamp, 0)).Abs() > tolerance {
+		return ErrSignatureInvalid
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(digest(secret, ts, body))) {
+		return ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+func digest(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -3,9 +3,15 @@ package worker
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 
+	"github.com/arefev/gophermart/internal/tracing"
 	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type request struct {
@@ -16,11 +22,25 @@ func NewRequest(accrualAddress string) *request {
 	return &request{accrualAddress: accrualAddress}
 }
 
-func (r *request) Request(ctx context.Context, number string, res *OrderResponse) error {
+// Request запрашивает статус заказа у системы начислений.
+// Контекст trace передается в заголовке traceparent.
+func (r *request) Request(ctx context.Context, number string, res *OrderResponse) (err error) {
+	ctx, span := tracing.Tracer().Start(
+		ctx,
+		"accrual.GetOrder",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPRequestMethodKey.String(http.MethodGet)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	header := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+
 	client := resty.New()
 	response, err := client.R().
 		SetResult(res).
 		SetContext(ctx).
+		SetHeaderMultiValues(header).
 		Get(r.getURL(number))
 
 	if err != nil {
@@ -29,6 +49,7 @@ func (r *request) Request(ctx context.Context, number string, res *OrderResponse
 
 	res.Header = response.Header()
 	res.HTTPStatus = response.StatusCode()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(res.HTTPStatus))
 
 	return nil
 }
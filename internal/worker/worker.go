@@ -5,13 +5,30 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/arefev/gophermart/internal/application"
+	"github.com/arefev/gophermart/internal/events"
+	"github.com/arefev/gophermart/internal/metrics"
 	"github.com/arefev/gophermart/internal/model"
+	"github.com/arefev/gophermart/internal/outbox"
+	"github.com/arefev/gophermart/internal/webhook"
 	"go.uber.org/zap"
 )
 
+const (
+	defaultShutdownTimeout = 30 * time.Second
+	defaultHeartbeat       = 10 * time.Second
+)
+
+var (
+	ErrDrainTimeout = errors.New("worker drain timeout")
+	ErrAccrualBusy  = errors.New("accrual system asks to retry later")
+)
+
 type StatusRequest interface {
 	Request(ctx context.Context, number string, res *OrderResponse) error
 }
@@ -24,42 +41,125 @@ type OrderResponse struct {
 	HTTPStatus int         `json:"-"`
 }
 
+// Settings настройки воркера, которые можно менять без перезапуска.
+type Settings struct {
+	PollInterval int
+	RateLimit    int
+}
+
 type worker struct {
-	app      *application.App
-	request  StatusRequest
-	job      chan *model.Order
-	ticker   *time.Ticker
-	isActive bool
+	app          *application.App
+	request      StatusRequest
+	job          chan *model.Order
+	ticker       *time.Ticker
+	reload       chan Settings
+	pause        chan time.Duration
+	wg           sync.WaitGroup
+	pollInterval atomic.Int64
+	limit        atomic.Int64
+	listeners    atomic.Int64
 }
 
 func NewWorker(app *application.App, r StatusRequest) *worker {
 	return &worker{
 		app:     app,
 		request: r,
+		reload:  make(chan Settings),
+		pause:   make(chan time.Duration, 1),
+	}
+}
+
+// Reload передает новые настройки в цикл воркера.
+func (w *worker) Reload(ctx context.Context, s Settings) error {
+	select {
+	case w.reload <- s:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("worker reload: %w", ctx.Err())
 	}
 }
 
 func (w *worker) Run(ctx context.Context) error {
 	w.app.Log.Info("Worker started")
 
-	w.pool(ctx)
+	// Задания не прерываются отменой ctx: начатая транзакция должна завершиться,
+	// остановка ждет их в stop
+	jobCtx := context.WithoutCancel(ctx)
+
+	w.pollInterval.Store(int64(w.app.Conf.PollInterval))
+	w.pool(jobCtx)
 	w.ticker = time.NewTicker(w.tickerTime())
-	w.isActive = true
+	w.beat()
+
+	// Тикером управляет только этот цикл: пауза после 429 и перезагрузка настроек
+	// приходят через каналы, resume не nil, пока опрос приостановлен
+	var resume <-chan time.Time
+
+	// Отметка для проверки готовности не зависит от тикера опроса: пауза после 429
+	// или большой poll_interval не должны делать воркер неготовым
+	heartbeat := time.NewTicker(w.heartbeatTime())
+	defer heartbeat.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
+			if err := w.stop(); err != nil {
+				return err
+			}
+
 			w.app.Log.Info("Worker stopped")
 			return fmt.Errorf("worker stopped: %w", ctx.Err())
+		case <-heartbeat.C:
+			w.beat()
 		case <-w.ticker.C:
 			w.app.Log.Info("Worker polling")
+			w.beat()
 			w.handle(ctx)
+		case s := <-w.reload:
+			w.apply(jobCtx, s, resume == nil)
+		case d := <-w.pause:
+			if resume == nil {
+				w.ticker.Stop()
+				resume = time.After(d)
+				metrics.AccrualPauses.Inc()
+				metrics.AccrualPauseSeconds.Add(d.Seconds())
+				w.app.Log.Sugar().Infof("worker wait time is %+v", d)
+			}
+		case <-resume:
+			resume = nil
+			w.ticker.Reset(w.tickerTime())
 		}
 	}
 }
 
+// stop перестает брать новые заказы и ждет, пока обработчики закончат текущие,
+// но не дольше ShutdownTimeout.
+func (w *worker) stop() error {
+	w.ticker.Stop()
+	close(w.job)
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	timeout := time.Duration(w.app.Conf.ShutdownTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		w.app.Log.Error("Worker stopped before jobs finished", zap.Duration("timeout", timeout))
+		return fmt.Errorf("%w: %s", ErrDrainTimeout, timeout)
+	}
+}
+
 func (w *worker) handle(ctx context.Context) {
-	w.checkOrders(w.getNewOrders(ctx))
+	w.checkOrders(ctx, w.getNewOrders(ctx))
 }
 
 func (w *worker) getNewOrders(ctx context.Context) []model.Order {
@@ -77,29 +177,49 @@ func (w *worker) getNewOrders(ctx context.Context) []model.Order {
 	return orders
 }
 
-func (w *worker) checkOrders(orders []model.Order) {
+func (w *worker) checkOrders(ctx context.Context, orders []model.Order) {
 	for i := range orders {
-		w.createJob(&orders[i])
+		if !w.createJob(ctx, &orders[i]) {
+			return
+		}
 	}
 }
 
 func (w *worker) getStatus(ctx context.Context, number string) (*OrderResponse, error) {
+	const statusError = "error"
+
+	start := time.Now()
 	res := OrderResponse{}
 	err := w.request.Request(ctx, number, &res)
 	if err != nil {
+		metrics.AccrualDuration.WithLabelValues(statusError).Observe(time.Since(start).Seconds())
 		return &OrderResponse{}, fmt.Errorf("get status fail: %w", err)
 	}
 
+	metrics.AccrualDuration.WithLabelValues(strconv.Itoa(res.HTTPStatus)).Observe(time.Since(start).Seconds())
 	return &res, nil
 }
 
 func (w *worker) accrual(ctx context.Context, order *model.Order, fields *OrderResponse) error {
 	status := model.OrderStatusFromString(fields.Status)
 	if status != model.OrderStatusProcessed && status != model.OrderStatusInvalid {
-		return nil
+		return w.checked(ctx, order, status)
 	}
 
+	// Заказ обновляется первым: если его уже обработала параллельная проверка
+	// (очередь воркера или внеочередная проверка), баллы повторно не начисляются
+	var updated bool
 	err := w.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+		updated, err = w.app.Rep.Order.AccrualByID(ctx, fields.Accrual, status, order.ID)
+		if err != nil {
+			return fmt.Errorf("update order accrual fail: %w", err)
+		}
+
+		if !updated {
+			return nil
+		}
+
 		if status == model.OrderStatusProcessed {
 			balance, ok := w.app.Rep.Balance.FindByUserID(ctx, order.UserID)
 			if !ok {
@@ -110,39 +230,158 @@ func (w *worker) accrual(ctx context.Context, order *model.Order, fields *OrderR
 			if err := w.app.Rep.Balance.UpdateByID(ctx, balance.ID, current, balance.Withdrawn); err != nil {
 				return fmt.Errorf("update user balance fail: %w", err)
 			}
+
+			payload, err := events.NewBalancePayload(current, balance.Withdrawn)
+			if err != nil {
+				return err
+			}
+
+			if err := w.app.Rep.Event.Create(ctx, order.UserID, model.EventBalance, payload); err != nil {
+				return fmt.Errorf("create balance event fail: %w", err)
+			}
+
+			hookPayload, err := webhook.NewPointsPayload(
+				model.WebhookEventPointsAccrued, order.UserID, order.Number, fields.Accrual,
+			)
+			if err != nil {
+				return err
+			}
+
+			if err := w.app.Rep.Webhook.Enqueue(ctx, order.UserID, model.WebhookEventPointsAccrued, hookPayload); err != nil {
+				return fmt.Errorf("enqueue webhook fail: %w", err)
+			}
 		}
 
-		if err := w.app.Rep.Order.AccrualByID(ctx, fields.Accrual, status, order.ID); err != nil {
-			return fmt.Errorf("update order accrual fail: %w", err)
+		payload, err := events.NewOrderPayload(order.Number, status, fields.Accrual)
+		if err != nil {
+			return err
 		}
 
-		return nil
+		if err := w.app.Rep.Event.Create(ctx, order.UserID, model.EventOrder, payload); err != nil {
+			return fmt.Errorf("create order event fail: %w", err)
+		}
+
+		return outbox.Emit(ctx, w.app, model.OutboxOrderProcessed, outbox.OrderProcessed{
+			Number:  order.Number,
+			Status:  status.String(),
+			Accrual: fields.Accrual,
+			UserID:  order.UserID,
+		})
 	})
 
 	if err != nil {
 		return fmt.Errorf("update order transaction fail: %w", err)
 	}
 
+	if !updated {
+		w.app.Log.Debug("order already finalized", zap.String("number", order.Number))
+		return nil
+	}
+
+	if status == model.OrderStatusProcessed {
+		metrics.PointsAccrued.Add(fields.Accrual)
+	}
+
+	return nil
+}
+
+// checked сохраняет время проверки и промежуточный статус заказа, окончательного
+// статуса у которого еще нет. О смене статуса клиенты узнают из потока событий.
+func (w *worker) checked(ctx context.Context, order *model.Order, status model.OrderStatus) error {
+	err := w.app.TrManager.Do(ctx, func(ctx context.Context) error {
+		changed, err := w.app.Rep.Order.CheckedByID(ctx, status, order.ID)
+		if err != nil || !changed {
+			return err
+		}
+
+		payload, err := events.NewOrderPayload(order.Number, status, 0)
+		if err != nil {
+			return err
+		}
+
+		if err := w.app.Rep.Event.Create(ctx, order.UserID, model.EventOrder, payload); err != nil {
+			return fmt.Errorf("create order event fail: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("order checked transaction fail: %w", err)
+	}
+
 	return nil
 }
 
 func (w *worker) pool(ctx context.Context) {
 	limit := w.app.Conf.RateLimit
 	w.job = make(chan *model.Order, limit)
+	w.resize(ctx, limit)
+}
+
+// apply применяет новые настройки. Приостановленный после 429 тикер не трогается,
+// новый интервал подхватится при возобновлении.
+func (w *worker) apply(ctx context.Context, s Settings, active bool) {
+	w.pollInterval.Store(int64(s.PollInterval))
+	if active {
+		w.ticker.Reset(w.tickerTime())
+	}
+
+	w.resize(ctx, s.RateLimit)
+	w.app.Log.Info(
+		"Worker settings reloaded",
+		zap.Int("poll_interval", s.PollInterval),
+		zap.Int("rate_limit", s.RateLimit),
+	)
+}
 
-	for range limit {
+// resize доводит число обработчиков до limit. Лишние обработчики завершаются
+// после своего следующего задания, размер очереди остается прежним.
+func (w *worker) resize(ctx context.Context, limit int) {
+	w.limit.Store(int64(limit))
+	for w.listeners.Load() < int64(limit) {
+		w.listeners.Add(1)
+		w.wg.Add(1)
 		go w.listener(ctx)
 	}
 }
 
 func (w *worker) listener(ctx context.Context) {
+	defer w.wg.Done()
+
 	for order := range w.job {
+		metrics.WorkerQueueDepth.Set(float64(len(w.job)))
 		w.runJob(ctx, order)
+
+		if w.retire() {
+			return
+		}
 	}
 }
 
-func (w *worker) createJob(order *model.Order) {
-	w.job <- order
+// retire завершает обработчик, если после уменьшения лимита он лишний.
+func (w *worker) retire() bool {
+	for {
+		n := w.listeners.Load()
+		if n <= w.limit.Load() {
+			return false
+		}
+
+		if w.listeners.CompareAndSwap(n, n-1) {
+			return true
+		}
+	}
+}
+
+// createJob ставит заказ в очередь, при остановке новые заказы не берутся.
+func (w *worker) createJob(ctx context.Context, order *model.Order) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case w.job <- order:
+		metrics.WorkerQueueDepth.Set(float64(len(w.job)))
+		return true
+	}
 }
 
 func (w *worker) runJob(ctx context.Context, order *model.Order) {
@@ -164,6 +403,21 @@ func (w *worker) runJob(ctx context.Context, order *model.Order) {
 	}
 }
 
+// Recheck проверяет заказ вне очереди. На 429 воркер не приостанавливается:
+// тикером управляет только цикл Run, вызывающий получает ErrAccrualBusy.
+func (w *worker) Recheck(ctx context.Context, order *model.Order) error {
+	response, err := w.getStatus(ctx, order.Number)
+	if w.shouldRestart(response) {
+		return fmt.Errorf("recheck order %s: %w", order.Number, ErrAccrualBusy)
+	}
+
+	if err != nil {
+		return fmt.Errorf("recheck order %s: %w", order.Number, err)
+	}
+
+	return w.accrual(ctx, order, response)
+}
+
 func (w *worker) shouldRestart(r *OrderResponse) bool {
 	return r.HTTPStatus == http.StatusTooManyRequests
 }
@@ -182,21 +436,31 @@ func (w *worker) restart(r *OrderResponse) {
 	w.restartAfter(wait)
 }
 
+// restartAfter просит цикл Run приостановить опрос. Если пауза уже ожидает
+// обработки, повторная от другого обработчика не нужна.
 func (w *worker) restartAfter(d time.Duration) {
-	if !w.isActive {
-		return
+	select {
+	case w.pause <- d:
+	default:
 	}
+}
 
-	w.ticker.Stop()
-	time.AfterFunc(d, func() {
-		w.ticker.Reset(w.tickerTime())
-		w.isActive = true
-	})
+func (w *worker) tickerTime() time.Duration {
+	return time.Duration(w.pollInterval.Load()) * time.Second
+}
+
+// heartbeatTime половина допустимого возраста отметки, чтобы она успевала обновиться.
+func (w *worker) heartbeatTime() time.Duration {
+	if w.app.Conf.HealthWorkerMaxAge > 1 {
+		return time.Duration(w.app.Conf.HealthWorkerMaxAge) * time.Second / 2
+	}
 
-	w.isActive = false
-	w.app.Log.Sugar().Infof("worker wait time is %+v", d)
+	return defaultHeartbeat
 }
 
-func (w *worker) tickerTime() time.Duration {
-	return time.Duration(w.app.Conf.PollInterval) * time.Second
+// beat отмечает живой цикл воркера для проверки готовности.
+func (w *worker) beat() {
+	if w.app.Health != nil {
+		w.app.Health.Worker().Beat()
+	}
 }